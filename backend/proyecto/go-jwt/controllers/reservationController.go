@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"proyecto/dtos"
@@ -108,6 +109,10 @@ func UpdateReservation(c *gin.Context) {
 	}
 
 	reservation, err := services.UpdateReservation(id, &dto)
+	if errors.Is(err, services.ErrNoAvailability) {
+		c.JSON(http.StatusConflict, gin.H{"error": "No availability for the selected dates"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found or update failed"})
 		return
@@ -116,15 +121,15 @@ func UpdateReservation(c *gin.Context) {
 	c.JSON(http.StatusOK, reservation)
 }
 
-// DeleteReservation handles deleting a reservation
+// DeleteReservation handles cancelling a reservation
 func DeleteReservation(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	if err := services.DeleteReservation(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reservation"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel reservation"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Reservation deleted"})
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled"})
 }
 
 func GetMyReservations(c *gin.Context) {
@@ -6,6 +6,8 @@ type HotelDto struct {
 	Address     string   `json:"address" binding:"required"`
 	City        string   `json:"city" binding:"required"`
 	Country     string   `json:"country" binding:"required"`
+	Latitude    *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude   *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	Amenities   []string `json:"amenities"`
 	Photos      []string `json:"photos"`
 }
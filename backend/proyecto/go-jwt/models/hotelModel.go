@@ -10,6 +10,8 @@ type Hotel struct {
 	Address     string    `json:"address" binding:"required"`
 	City        string    `json:"city" binding:"required"`
 	Country     string    `json:"country" binding:"required"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
 	Amenities   []Amenity `gorm:"many2many:hotel_amenities" json:"amenities"`
 	Photos      []Photo   `json:"photos"`
 }
@@ -6,11 +6,18 @@ import (
 	"gorm.io/gorm"
 )
 
+// Estados de una reserva
+const (
+	ReservationConfirmed = "confirmed"
+	ReservationCancelled = "cancelled"
+)
+
 type Reservation struct {
 	gorm.Model
 	HotelID  uint      `json:"hotel_id"`
 	UserID   uint      `json:"user_id"`
 	CheckIn  time.Time `json:"check_in"`
 	CheckOut time.Time `json:"check_out"`
+	Status   string    `json:"status" gorm:"default:confirmed"`
 	Hotel    Hotel     `gorm:"foreignKey:HotelID"`
 }
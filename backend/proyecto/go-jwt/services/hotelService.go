@@ -95,6 +95,8 @@ func CreateHotel(hotelDto dtos.HotelDto) (*models.Hotel, error) {
 		Address:     hotelDto.Address,
 		City:        hotelDto.City,
 		Country:     hotelDto.Country,
+		Latitude:    hotelDto.Latitude,
+		Longitude:   hotelDto.Longitude,
 		Amenities:   amenities,
 		Photos:      photos,
 	}
@@ -147,6 +149,8 @@ func UpdateHotel(id int, hotelDto dtos.HotelDto) (*models.Hotel, error) {
 	hotel.Address = hotelDto.Address
 	hotel.City = hotelDto.City
 	hotel.Country = hotelDto.Country
+	hotel.Latitude = hotelDto.Latitude
+	hotel.Longitude = hotelDto.Longitude
 	hotel.Amenities = amenities
 	hotel.Photos = photos
 
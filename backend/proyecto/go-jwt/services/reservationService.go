@@ -6,6 +6,9 @@ import (
 	"proyecto/initializers"
 	"proyecto/models"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateReservation creates a new reservation in the database
@@ -65,13 +68,13 @@ func GetReservationByID(id int) (models.Reservation, error) {
 	return reservation, nil
 }
 
-// UpdateReservation updates an existing reservation in the database
-func UpdateReservation(id int, dto *dtos.ReservationDto) (models.Reservation, error) {
-	var reservation models.Reservation
-	if err := initializers.DB.First(&reservation, id).Error; err != nil {
-		return models.Reservation{}, err
-	}
+// ErrNoAvailability is returned when a night of the requested range is fully booked
+var ErrNoAvailability = errors.New("no availability")
 
+// UpdateReservation moves an existing reservation to a new hotel and date range.
+// The old nights are released and the new ones are claimed in the same transaction,
+// so the availability table stays consistent and a failed change leaves everything untouched.
+func UpdateReservation(id int, dto *dtos.ReservationDto) (models.Reservation, error) {
 	// Parse dates
 	checkIn, err := time.Parse("2006-01-02", dto.CheckIn)
 	if err != nil {
@@ -81,37 +84,74 @@ func UpdateReservation(id int, dto *dtos.ReservationDto) (models.Reservation, er
 	if err != nil {
 		return models.Reservation{}, err
 	}
+	if !checkOut.After(checkIn) {
+		return models.Reservation{}, errors.New("check_out must be after check_in")
+	}
 
-	// Update fields
-	reservation.HotelID = dto.HotelID
-	reservation.UserID = dto.UserID
-	reservation.CheckIn = checkIn
-	reservation.CheckOut = checkOut
+	var reservation models.Reservation
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+			return err
+		}
+		if reservation.Status == models.ReservationCancelled {
+			return errors.New("reservation is cancelled")
+		}
 
-	if err := initializers.DB.Save(&reservation).Error; err != nil {
+		// Release the old nights before checking the new ones, they may overlap
+		if err := updateAvailability(tx, reservation.HotelID, reservation.CheckIn, reservation.CheckOut, 1); err != nil {
+			return err
+		}
+
+		var availabilities []models.Availability
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hotel_id = ? AND date >= ? AND date < ?", dto.HotelID, checkIn, checkOut).
+			Find(&availabilities).Error; err != nil {
+			return err
+		}
+		for _, availability := range availabilities {
+			if availability.Available <= 0 {
+				return ErrNoAvailability
+			}
+		}
+
+		if err := updateAvailability(tx, dto.HotelID, checkIn, checkOut, -1); err != nil {
+			return err
+		}
+
+		// The owner of the reservation never changes
+		reservation.HotelID = dto.HotelID
+		reservation.CheckIn = checkIn
+		reservation.CheckOut = checkOut
+		return tx.Save(&reservation).Error
+	})
+	if err != nil {
 		return models.Reservation{}, err
 	}
 
 	return reservation, nil
 }
 
-// DeleteReservation deletes a reservation from the database
+// DeleteReservation cancels a reservation. The row is kept with status cancelled
+// so the reservation history is not lost. The status change and the released nights
+// are written in the same transaction, like in UpdateReservation.
 func DeleteReservation(id int) error {
-	var reservation models.Reservation
-	if err := initializers.DB.First(&reservation, id).Error; err != nil {
-		return err
-	}
+	return initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var reservation models.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+			return err
+		}
 
-	if err := initializers.DB.Delete(&reservation).Error; err != nil {
-		return err
-	}
+		if reservation.Status == models.ReservationCancelled {
+			return errors.New("reservation is already cancelled")
+		}
 
-	// Update availability
-	if err := updateAvailability(reservation.HotelID, reservation.CheckIn, reservation.CheckOut, 1); err != nil {
-		return err
-	}
+		if err := tx.Model(&reservation).Update("status", models.ReservationCancelled).Error; err != nil {
+			return err
+		}
 
-	return nil
+		// Update availability
+		return updateAvailability(tx, reservation.HotelID, reservation.CheckIn, reservation.CheckOut, 1)
+	})
 }
 
 // CheckAvailability checks the availability of a hotel between two dates
@@ -129,16 +169,17 @@ func CheckAvailability(hotelID uint, checkIn, checkOut time.Time) error {
 	return nil
 }
 
-// updateAvailability updates the availability of a hotel between two dates
-func updateAvailability(hotelID uint, checkIn, checkOut time.Time, quantity int) error {
+// updateAvailability updates the availability of a hotel between two dates using db,
+// which can be a transaction
+func updateAvailability(db *gorm.DB, hotelID uint, checkIn, checkOut time.Time, quantity int) error {
 	var availabilities []models.Availability
-	if err := initializers.DB.Where("hotel_id = ? AND date >= ? AND date < ?", hotelID, checkIn, checkOut).Find(&availabilities).Error; err != nil {
+	if err := db.Where("hotel_id = ? AND date >= ? AND date < ?", hotelID, checkIn, checkOut).Find(&availabilities).Error; err != nil {
 		return err
 	}
 
 	for _, availability := range availabilities {
 		availability.Available += quantity
-		if err := initializers.DB.Save(&availability).Error; err != nil {
+		if err := db.Save(&availability).Error; err != nil {
 			return err
 		}
 	}
@@ -6,9 +6,11 @@ import (
 	"hotel-api/models"
 	"hotel-api/services"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type HotelController struct{}
@@ -21,6 +23,12 @@ func (ctrl *HotelController) CreateHotel(c *gin.Context) {
 		return
 	}
 
+	// Verificar que las coordenadas sean válidas
+	if err := services.ValidateCoordinates(hotelDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Verificar si el hotel ya existe con el mismo nombre y dirección
 	duplicate, err := services.CheckDuplicateHotel(hotelDto)
 	if err != nil {
@@ -63,8 +71,48 @@ func (ctrl *HotelController) GetHotel(c *gin.Context) {
 	c.JSON(http.StatusOK, hotel)
 }
 
-// Obtener todos los hoteles
+// Obtener todos los hoteles. Con los parámetros 'page' y 'size' se devuelve una página
+// y el total de hoteles en el header X-Total-Count. Con 'after' (el ID del último hotel
+// recibido) en lugar de 'page' se devuelven los hoteles siguientes a ese ID.
 func (ctrl *HotelController) GetHotels(c *gin.Context) {
+	if c.Query("page") != "" || c.Query("size") != "" || c.Query("after") != "" {
+		size, err := strconv.Atoi(c.DefaultQuery("size", "100"))
+		if err != nil || size < 1 || size > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size"})
+			return
+		}
+
+		var hotels []models.Hotel
+		var total int64
+		if after := c.Query("after"); after != "" {
+			if c.Query("page") != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "page and after cannot be combined"})
+				return
+			}
+			afterID, parseErr := primitive.ObjectIDFromHex(after)
+			if parseErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after"})
+				return
+			}
+			hotels, total, err = services.GetHotelsAfter(afterID, size)
+		} else {
+			page, parseErr := strconv.Atoi(c.DefaultQuery("page", "1"))
+			if parseErr != nil || page < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+				return
+			}
+			hotels, total, err = services.GetHotelsPage(page, size)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hotels"})
+			return
+		}
+
+		c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		c.JSON(http.StatusOK, hotels)
+		return
+	}
+
 	hotels, err := services.GetHotels()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hotels"})
@@ -91,6 +139,12 @@ func (ctrl *HotelController) UpdateHotel(c *gin.Context) {
 		return
 	}
 
+	// Verificar que las coordenadas sean válidas
+	if err := services.ValidateCoordinates(hotelDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Verificar si ya existe un hotel con el mismo nombre y dirección, excluyendo el hotel actual
 	duplicate, err := services.CheckDuplicateHotelExcludingCurrent(objectID, hotelDto)
 	if err != nil {
@@ -136,13 +190,25 @@ func (ctrl *HotelController) DeleteHotel(c *gin.Context) {
 func CheckHotelExistence(c *gin.Context) {
 	hotelID := c.Param("hotelID")
 
+	// Un ID que no es un ObjectID válido no puede corresponder a ningún hotel
+	objectID, err := primitive.ObjectIDFromHex(hotelID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Hotel with ID %s does not exist", hotelID)})
+		return
+	}
+
 	var hotel models.Hotel
 	// Buscar el hotel en la base de datos usando MongoDB
-	if err := initializers.DB.Collection("hotels").FindOne(c, map[string]interface{}{"hotelID": hotelID}).Decode(&hotel); err != nil {
+	err = initializers.DB.Collection("hotels").FindOne(c, map[string]interface{}{"_id": objectID}).Decode(&hotel)
+	if err == mongo.ErrNoDocuments {
 		// Si el hotel no existe, devolver 404
 		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Hotel with ID %s does not exist", hotelID)})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check hotel existence"})
+		return
+	}
 
 	// Si el hotel existe, devolver 200 OK
 	c.JSON(http.StatusOK, gin.H{"message": "Hotel exists"})
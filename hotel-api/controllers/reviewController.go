@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"errors"
+	"hotel-api/dtos"
+	"hotel-api/models"
+	"hotel-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReviewController struct{}
+
+// Obtener el ID del usuario autenticado que guardó el middleware RequireAuth
+func currentUserID(c *gin.Context) (uint, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		return 0, false
+	}
+	claims, ok := user.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	id, ok := claims["id"].(float64)
+	if !ok || id <= 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// Leer los parámetros 'page' y 'size' de la paginación
+func bindPage(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return 0, 0, false
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil || size < 1 || size > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size"})
+		return 0, 0, false
+	}
+	return page, size, true
+}
+
+// Crear una reseña del hotel. Solo puede hacerlo el huésped de una reserva finalizada
+func (ctrl *ReviewController) CreateReview(c *gin.Context) {
+	hotelID, err := primitive.ObjectIDFromHex(c.Param("hotelID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hotel ID"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var reviewDto dtos.ReviewDto
+	if err := c.ShouldBindJSON(&reviewDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// El token se reenvía a reservation-api para consultar la reserva en nombre del usuario
+	token, _ := c.Cookie("Authorization")
+
+	review, err := services.CreateReview(hotelID, userID, token, reviewDto)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidReviewCategory):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, mongo.ErrNoDocuments):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hotel not found"})
+		case errors.Is(err, services.ErrReviewNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrReviewExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrReservationAPIUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// Obtener las reseñas aprobadas de un hotel, paginadas con el total en el header X-Total-Count
+func (ctrl *ReviewController) GetHotelReviews(c *gin.Context) {
+	hotelID, err := primitive.ObjectIDFromHex(c.Param("hotelID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hotel ID"})
+		return
+	}
+
+	page, size, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	reviews, total, err := services.GetHotelReviews(hotelID, page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, reviews)
+}
+
+// Obtener las reseñas por estado para moderarlas. Por defecto las pendientes
+func (ctrl *ReviewController) GetReviews(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReviewPending)
+	if status != models.ReviewPending && status != models.ReviewApproved && status != models.ReviewRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review status"})
+		return
+	}
+
+	page, size, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	reviews, total, err := services.GetReviewsByStatus(status, page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, reviews)
+}
+
+// Aprobar o rechazar una reseña
+func (ctrl *ReviewController) ModerateReview(c *gin.Context) {
+	reviewID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var moderateDto dtos.ModerateReviewDto
+	if err := c.ShouldBindJSON(&moderateDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	moderatorID, _ := currentUserID(c)
+
+	review, err := services.ModerateReview(reviewID, moderateDto.Status, moderateDto.Note, moderatorID)
+	if err != nil {
+		if errors.Is(err, services.ErrReviewNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
@@ -0,0 +1,15 @@
+package dtos
+
+// ReviewDto es la reseña que deja un huésped sobre la estadía de una reserva
+type ReviewDto struct {
+	ReservationID uint           `json:"reservation_id" binding:"required"`
+	Overall       int            `json:"overall" binding:"required,min=1,max=5"`
+	Categories    map[string]int `json:"categories"` // Opcional; ver models.ReviewCategories
+	Text          string         `json:"text" binding:"max=2000"`
+}
+
+// ModerateReviewDto es la decisión de un administrador sobre una reseña
+type ModerateReviewDto struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string `json:"note"`
+}
@@ -3,6 +3,7 @@ package initializers
 import (
 	"context"
 	"log"
+	"os"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -11,8 +12,13 @@ import (
 var DB *mongo.Database
 
 func ConnectMongo() {
-	// Aquí debes colocar la URL de conexión de tu MongoDB.
-	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
+	// URL de conexión de MongoDB. El outbox de eventos usa transacciones,
+	// por lo que MongoDB tiene que correr como replica set.
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		uri = "mongodb://localhost:27017/?replicaSet=rs0"
+	}
+	clientOptions := options.Client().ApplyURI(uri)
 
 	// Conectando a MongoDB
 	client, err := mongo.Connect(context.Background(), clientOptions)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"hotel-api/initializers"
 	"hotel-api/routes"
+	"hotel-api/services"
 	"log"
 	"os"
 
@@ -21,10 +22,16 @@ func init() {
 	// Conectar a la base de datos
 	initializers.ConnectMongo()
 
-	// Conectar a RabbitMQ
-	err := initializers.ConnectRabbitMQ() // Llamamos a la función para inicializar RabbitMQ
-	if err != nil {
-		panic("Failed to connect to RabbitMQ") // En caso de error, paramos la aplicación
+	// Crear los índices de las reseñas (una reseña por reserva)
+	services.EnsureReviewIndexes()
+
+	// Borrar automáticamente las entradas del outbox ya publicadas
+	services.EnsureOutboxIndexes()
+
+	// Conectar a RabbitMQ. Si el broker no está disponible los eventos quedan
+	// en el outbox y el relay reintenta la conexión más tarde.
+	if err := initializers.ConnectRabbitMQ(); err != nil {
+		log.Printf("RabbitMQ not available, hotel events will be relayed later: %s", err)
 	}
 }
 
@@ -32,6 +39,9 @@ func main() {
 	// Verificar el valor de SECRET
 	fmt.Println("SECRET:", os.Getenv("SECRET"))
 
+	// Publicar en segundo plano los eventos pendientes del outbox
+	services.StartOutboxRelay()
+
 	r := gin.Default()
 
 	// Configuración CORS
@@ -43,9 +53,10 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	// Llamar al archivo de rutas para registrar las rutas de los hoteles y amenidades
+	// Llamar al archivo de rutas para registrar las rutas de los hoteles, amenidades y reseñas
 	routes.SetupHotelRoutes(r)
 	routes.SetupAmenityRoutes(r)
+	routes.SetupReviewRoutes(r)
 
 	// Iniciar el servidor
 	r.Run(":8080")
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Versión del esquema de los eventos de hoteles publicados en RabbitMQ
+const HotelEventSchemaVersion = 1
+
+// Tipos de eventos de hoteles (se usan también como routing key)
+const (
+	HotelCreated = "hotel.created"
+	HotelUpdated = "hotel.updated"
+	HotelDeleted = "hotel.deleted"
+)
+
+// HotelEvent representa un cambio sobre un hotel publicado en el exchange de hoteles
+type HotelEvent struct {
+	SchemaVersion int           `json:"schema_version"`
+	EventType     string        `json:"event_type"`
+	HotelID       string        `json:"hotel_id"`
+	Revision      int64         `json:"revision"`
+	Timestamp     time.Time     `json:"timestamp"`
+	Hotel         *HotelPayload `json:"hotel,omitempty"` // nil en los eventos de borrado
+}
+
+// HotelPayload son los datos del hotel que viajan en los eventos
+type HotelPayload struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Amenities []string `json:"amenities"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+
+	// Para ordenar y filtrar por calificación en el buscador
+	RatingAverage float64 `json:"rating_average"`
+	ReviewCount   int     `json:"review_count"`
+}
@@ -12,4 +12,12 @@ type Hotel struct {
     Country   string             `json:"country" bson:"country"`
     Amenities []string           `json:"amenities" bson:"amenities"`
     Photos    interface{}        `json:"photos" bson:"photos"` // Esto puede ser ajustado según el tipo de datos
+    Latitude  *float64           `json:"latitude,omitempty" bson:"latitude,omitempty"`
+    Longitude *float64           `json:"longitude,omitempty" bson:"longitude,omitempty"`
+    Revision  int64              `json:"revision" bson:"revision"` // Se incrementa en cada cambio del hotel
+
+    // Calificaciones de las reseñas aprobadas; solo las actualiza la moderación de reseñas
+    RatingAverage   float64            `json:"rating_average" bson:"rating_average"`
+    ReviewCount     int                `json:"review_count" bson:"review_count"`
+    CategoryRatings map[string]float64 `json:"category_ratings,omitempty" bson:"category_ratings,omitempty"`
 }
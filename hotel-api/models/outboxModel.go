@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de una entrada del outbox
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+)
+
+// OutboxEntry es un evento pendiente de publicar en RabbitMQ.
+// Se guarda en la misma transacción que el cambio del hotel.
+type OutboxEntry struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventType     string             `json:"event_type" bson:"event_type"`
+	HotelID       string             `json:"hotel_id" bson:"hotel_id"`
+	Payload       []byte             `json:"payload" bson:"payload"` // Evento serializado en JSON
+	Status        string             `json:"status" bson:"status"`
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	LastError     string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	DeliveredAt   *time.Time         `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de moderación de una reseña. Solo las aprobadas se muestran y cuentan en el promedio.
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Calificación mínima y máxima de una reseña
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Categorías que se pueden calificar además de la calificación general
+var ReviewCategories = []string{"cleanliness", "location", "service", "comfort", "value"}
+
+// Review es la reseña de un huésped sobre un hotel en el que se alojó
+type Review struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	HotelID        primitive.ObjectID `json:"hotel_id" bson:"hotel_id"`
+	UserID         uint               `json:"user_id" bson:"user_id"`
+	ReservationID  uint               `json:"reservation_id" bson:"reservation_id"` // Una reseña por reserva
+	Overall        int                `json:"overall" bson:"overall"`
+	Categories     map[string]int     `json:"categories,omitempty" bson:"categories,omitempty"`
+	Text           string             `json:"text" bson:"text"`
+	Status         string             `json:"status" bson:"status"`
+	ModerationNote string             `json:"moderation_note,omitempty" bson:"moderation_note,omitempty"`
+	ModeratedBy    uint               `json:"moderated_by,omitempty" bson:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time         `json:"moderated_at,omitempty" bson:"moderated_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
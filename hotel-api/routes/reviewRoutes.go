@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"hotel-api/controllers"
+	"hotel-api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupReviewRoutes(r *gin.Engine) {
+	reviewController := &controllers.ReviewController{}
+
+	// Grupo de rutas protegidas con autenticación
+	auth := r.Group("/reviews")
+	auth.Use(middleware.RequireAuth)
+
+	// Grupo de rutas solo para administradores
+	admin := auth.Group("")
+	admin.Use(middleware.RequireAdmin)
+
+	// Los huéspedes publican reseñas y todos los usuarios autenticados ven las aprobadas
+	auth.POST("/hotel/:hotelID", reviewController.CreateReview)
+	auth.GET("/hotel/:hotelID", reviewController.GetHotelReviews)
+
+	// Solo administradores moderan las reseñas
+	admin.GET("", reviewController.GetReviews)
+	admin.PUT("/:id/moderate", reviewController.ModerateReview)
+}
@@ -2,18 +2,16 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"hotel-api/initializers"
 	"hotel-api/models"
-	"log"
 	"strings"
+	"time"
 
-	"github.com/streadway/amqp"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Validar si las amenidades existen
@@ -37,61 +35,51 @@ func validateAmenitiesExist(amenities []string) error {
 	return nil
 }
 
-// Enviar un mensaje a RabbitMQ
-func SendHotelCreationMessage(hotel models.Hotel) error {
-	if initializers.RabbitMQChannel == nil {
-		log.Println("RabbitMQ channel is not initialized")
-		return fmt.Errorf("RabbitMQ channel is not initialized")
+// ValidateCoordinates verifica que la latitud y la longitud vengan juntas y estén en rango
+func ValidateCoordinates(hotel models.Hotel) error {
+	if hotel.Latitude == nil && hotel.Longitude == nil {
+		return nil
 	}
+	if hotel.Latitude == nil || hotel.Longitude == nil {
+		return errors.New("latitude and longitude must be provided together")
+	}
+	if *hotel.Latitude < -90 || *hotel.Latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if *hotel.Longitude < -180 || *hotel.Longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
 
-	message := map[string]interface{}{
-		"id":        hotel.ID.Hex(),
-		"name":      hotel.Name,
-		"address":   hotel.Address,
-		"city":      hotel.City,
-		"country":   hotel.Country,
-		"amenities": hotel.Amenities,
+// NewHotelEvent arma el evento correspondiente a un cambio sobre el hotel
+func NewHotelEvent(eventType string, hotel models.Hotel) models.HotelEvent {
+	event := models.HotelEvent{
+		SchemaVersion: models.HotelEventSchemaVersion,
+		EventType:     eventType,
+		HotelID:       hotel.ID.Hex(),
+		Revision:      hotel.Revision,
+		Timestamp:     time.Now().UTC(),
 	}
 
-	// Convertir el mensaje a JSON
-	body, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("Failed to marshal hotel message: %s", err)
-		return err
-	}
-
-	// Asegurarse de que la cola "hotel_created" esté declarada
-	_, err = initializers.RabbitMQChannel.QueueDeclare(
-		"hotel_created", // nombre de la cola
-		true,            // durable
-		false,           // auto-deleted
-		false,           // exclusive
-		false,           // no-wait
-		nil,             // argumentos adicionales
-	)
-	if err != nil {
-		log.Printf("Failed to declare queue: %s", err)
-		return err
-	}
-
-	// Publicar el mensaje
-	err = initializers.RabbitMQChannel.Publish(
-		"",              // exchange
-		"hotel_created", // routing key (nombre de la cola)
-		false,           // mandatory
-		false,           // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
-	if err != nil {
-		log.Printf("Failed to publish message: %s", err)
-		return err
+	// Los eventos de borrado solo llevan el ID y la revisión
+	if eventType != models.HotelDeleted {
+		event.Hotel = &models.HotelPayload{
+			ID:        hotel.ID.Hex(),
+			Name:      hotel.Name,
+			Address:   hotel.Address,
+			City:      hotel.City,
+			Country:   hotel.Country,
+			Amenities: hotel.Amenities,
+			Latitude:  hotel.Latitude,
+			Longitude: hotel.Longitude,
+
+			RatingAverage: hotel.RatingAverage,
+			ReviewCount:   hotel.ReviewCount,
+		}
 	}
 
-	log.Println("Message sent to RabbitMQ queue: hotel_created")
-	return nil
+	return event
 }
 
 // Crear un hotel
@@ -102,16 +90,23 @@ func CreateHotel(hotelDto models.Hotel) (models.Hotel, error) {
 	}
 
 	hotelDto.ID = primitive.NewObjectID()
+	hotelDto.Revision = 1
+
+	// Un hotel nuevo no tiene reseñas, aunque el cuerpo traiga calificaciones
+	hotelDto.RatingAverage = 0
+	hotelDto.ReviewCount = 0
+	hotelDto.CategoryRatings = nil
 
 	collection := initializers.DB.Collection("hotels")
-	_, err := collection.InsertOne(context.Background(), hotelDto)
-	if err != nil {
-		return models.Hotel{}, err
-	}
 
-	// Enviar mensaje a RabbitMQ después de crear el hotel
-	if err := SendHotelCreationMessage(hotelDto); err != nil {
-		log.Printf("Failed to send hotel creation message: %s", err)
+	// Guardar el hotel y su evento en la misma transacción; el relay lo publica después
+	err := withHotelTransaction(func(sc mongo.SessionContext) error {
+		if _, err := collection.InsertOne(sc, hotelDto); err != nil {
+			return err
+		}
+		return enqueueHotelEvent(sc, NewHotelEvent(models.HotelCreated, hotelDto))
+	})
+	if err != nil {
 		return models.Hotel{}, err
 	}
 
@@ -158,6 +153,43 @@ func GetHotels() ([]models.Hotel, error) {
 	return hotels, nil
 }
 
+// Obtener una página de hoteles ordenada por ID junto con el total de hoteles
+func GetHotelsPage(page, size int) ([]models.Hotel, int64, error) {
+	opts := options.Find().SetSkip(int64((page - 1) * size))
+	return findHotelsPage(bson.M{}, size, opts)
+}
+
+// Obtener los hoteles siguientes al ID after, ordenados por ID, junto con el total de hoteles.
+// A diferencia de saltear páginas, borrar un hotel mientras se recorre no corre a los siguientes
+// de lugar, así que no se pierde ninguno.
+func GetHotelsAfter(after primitive.ObjectID, size int) ([]models.Hotel, int64, error) {
+	return findHotelsPage(bson.M{"_id": bson.M{"$gt": after}}, size, options.Find())
+}
+
+// findHotelsPage busca hasta size hoteles que cumplen filter ordenados por ID
+func findHotelsPage(filter bson.M, size int, opts *options.FindOptions) ([]models.Hotel, int64, error) {
+	collection := initializers.DB.Collection("hotels")
+
+	total, err := collection.CountDocuments(context.Background(), bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts.SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(size))
+	cursor, err := collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	hotels := []models.Hotel{}
+	if err := cursor.All(context.Background(), &hotels); err != nil {
+		return nil, 0, err
+	}
+
+	return hotels, total, nil
+}
+
 // Actualizar un hotel
 func UpdateHotel(id primitive.ObjectID, hotelDto models.Hotel) (models.Hotel, error) {
 	// Excluir el campo `_id` para evitar errores en MongoDB
@@ -167,6 +199,8 @@ func UpdateHotel(id primitive.ObjectID, hotelDto models.Hotel) (models.Hotel, er
 		"city":      hotelDto.City,
 		"country":   hotelDto.Country,
 		"amenities": hotelDto.Amenities,
+		"latitude":  hotelDto.Latitude,
+		"longitude": hotelDto.Longitude,
 	}
 
 	// Verificar que las amenidades existan
@@ -176,13 +210,22 @@ func UpdateHotel(id primitive.ObjectID, hotelDto models.Hotel) (models.Hotel, er
 
 	collection := initializers.DB.Collection("hotels")
 
-	// Realizar la actualización y obtener el hotel actualizado
-	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": id}, bson.M{"$set": updateData}).Decode(&hotelDto)
+	// Realizar la actualización incrementando la revisión y obtener el hotel actualizado
+	var updated models.Hotel
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": updateData, "$inc": bson.M{"revision": 1}}
+	err := withHotelTransaction(func(sc mongo.SessionContext) error {
+		if err := collection.FindOneAndUpdate(sc, bson.M{"_id": id}, update, opts).Decode(&updated); err != nil {
+			return err
+		}
+		// Registrar el evento para que search-api actualice el índice
+		return enqueueHotelEvent(sc, NewHotelEvent(models.HotelUpdated, updated))
+	})
 	if err != nil {
 		return models.Hotel{}, err
 	}
 
-	return hotelDto, nil
+	return updated, nil
 }
 
 // CheckDuplicateHotelExcludingCurrent verifica si ya existe un hotel con el mismo nombre y dirección, excluyendo el hotel actual
@@ -214,12 +257,16 @@ func CheckDuplicateHotelExcludingCurrent(hotelID primitive.ObjectID, hotelDto mo
 func DeleteHotel(id primitive.ObjectID) error {
 	collection := initializers.DB.Collection("hotels")
 
-	_, err := collection.DeleteOne(context.Background(), bson.M{"_id": id})
-	if err != nil {
-		return err
-	}
+	return withHotelTransaction(func(sc mongo.SessionContext) error {
+		var deleted models.Hotel
+		if err := collection.FindOneAndDelete(sc, bson.M{"_id": id}).Decode(&deleted); err != nil {
+			return err
+		}
 
-	return nil
+		// El borrado es un cambio más, por lo que lleva la siguiente revisión
+		deleted.Revision++
+		return enqueueHotelEvent(sc, NewHotelEvent(models.HotelDeleted, deleted))
+	})
 }
 
 // CheckDuplicateHotel verifica si ya existe un hotel con el mismo nombre y dirección
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hotel-api/initializers"
+	"hotel-api/models"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/streadway/amqp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nombre del exchange donde se publican los eventos de hoteles
+const hotelEventsExchange = "hotel_events"
+
+// Colección donde se guardan los eventos pendientes de publicar
+const outboxCollection = "outbox"
+
+const (
+	outboxPollInterval = 2 * time.Second
+	outboxLease        = 30 * time.Second // Tiempo que una entrada queda reservada mientras se publica
+	outboxMaxBackoff   = 5 * time.Minute
+	publishTimeout     = 5 * time.Second
+)
+
+// Horas que se conservan las entradas ya publicadas antes de que MongoDB las borre
+const defaultOutboxRetentionHours = 24 * 7
+
+// Avisa al relay que hay entradas nuevas para no esperar al próximo ciclo
+var outboxSignal = make(chan struct{}, 1)
+
+// enqueueHotelEvent guarda el evento en el outbox usando la sesión de la transacción
+func enqueueHotelEvent(ctx context.Context, event models.HotelEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hotel event: %w", err)
+	}
+
+	now := time.Now().UTC()
+	entry := models.OutboxEntry{
+		EventType:     event.EventType,
+		HotelID:       event.HotelID,
+		Payload:       payload,
+		Status:        models.OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	_, err = initializers.DB.Collection(outboxCollection).InsertOne(ctx, entry)
+	return err
+}
+
+// EnsureOutboxIndexes crea el índice TTL que borra las entradas publicadas después de
+// OUTBOX_RETENTION_HOURS horas. Las pendientes no tienen delivered_at y no vencen.
+func EnsureOutboxIndexes() {
+	hours, err := strconv.Atoi(os.Getenv("OUTBOX_RETENTION_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = defaultOutboxRetentionHours
+	}
+
+	_, err = initializers.DB.Collection(outboxCollection).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "delivered_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(hours * 3600)),
+	})
+	if err != nil {
+		log.Printf("Failed to create outbox indexes: %s", err)
+	}
+}
+
+// notifyOutbox despierta al relay después de confirmar una transacción
+func notifyOutbox() {
+	select {
+	case outboxSignal <- struct{}{}:
+	default:
+	}
+}
+
+// withHotelTransaction ejecuta fn dentro de una transacción de MongoDB.
+// Requiere que MongoDB corra como replica set.
+func withHotelTransaction(fn func(sc mongo.SessionContext) error) error {
+	session, err := initializers.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	if err != nil {
+		return err
+	}
+
+	notifyOutbox()
+	return nil
+}
+
+// StartOutboxRelay publica en segundo plano las entradas pendientes del outbox
+func StartOutboxRelay() {
+	go func() {
+		ticker := time.NewTicker(outboxPollInterval)
+		defer ticker.Stop()
+
+		for {
+			relayPendingEvents()
+
+			select {
+			case <-ticker.C:
+			case <-outboxSignal:
+			}
+		}
+	}()
+}
+
+// relayPendingEvents publica todas las entradas vencidas hasta vaciar el outbox
+func relayPendingEvents() {
+	for {
+		entry, err := claimNextOutboxEntry()
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to read outbox: %s", err)
+			return
+		}
+
+		if err := publishOutboxEntry(entry); err != nil {
+			log.Printf("Failed to publish outbox entry %s (attempt %d): %s", entry.ID.Hex(), entry.Attempts+1, err)
+			markOutboxRetry(entry, err)
+			return
+		}
+
+		markOutboxDelivered(entry)
+	}
+}
+
+// claimNextOutboxEntry reserva la entrada pendiente más antigua para que ningún otro relay la tome
+func claimNextOutboxEntry() (models.OutboxEntry, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"status":          models.OutboxPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(outboxLease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var entry models.OutboxEntry
+	err := initializers.DB.Collection(outboxCollection).FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&entry)
+	return entry, err
+}
+
+// markOutboxDelivered marca la entrada como publicada
+func markOutboxDelivered(entry models.OutboxEntry) {
+	now := time.Now().UTC()
+	update := bson.M{
+		"$set":   bson.M{"status": models.OutboxDelivered, "delivered_at": now},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"last_error": ""},
+	}
+	if _, err := initializers.DB.Collection(outboxCollection).UpdateByID(context.Background(), entry.ID, update); err != nil {
+		log.Printf("Failed to mark outbox entry %s as delivered: %s", entry.ID.Hex(), err)
+	}
+}
+
+// markOutboxRetry reprograma la entrada con backoff exponencial
+func markOutboxRetry(entry models.OutboxEntry, cause error) {
+	backoff := time.Duration(1<<uint(min(entry.Attempts, 16))) * time.Second
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+
+	update := bson.M{
+		"$set": bson.M{"next_attempt_at": time.Now().UTC().Add(backoff), "last_error": cause.Error()},
+		"$inc": bson.M{"attempts": 1},
+	}
+	if _, err := initializers.DB.Collection(outboxCollection).UpdateByID(context.Background(), entry.ID, update); err != nil {
+		log.Printf("Failed to reschedule outbox entry %s: %s", entry.ID.Hex(), err)
+	}
+}
+
+// publishOutboxEntry publica la entrada en RabbitMQ y espera la confirmación del broker
+func publishOutboxEntry(entry models.OutboxEntry) error {
+	// Reconectar si el broker se cayó desde la última publicación
+	if !initializers.RabbitMQConnected() {
+		if err := initializers.ConnectRabbitMQ(); err != nil {
+			return fmt.Errorf("RabbitMQ is not available: %w", err)
+		}
+	}
+
+	// Asegurarse de que el exchange de eventos esté declarado
+	err := initializers.RabbitMQChannel.ExchangeDeclare(
+		hotelEventsExchange, // nombre del exchange
+		"topic",             // tipo
+		true,                // durable
+		false,               // auto-deleted
+		false,               // internal
+		false,               // no-wait
+		nil,                 // argumentos adicionales
+	)
+	if err != nil {
+		initializers.CloseRabbitMQ()
+		return fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	// Publicar el evento usando el tipo como routing key
+	err = initializers.RabbitMQChannel.Publish(
+		hotelEventsExchange, // exchange
+		entry.EventType,     // routing key
+		false,               // mandatory
+		false,               // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    entry.ID.Hex(),
+			Timestamp:    entry.CreatedAt,
+			Type:         entry.EventType,
+			Body:         entry.Payload,
+		},
+	)
+	if err != nil {
+		initializers.CloseRabbitMQ()
+		return fmt.Errorf("failed to publish hotel event: %w", err)
+	}
+
+	select {
+	case confirm, ok := <-initializers.RabbitMQConfirms:
+		if !ok || !confirm.Ack {
+			initializers.CloseRabbitMQ()
+			return fmt.Errorf("broker did not acknowledge hotel event")
+		}
+	case <-time.After(publishTimeout):
+		// Sin confirmación no sabemos qué pasó; se reabre el canal y se reintenta
+		initializers.CloseRabbitMQ()
+		return fmt.Errorf("timed out waiting for broker confirmation")
+	}
+
+	log.Printf("Hotel event %s sent for hotel %s", entry.EventType, entry.HotelID)
+	return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ErrReservationAPIUnavailable se devuelve cuando no se puede consultar reservation-api
+var ErrReservationAPIUnavailable = errors.New("reservation-api is unavailable")
+
+// errReservationNotFound indica que la reserva no existe o no es del usuario
+var errReservationNotFound = errors.New("reservation not found")
+
+// Estado de una reserva en la que el huésped ya se fue del hotel
+const reservationCheckedOut = "checked_out"
+
+var reservationHTTP = &http.Client{Timeout: 3 * time.Second}
+
+// guestReservation son los datos de una reserva de reservation-api que se usan para validar reseñas
+type guestReservation struct {
+	ID      uint   `json:"id"`
+	UserID  uint   `json:"userId"`
+	HotelID string `json:"hotelId"`
+	Status  string `json:"status"`
+}
+
+// fetchReservation obtiene la reserva de reservation-api con el token del usuario, así
+// reservation-api verifica que la reserva sea suya
+func fetchReservation(token string, reservationID uint) (*guestReservation, error) {
+	baseURL := os.Getenv("RESERVATION_API_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3001"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/reservations/%d", baseURL, reservationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "Authorization", Value: token})
+
+	resp, err := reservationHTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReservationAPIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden, http.StatusBadRequest:
+		return nil, errReservationNotFound
+	default:
+		return nil, fmt.Errorf("%w: unexpected response %d", ErrReservationAPIUnavailable, resp.StatusCode)
+	}
+
+	var body struct {
+		Reservation guestReservation `json:"reservation"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReservationAPIUnavailable, err)
+	}
+	return &body.Reservation, nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotel-api/dtos"
+	"hotel-api/initializers"
+	"hotel-api/models"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Colección donde se guardan las reseñas
+const reviewsCollection = "reviews"
+
+// Errores de las reseñas
+var (
+	ErrReviewNotAllowed      = errors.New("only guests with a checked-out reservation at this hotel can review it")
+	ErrReviewExists          = errors.New("this reservation was already reviewed")
+	ErrReviewNotFound        = errors.New("review not found")
+	ErrInvalidReviewCategory = errors.New("invalid review category or rating")
+)
+
+// EnsureReviewIndexes crea los índices de las reseñas: una reseña por reserva y el listado por hotel
+func EnsureReviewIndexes() {
+	_, err := initializers.DB.Collection(reviewsCollection).Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "reservation_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "hotel_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Failed to create review indexes: %s", err)
+	}
+}
+
+// validateReviewCategories verifica que las categorías existan y que sus calificaciones estén en rango
+func validateReviewCategories(categories map[string]int) error {
+	for category, rating := range categories {
+		valid := false
+		for _, known := range models.ReviewCategories {
+			if category == known {
+				valid = true
+				break
+			}
+		}
+		if !valid || rating < models.MinRating || rating > models.MaxRating {
+			return fmt.Errorf("%w: %s", ErrInvalidReviewCategory, category)
+		}
+	}
+	return nil
+}
+
+// CreateReview guarda la reseña del usuario sobre el hotel. La reserva tiene que ser del usuario,
+// del hotel y estar finalizada (checked_out). La reseña queda pendiente de moderación.
+func CreateReview(hotelID primitive.ObjectID, userID uint, token string, reviewDto dtos.ReviewDto) (models.Review, error) {
+	if err := validateReviewCategories(reviewDto.Categories); err != nil {
+		return models.Review{}, err
+	}
+
+	if _, err := GetHotel(hotelID); err != nil {
+		return models.Review{}, err
+	}
+
+	reservation, err := fetchReservation(token, reviewDto.ReservationID)
+	if errors.Is(err, errReservationNotFound) {
+		return models.Review{}, ErrReviewNotAllowed
+	}
+	if err != nil {
+		return models.Review{}, err
+	}
+	if reservation.UserID != userID || reservation.HotelID != hotelID.Hex() || reservation.Status != reservationCheckedOut {
+		return models.Review{}, ErrReviewNotAllowed
+	}
+
+	review := models.Review{
+		ID:            primitive.NewObjectID(),
+		HotelID:       hotelID,
+		UserID:        userID,
+		ReservationID: reviewDto.ReservationID,
+		Overall:       reviewDto.Overall,
+		Categories:    reviewDto.Categories,
+		Text:          reviewDto.Text,
+		Status:        models.ReviewPending,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if _, err := initializers.DB.Collection(reviewsCollection).InsertOne(context.Background(), review); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.Review{}, ErrReviewExists
+		}
+		return models.Review{}, err
+	}
+
+	return review, nil
+}
+
+// findReviews devuelve una página de reseñas que cumplen el filtro junto con el total
+func findReviews(filter bson.M, sortOrder, page, size int) ([]models.Review, int64, error) {
+	collection := initializers.DB.Collection(reviewsCollection)
+
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: sortOrder}, {Key: "_id", Value: sortOrder}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	reviews := []models.Review{}
+	if err := cursor.All(context.Background(), &reviews); err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// GetHotelReviews obtiene una página de las reseñas aprobadas del hotel, las más nuevas primero
+func GetHotelReviews(hotelID primitive.ObjectID, page, size int) ([]models.Review, int64, error) {
+	return findReviews(bson.M{"hotel_id": hotelID, "status": models.ReviewApproved}, -1, page, size)
+}
+
+// GetReviewsByStatus obtiene una página de reseñas en el estado indicado, las más antiguas primero
+// para moderarlas en orden de llegada
+func GetReviewsByStatus(status string, page, size int) ([]models.Review, int64, error) {
+	return findReviews(bson.M{"status": status}, 1, page, size)
+}
+
+// ModerateReview aprueba o rechaza una reseña. Si la reseña entra o sale de las aprobadas se
+// recalculan las calificaciones del hotel y se publica el cambio para el buscador.
+func ModerateReview(reviewID primitive.ObjectID, status, note string, moderatorID uint) (models.Review, error) {
+	collection := initializers.DB.Collection(reviewsCollection)
+	now := time.Now().UTC()
+
+	var review models.Review
+	err := withHotelTransaction(func(sc mongo.SessionContext) error {
+		update := bson.M{"$set": bson.M{
+			"status":          status,
+			"moderation_note": note,
+			"moderated_by":    moderatorID,
+			"moderated_at":    now,
+		}}
+		var previous models.Review
+		if err := collection.FindOneAndUpdate(sc, bson.M{"_id": reviewID}, update).Decode(&previous); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return ErrReviewNotFound
+			}
+			return err
+		}
+
+		review = previous
+		review.Status = status
+		review.ModerationNote = note
+		review.ModeratedBy = moderatorID
+		review.ModeratedAt = &now
+
+		if previous.Status == status || (previous.Status != models.ReviewApproved && status != models.ReviewApproved) {
+			return nil
+		}
+		return refreshHotelRatings(sc, previous.HotelID)
+	})
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	return review, nil
+}
+
+// roundRating redondea un promedio a dos decimales
+func roundRating(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// refreshHotelRatings recalcula las calificaciones del hotel con sus reseñas aprobadas,
+// incrementa la revisión y registra el evento para que search-api actualice el índice.
+// Si el hotel ya no existe no hace nada.
+func refreshHotelRatings(sc mongo.SessionContext, hotelID primitive.ObjectID) error {
+	group := bson.M{
+		"_id":     nil,
+		"count":   bson.M{"$sum": 1},
+		"overall": bson.M{"$avg": "$overall"},
+	}
+	for _, category := range models.ReviewCategories {
+		group[category] = bson.M{"$avg": "$categories." + category}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"hotel_id": hotelID, "status": models.ReviewApproved}}},
+		{{Key: "$group", Value: group}},
+	}
+
+	cursor, err := initializers.DB.Collection(reviewsCollection).Aggregate(sc, pipeline)
+	if err != nil {
+		return err
+	}
+	var results []bson.M
+	if err := cursor.All(sc, &results); err != nil {
+		return err
+	}
+
+	ratings := bson.M{"rating_average": 0.0, "review_count": 0, "category_ratings": bson.M{}}
+	if len(results) > 0 {
+		result := results[0]
+		categories := bson.M{}
+		for _, category := range models.ReviewCategories {
+			// $avg devuelve null si ninguna reseña calificó la categoría
+			if average, ok := result[category].(float64); ok {
+				categories[category] = roundRating(average)
+			}
+		}
+		count, _ := result["count"].(int32)
+		overall, _ := result["overall"].(float64)
+		ratings = bson.M{"rating_average": roundRating(overall), "review_count": int(count), "category_ratings": categories}
+	}
+
+	var updated models.Hotel
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": ratings, "$inc": bson.M{"revision": 1}}
+	err = initializers.DB.Collection("hotels").FindOneAndUpdate(sc, bson.M{"_id": hotelID}, update, opts).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return enqueueHotelEvent(sc, NewHotelEvent(models.HotelUpdated, updated))
+}
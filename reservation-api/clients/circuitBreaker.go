@@ -0,0 +1,89 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen se devuelve mientras el circuito está abierto y no se hacen llamadas
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Estados del circuit breaker
+const (
+	circuitClosed   = "closed"    // las llamadas pasan normalmente
+	circuitOpen     = "open"      // las llamadas fallan sin intentarse
+	circuitHalfOpen = "half-open" // se deja pasar una llamada de prueba
+)
+
+// CircuitBreaker deja de llamar a un servicio después de varias fallas seguidas
+// y vuelve a probar cuando pasa el tiempo de espera.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	state     string
+	failures  int
+	threshold int           // Fallas seguidas que abren el circuito
+	cooldown  time.Duration // Tiempo que el circuito queda abierto antes de probar de nuevo
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+// NewCircuitBreaker crea un circuit breaker cerrado
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:     circuitClosed,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow indica si se puede hacer la llamada. Con el circuito abierto, pasado el tiempo
+// de espera se deja pasar una sola llamada de prueba.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// Ya hay una llamada de prueba en curso
+		return false
+	default:
+		return true
+	}
+}
+
+// Success registra una llamada exitosa y cierra el circuito
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = circuitClosed
+	b.failures = 0
+}
+
+// Failure registra una llamada fallida y abre el circuito si se llegó al límite
+// o si falló la llamada de prueba.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// State devuelve el estado actual del circuito
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
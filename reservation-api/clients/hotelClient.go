@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrHotelAPIUnavailable se devuelve cuando no se puede consultar hotel-api
+var ErrHotelAPIUnavailable = errors.New("hotel-api is unavailable")
+
+// Tiempo que se recuerda la respuesta de hotel-api. Los hoteles inexistentes se
+// recuerdan menos tiempo para que un hotel recién creado se pueda reservar enseguida.
+const (
+	hotelFoundTTL    = 5 * time.Minute
+	hotelNotFoundTTL = 30 * time.Second
+)
+
+// Circuit breaker de hotel-api
+const (
+	hotelBreakerThreshold = 5
+	hotelBreakerCooldown  = 30 * time.Second
+)
+
+type hotelCacheEntry struct {
+	exists    bool
+	expiresAt time.Time
+}
+
+// HotelClient consulta la existencia de hoteles en hotel-api
+type HotelClient struct {
+	baseURL string
+	secret  string // SECRET compartido con el que se firma el token de servicio para hotel-api
+	http    *http.Client
+	breaker *CircuitBreaker
+
+	mu    sync.Mutex
+	cache map[string]hotelCacheEntry
+}
+
+// NewHotelClient crea un cliente de hotel-api que se autentica con tokens de servicio firmados con secret
+func NewHotelClient(baseURL, secret string) *HotelClient {
+	return &HotelClient{
+		baseURL: baseURL,
+		secret:  secret,
+		http:    &http.Client{Timeout: 3 * time.Second},
+		breaker: NewCircuitBreaker(hotelBreakerThreshold, hotelBreakerCooldown),
+		cache:   make(map[string]hotelCacheEntry),
+	}
+}
+
+// HotelExists indica si el hotel existe en hotel-api. Usa la respuesta cacheada si
+// está vigente y no llama a hotel-api mientras el circuito esté abierto.
+func (c *HotelClient) HotelExists(hotelID string) (bool, error) {
+	if exists, ok := c.cached(hotelID); ok {
+		return exists, nil
+	}
+
+	if !c.breaker.Allow() {
+		return false, fmt.Errorf("%w: %v", ErrHotelAPIUnavailable, ErrCircuitOpen)
+	}
+
+	exists, err := c.checkExistence(hotelID)
+	if err != nil {
+		c.breaker.Failure()
+		return false, fmt.Errorf("%w: %v", ErrHotelAPIUnavailable, err)
+	}
+	c.breaker.Success()
+
+	ttl := hotelNotFoundTTL
+	if exists {
+		ttl = hotelFoundTTL
+	}
+	c.mu.Lock()
+	c.cache[hotelID] = hotelCacheEntry{exists: exists, expiresAt: time.Now().Add(ttl)}
+	c.mu.Unlock()
+
+	return exists, nil
+}
+
+// cached devuelve la respuesta cacheada del hotel si no venció
+func (c *HotelClient) cached(hotelID string) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cache[hotelID]
+	if !ok {
+		return false, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.cache, hotelID)
+		return false, false
+	}
+	return entry.exists, true
+}
+
+// checkExistence hace la consulta a hotel-api
+func (c *HotelClient) checkExistence(hotelID string) (bool, error) {
+	endpoint := fmt.Sprintf("%s/hotels/check-existence/%s", c.baseURL, url.PathEscape(hotelID))
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	token, err := ServiceToken(c.secret, "reservation-api")
+	if err != nil {
+		return false, err
+	}
+	req.AddCookie(&http.Cookie{Name: "Authorization", Value: token})
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected response from hotel API: %d", resp.StatusCode)
+	}
+}
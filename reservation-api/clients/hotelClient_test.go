@@ -0,0 +1,97 @@
+package clients
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Failure()
+	if !breaker.Allow() {
+		t.Fatal("breaker should stay closed below the threshold")
+	}
+	breaker.Failure()
+	if breaker.Allow() {
+		t.Fatal("breaker should open after reaching the threshold")
+	}
+
+	// Pasado el tiempo de espera se deja pasar una sola llamada de prueba
+	now = now.Add(time.Minute)
+	if !breaker.Allow() || breaker.Allow() {
+		t.Fatal("breaker should allow exactly one trial call when half-open")
+	}
+	breaker.Failure()
+	if breaker.State() != circuitOpen {
+		t.Fatalf("failed trial should reopen the breaker, got %s", breaker.State())
+	}
+
+	now = now.Add(time.Minute)
+	breaker.Allow()
+	breaker.Success()
+	if breaker.State() != circuitClosed || !breaker.Allow() {
+		t.Fatalf("successful trial should close the breaker, got %s", breaker.State())
+	}
+}
+
+func TestHotelExistsCachesResponses(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		cookie, err := r.Cookie("Authorization")
+		if err != nil {
+			t.Fatalf("expected the service token cookie")
+		}
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(cookie.Value, claims, func(*jwt.Token) (interface{}, error) { return []byte("secret"), nil })
+		if err != nil || claims["role"] != ServiceRole {
+			t.Errorf("expected a service token signed with the shared secret, got %v %v", claims, err)
+		}
+		if r.URL.Path == "/hotels/check-existence/found" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewHotelClient(server.URL, "secret")
+	for i := 0; i < 3; i++ {
+		if exists, err := client.HotelExists("found"); err != nil || !exists {
+			t.Fatalf("expected hotel to exist, got %v %v", exists, err)
+		}
+		if exists, err := client.HotelExists("missing"); err != nil || exists {
+			t.Fatalf("expected hotel to be missing, got %v %v", exists, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected one call per hotel, got %d", calls)
+	}
+}
+
+func TestHotelExistsOpensCircuitOnFailures(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewHotelClient(server.URL, "secret")
+	for i := 0; i < hotelBreakerThreshold+3; i++ {
+		if _, err := client.HotelExists("any"); !errors.Is(err, ErrHotelAPIUnavailable) {
+			t.Fatalf("expected ErrHotelAPIUnavailable, got %v", err)
+		}
+	}
+	if calls != hotelBreakerThreshold {
+		t.Errorf("expected calls to stop once the circuit opens, got %d", calls)
+	}
+}
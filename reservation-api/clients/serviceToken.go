@@ -0,0 +1,24 @@
+package clients
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Rol de los tokens con los que un servicio llama a otro
+const ServiceRole = "service"
+
+// Vigencia de los tokens de servicio; se firma uno nuevo en cada llamada
+const serviceTokenTTL = time.Minute
+
+// ServiceToken firma con el SECRET compartido un token de corta duración para que el
+// servicio indicado se autentique en otro servicio que valida los tokens de user-api
+func ServiceToken(secret, service string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  service,
+		"role": ServiceRole,
+		"exp":  time.Now().Add(serviceTokenTTL).Unix(),
+	})
+	return token.SignedString([]byte(secret))
+}
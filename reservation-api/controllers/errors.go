@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"reservation-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Código HTTP de cada error de negocio de las reservas
+var reservationErrorStatus = map[string]int{
+	services.ErrInvalidDateRange.Code:           http.StatusBadRequest,
+	services.ErrDateInPast.Code:                 http.StatusUnprocessableEntity,
+	services.ErrStayTooLong.Code:                http.StatusUnprocessableEntity,
+	services.ErrLeadTimeTooShort.Code:           http.StatusUnprocessableEntity,
+	services.ErrBookingTooFar.Code:              http.StatusUnprocessableEntity,
+	services.ErrHotelNotFound.Code:              http.StatusNotFound,
+	services.ErrHotelLookupUnavailable.Code:     http.StatusServiceUnavailable,
+	services.ErrUserNotFound.Code:               http.StatusNotFound,
+	services.ErrUserLookupUnavailable.Code:      http.StatusBadGateway,
+	services.ErrRoomTypeNotFound.Code:           http.StatusNotFound,
+	services.ErrRoomTypeCapacity.Code:           http.StatusBadRequest,
+	services.ErrNoAvailability.Code:             http.StatusConflict,
+	services.ErrInventoryInUse.Code:             http.StatusConflict,
+	services.ErrHoldNotFound.Code:               http.StatusNotFound,
+	services.ErrHoldExpired.Code:                http.StatusGone,
+	services.ErrHoldMismatch.Code:               http.StatusBadRequest,
+	services.ErrHoldLimitReached.Code:           http.StatusConflict,
+	services.ErrWaitlistEntryNotFound.Code:      http.StatusNotFound,
+	services.ErrRoomsAvailable.Code:             http.StatusConflict,
+	services.ErrWaitlistEntryClosed.Code:        http.StatusConflict,
+	services.ErrWaitlistEntryExists.Code:        http.StatusConflict,
+	services.ErrReservationNotFound.Code:        http.StatusNotFound,
+	services.ErrIllegalTransition.Code:          http.StatusConflict,
+	services.ErrNotModifiable.Code:              http.StatusConflict,
+	services.ErrPaymentNotFound.Code:            http.StatusNotFound,
+	services.ErrPaymentNotAllowed.Code:          http.StatusConflict,
+	services.ErrPaymentAmountLocked.Code:        http.StatusConflict,
+	services.ErrPaymentDeclined.Code:            http.StatusPaymentRequired,
+	services.ErrPaymentGatewayUnavailable.Code:  http.StatusBadGateway,
+	services.ErrPaymentRejected.Code:            http.StatusUnprocessableEntity,
+	services.ErrInvalidPaymentAmount.Code:       http.StatusBadRequest,
+	services.ErrInvalidWebhookSignature.Code:    http.StatusUnauthorized,
+	services.ErrInvalidWebhook.Code:             http.StatusBadRequest,
+	services.ErrSagaNotFound.Code:               http.StatusNotFound,
+	services.ErrInvalidPricingRule.Code:         http.StatusBadRequest,
+	services.ErrPricingRuleNotFound.Code:        http.StatusNotFound,
+	services.ErrCancellationPolicyNotFound.Code: http.StatusNotFound,
+	services.ErrInvalidPromoCode.Code:           http.StatusBadRequest,
+	services.ErrPromoCodeExists.Code:            http.StatusConflict,
+	services.ErrPromoCodeNotFound.Code:          http.StatusNotFound,
+	services.ErrPromoCodeExpired.Code:           http.StatusUnprocessableEntity,
+	services.ErrPromoNotEligible.Code:           http.StatusUnprocessableEntity,
+	services.ErrPromoUsageExceeded.Code:         http.StatusConflict,
+	services.ErrPromoNotCombinable.Code:         http.StatusUnprocessableEntity,
+}
+
+// respondReservationError responde con el código HTTP del error de negocio y su código,
+// o con 500 si es un error inesperado.
+func respondReservationError(c *gin.Context, err error) {
+	var reservationErr *services.ReservationError
+	if errors.As(err, &reservationErr) {
+		status, ok := reservationErrorStatus[reservationErr.Code]
+		if !ok {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": reservationErr.Message, "code": reservationErr.Code})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"reservation-api/dto"
+	"reservation-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateHold bloquea una habitación mientras el usuario confirma la reserva
+func CreateHold(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var holdDto dto.HoldDTO
+	if err := c.ShouldBindJSON(&holdDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	hold, err := services.CreateHold(user.ID, holdDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"hold": hold})
+}
+
+// GetHold obtiene un bloqueo del usuario por su token
+func GetHold(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := services.GetHold(user.ID, c.Param("token"))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hold": hold})
+}
+
+// ReleaseHold libera un bloqueo del usuario antes de que venza
+func ReleaseHold(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := services.ReleaseHold(user.ID, c.Param("token"))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hold": hold})
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reservation-api/middleware"
+	"reservation-api/models"
+	"reservation-api/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header con la firma HMAC-SHA256 del cuerpo de los webhooks de pagos
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// authorizeRequest es el medio de pago a autorizar
+type authorizeRequest struct {
+	PaymentMethod string `json:"paymentMethod" binding:"required"`
+}
+
+// amountRequest es el monto opcional de un cobro o una devolución; 0 usa el total disponible
+type amountRequest struct {
+	Amount float64 `json:"amount" binding:"min=0"`
+}
+
+// authorizePayment obtiene el pago de la URL y verifica que su reserva sea del usuario autenticado.
+// Si no se puede acceder ya se respondió.
+func authorizePayment(c *gin.Context, user middleware.AuthUser) (*models.PaymentIntent, bool) {
+	paymentID, err := strconv.ParseUint(c.Param("paymentID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return nil, false
+	}
+
+	intent, err := services.GetPaymentIntent(uint(paymentID))
+	if err != nil {
+		respondReservationError(c, err)
+		return nil, false
+	}
+
+	reservation, err := services.GetReservation(intent.ReservationID)
+	if err != nil {
+		respondReservationError(c, err)
+		return nil, false
+	}
+	if !user.IsAdmin() && reservation.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado: La reserva pertenece a otro usuario"})
+		return nil, false
+	}
+
+	return intent, true
+}
+
+// respondPayment responde con el pago. Si el proveedor rechazó o no pudo hacer la operación,
+// el error va junto con el estado en que quedó el pago.
+func respondPayment(c *gin.Context, intent *models.PaymentIntent, err error) {
+	var reservationErr *services.ReservationError
+	if err != nil && intent != nil && errors.As(err, &reservationErr) {
+		c.JSON(reservationErrorStatus[reservationErr.Code], gin.H{"error": reservationErr.Message, "code": reservationErr.Code, "payment": intent})
+		return
+	}
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment": intent})
+}
+
+// CreatePaymentIntent crea el intento de pago de una reserva pendiente
+func CreatePaymentIntent(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	intent, err := services.CreatePaymentIntent(reservation.ID)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"payment": intent})
+}
+
+// GetReservationPayments obtiene los intentos de pago de una reserva
+func GetReservationPayments(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	intents, err := services.GetPaymentIntents(reservation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payments": intents})
+}
+
+// GetPayment obtiene un pago con sus operaciones
+func GetPayment(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	intent, ok := authorizePayment(c, user)
+	if !ok {
+		return
+	}
+
+	operations, err := services.GetPaymentOperations(intent.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payment operations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment": intent, "operations": operations})
+}
+
+// AuthorizePayment autoriza el pago con el medio de pago del usuario
+func AuthorizePayment(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	intent, ok := authorizePayment(c, user)
+	if !ok {
+		return
+	}
+
+	var request authorizeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	intent, err = services.AuthorizePayment(intent.ID, request.PaymentMethod, services.UserActor(user.ID))
+	respondPayment(c, intent, err)
+}
+
+// bindAmount lee el monto opcional del cuerpo. Si es inválido ya se respondió.
+func bindAmount(c *gin.Context) (float64, bool) {
+	var request amountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return 0, false
+		}
+	}
+	return request.Amount, true
+}
+
+// CapturePayment cobra un pago autorizado
+func CapturePayment(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	intent, ok := authorizePayment(c, user)
+	if !ok {
+		return
+	}
+	amount, ok := bindAmount(c)
+	if !ok {
+		return
+	}
+
+	intent, err = services.CapturePayment(intent.ID, amount, services.UserActor(user.ID))
+	respondPayment(c, intent, err)
+}
+
+// VoidPayment anula un pago autorizado
+func VoidPayment(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	intent, ok := authorizePayment(c, user)
+	if !ok {
+		return
+	}
+
+	intent, err = services.VoidPayment(intent.ID, services.UserActor(user.ID))
+	respondPayment(c, intent, err)
+}
+
+// RefundPayment devuelve un pago cobrado
+func RefundPayment(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	intent, ok := authorizePayment(c, user)
+	if !ok {
+		return
+	}
+	amount, ok := bindAmount(c)
+	if !ok {
+		return
+	}
+
+	intent, err = services.RefundPayment(intent.ID, amount, services.UserActor(user.ID))
+	respondPayment(c, intent, err)
+}
+
+// PaymentWebhook recibe las notificaciones asíncronas del proveedor de pagos
+func PaymentWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	intent, err := services.HandlePaymentWebhook(body, c.GetHeader(paymentSignatureHeader))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment": intent})
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"net/http"
+	"reservation-api/dto"
+	"reservation-api/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetQuote cotiza una estadía en el hotel con el detalle por noche.
+// Con room_type_id se cotiza solo ese tipo de habitación.
+func GetQuote(c *gin.Context) {
+	hotelID := c.Param("hotelID")
+
+	checkIn, checkOut, guests, ok := parseStayQuery(c)
+	if !ok {
+		return
+	}
+
+	var roomTypeID uint64
+	if value := c.Query("room_type_id"); value != "" {
+		var err error
+		roomTypeID, err = strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room_type_id"})
+			return
+		}
+	}
+
+	quotes, err := services.QuoteStay(hotelID, uint(roomTypeID), checkIn, checkOut, guests)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.QuoteDTO{
+		HotelID:  hotelID,
+		CheckIn:  checkIn.Format("2006-01-02"),
+		CheckOut: checkOut.Format("2006-01-02"),
+		Guests:   guests,
+		Quotes:   quotes,
+	})
+}
+
+// CreateRateRule crea una tarifa por temporada o día de la semana para un tipo de habitación
+func CreateRateRule(c *gin.Context) {
+	roomTypeID, err := strconv.ParseUint(c.Param("roomTypeID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room type ID"})
+		return
+	}
+
+	var ruleDto dto.RateRuleDTO
+	if err := c.ShouldBindJSON(&ruleDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	rule, err := services.CreateRateRule(uint(roomTypeID), ruleDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rate": rule})
+}
+
+// GetRateRules obtiene las tarifas de un tipo de habitación
+func GetRateRules(c *gin.Context) {
+	roomTypeID, err := strconv.ParseUint(c.Param("roomTypeID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room type ID"})
+		return
+	}
+
+	rules, err := services.GetRateRules(uint(roomTypeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rules})
+}
+
+// CreateStayDiscount crea un descuento por cantidad de noches para un hotel
+func CreateStayDiscount(c *gin.Context) {
+	var discountDto dto.StayDiscountDTO
+	if err := c.ShouldBindJSON(&discountDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	discount, err := services.CreateStayDiscount(c.Param("hotelID"), discountDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"discount": discount})
+}
+
+// GetStayDiscounts obtiene los descuentos por cantidad de noches de un hotel
+func GetStayDiscounts(c *gin.Context) {
+	discounts, err := services.GetStayDiscounts(c.Param("hotelID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch discounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"discounts": discounts})
+}
+
+// CreateCancellationPolicy crea una política de cancelación para las tarifas de un hotel
+func CreateCancellationPolicy(c *gin.Context) {
+	var policyDto dto.CancellationPolicyDTO
+	if err := c.ShouldBindJSON(&policyDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	policy, err := services.CreateCancellationPolicy(c.Param("hotelID"), policyDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"policy": policy})
+}
+
+// GetCancellationPolicies obtiene las políticas de cancelación de un hotel
+func GetCancellationPolicies(c *gin.Context) {
+	policies, err := services.GetCancellationPolicies(c.Param("hotelID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cancellation policies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+// CreateHotelTax crea un impuesto para un hotel
+func CreateHotelTax(c *gin.Context) {
+	var taxDto dto.HotelTaxDTO
+	if err := c.ShouldBindJSON(&taxDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tax, err := services.CreateHotelTax(c.Param("hotelID"), taxDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"tax": tax})
+}
+
+// GetHotelTaxes obtiene los impuestos de un hotel
+func GetHotelTaxes(c *gin.Context) {
+	taxes, err := services.GetHotelTaxes(c.Param("hotelID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch taxes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"taxes": taxes})
+}
+
+// DeletePricingRule elimina una tarifa, un descuento o un impuesto
+func DeletePricingRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := services.DeletePricingRule(c.Param("kind"), uint(id)); err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing rule deleted successfully"})
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+	"reservation-api/dto"
+	"reservation-api/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreatePromoCode crea un código promocional
+func CreatePromoCode(c *gin.Context) {
+	var promoDto dto.PromoCodeDTO
+	if err := c.ShouldBindJSON(&promoDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	promo, err := services.CreatePromoCode(promoDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"promo": promo})
+}
+
+// GetPromoCodes obtiene los códigos promocionales con sus usos
+func GetPromoCodes(c *gin.Context) {
+	promos, err := services.GetPromoCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promos": promos})
+}
+
+// DeactivatePromoCode desactiva un código promocional para las reservas nuevas
+func DeactivatePromoCode(c *gin.Context) {
+	promoID, err := strconv.ParseUint(c.Param("promoID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promo code ID"})
+		return
+	}
+
+	promo, err := services.DeactivatePromoCode(uint(promoID))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promo": promo})
+}
@@ -1,68 +1,54 @@
 package controllers
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"reservation-api/dto"
+	"reservation-api/middleware"
+	"reservation-api/models"
 	"reservation-api/services"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Verificar usuario en user-api
-func GetAuthenticatedUser(c *gin.Context) (uint, error) {
-	// Obtener el token desde la cookie
-	token, err := c.Cookie("Authorization")
-	if err != nil {
-		return 0, fmt.Errorf("No autorizado: Token no encontrado")
-	}
-
-	// Hacer una solicitud HTTP a user-api para verificar el token
-	url := "http://localhost:3000/users/me" // Endpoint en user-api para validar usuario
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Cookie", "Authorization="+token) // Pasar el token en la cookie
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("No autorizado: No se pudo autenticar el usuario")
+// authenticatedUser devuelve el usuario autenticado por el middleware RequireAuth
+func authenticatedUser(c *gin.Context) (middleware.AuthUser, error) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return middleware.AuthUser{}, fmt.Errorf("No autorizado: Usuario no autenticado")
 	}
-	defer resp.Body.Close()
+	return user, nil
+}
 
-	// Leer la respuesta
-	body, _ := ioutil.ReadAll(resp.Body)
-	userID, err := ParseUserID(body)
+// authorizeReservation obtiene la reserva y verifica que sea del usuario autenticado.
+// Los administradores pueden acceder a cualquier reserva. Si no se puede acceder ya se respondió.
+func authorizeReservation(c *gin.Context, user middleware.AuthUser) (*models.Reservation, bool) {
+	reservationID, err := strconv.ParseUint(c.Param("reservationID"), 10, 32)
 	if err != nil {
-		return 0, fmt.Errorf("Error al obtener ID de usuario")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		return nil, false
 	}
 
-	return userID, nil
-}
-
-// Parsear el ID del usuario desde la respuesta de user-api
-func ParseUserID(body []byte) (uint, error) {
-	var response map[string]interface{}
-	err := json.Unmarshal(body, &response)
+	reservation, err := services.GetReservation(uint(reservationID))
 	if err != nil {
-		return 0, err
+		respondReservationError(c, err)
+		return nil, false
 	}
 
-	// Extraer el userID
-	userIDFloat, ok := response["id"].(float64)
-	if !ok {
-		return 0, fmt.Errorf("ID de usuario inválido")
+	if !user.IsAdmin() && reservation.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado: La reserva pertenece a otro usuario"})
+		return nil, false
 	}
 
-	return uint(userIDFloat), nil
+	return reservation, true
 }
 
 // Crear una reserva
 func CreateReservation(c *gin.Context) {
-	// Verificar usuario autenticado en user-api
-	userID, err := GetAuthenticatedUser(c)
+	// El usuario de la reserva es siempre el del token
+	user, err := authenticatedUser(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -76,12 +62,12 @@ func CreateReservation(c *gin.Context) {
 	}
 
 	// Asignar el UserID autenticado
-	dto.UserID = userID
+	dto.UserID = user.ID
 
 	// Llamar al servicio para crear la reserva (sin `c`)
 	reservation, err := services.CreateReservation(dto)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondReservationError(c, err)
 		return
 	}
 
@@ -99,8 +85,15 @@ func GetAllReservations(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"reservations": reservations})
 }
 
-// GetReservationsByUser obtiene las reservas de un usuario por su ID
+// GetReservationsByUser obtiene las reservas de un usuario por su ID.
+// Cada usuario solo puede ver las suyas; los administradores pueden ver las de cualquiera.
 func GetReservationsByUser(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	userID := c.Param("userID")
 	userIDInt, err := strconv.ParseUint(userID, 10, 32) // Convertir de string a uint
 	if err != nil {
@@ -108,6 +101,11 @@ func GetReservationsByUser(c *gin.Context) {
 		return
 	}
 
+	if !user.IsAdmin() && uint(userIDInt) != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Acceso denegado: Solo puede ver sus propias reservas"})
+		return
+	}
+
 	reservations, err := services.GetReservationsByUser(uint(userIDInt)) // Pasar como uint
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations for user"})
@@ -117,20 +115,153 @@ func GetReservationsByUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"reservations": reservations})
 }
 
-// CancelReservation cancela una reserva por su ID
+// GetReservation obtiene una reserva del usuario autenticado por su ID
+func GetReservation(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
+
+// CancelReservation cancela una reserva por su ID aplicando su política de cancelación.
+// Responde la reserva junto con el cargo y la devolución.
 func CancelReservation(c *gin.Context) {
-	reservationID := c.Param("reservationID")
-	reservationIDInt, err := strconv.ParseUint(reservationID, 10, 32) // Convertir de string a uint
+	user, err := authenticatedUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	request, ok := bindTransitionRequest(c)
+	if !ok {
+		return
+	}
+
+	reservation, cancellation, err := services.CancelReservation(reservation.ID, services.UserActor(user.ID), request.Reason)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation, "cancellation": cancellation})
+}
+
+// PreviewCancellation calcula el cargo y la devolución si la reserva se cancela ahora
+func PreviewCancellation(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	preview, err := services.PreviewCancellation(reservation.ID, time.Now())
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cancellation": preview})
+}
+
+// ModifyReservation cambia las fechas, el tipo de habitación o los huéspedes de una reserva
+func ModifyReservation(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	var modifyDto dto.ModifyReservationDTO
+	if err := c.ShouldBindJSON(&modifyDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	reservation, err = services.ModifyReservation(reservation.ID, modifyDto, services.UserActor(user.ID))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
+
+// parseStayQuery lee check_in, check_out y guests de la query. Si son inválidos ya se respondió.
+func parseStayQuery(c *gin.Context) (time.Time, time.Time, int, bool) {
+	checkIn, err := time.Parse("2006-01-02", c.Query("check_in"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check_in, expected YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, 0, false
+	}
+	checkOut, err := time.Parse("2006-01-02", c.Query("check_out"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check_out, expected YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, 0, false
+	}
+	if !checkOut.After(checkIn) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "check_out must be after check_in"})
+		return time.Time{}, time.Time{}, 0, false
+	}
+
+	guests, err := strconv.Atoi(c.DefaultQuery("guests", "1"))
+	if err != nil || guests < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid guests"})
+		return time.Time{}, time.Time{}, 0, false
+	}
+
+	return checkIn, checkOut, guests, true
+}
+
+// GetHotelAvailability devuelve las habitaciones libres de un hotel entre check_in y check_out
+func GetHotelAvailability(c *gin.Context) {
+	hotelID := c.Param("hotelID")
+
+	checkIn, checkOut, guests, ok := parseStayQuery(c)
+	if !ok {
 		return
 	}
 
-	err = services.CancelReservation(uint(reservationIDInt)) // Pasar como uint
+	roomTypes, err := services.GetHotelAvailability(hotelID, checkIn, checkOut, guests)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check availability"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Reservation canceled successfully"})
+	remaining := 0
+	for _, roomType := range roomTypes {
+		remaining += roomType.RemainingRooms
+	}
+
+	c.JSON(http.StatusOK, dto.AvailabilityDTO{
+		HotelID:        hotelID,
+		CheckIn:        checkIn.Format("2006-01-02"),
+		CheckOut:       checkOut.Format("2006-01-02"),
+		Guests:         guests,
+		Available:      remaining > 0,
+		RemainingRooms: remaining,
+		RoomTypes:      roomTypes,
+	})
 }
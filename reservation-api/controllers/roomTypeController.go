@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+	"reservation-api/dto"
+	"reservation-api/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateRoomType crea un tipo de habitación para el hotel
+func CreateRoomType(c *gin.Context) {
+	var roomTypeDto dto.RoomTypeDTO
+	if err := c.ShouldBindJSON(&roomTypeDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	roomType, err := services.CreateRoomType(c.Param("hotelID"), roomTypeDto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"roomType": roomType})
+}
+
+// GetRoomTypesByHotel obtiene los tipos de habitación del hotel
+func GetRoomTypesByHotel(c *gin.Context) {
+	roomTypes, err := services.GetRoomTypesByHotel(c.Param("hotelID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch room types"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roomTypes": roomTypes})
+}
+
+// UpdateRoomType modifica un tipo de habitación
+func UpdateRoomType(c *gin.Context) {
+	roomTypeID, err := strconv.ParseUint(c.Param("roomTypeID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room type ID"})
+		return
+	}
+
+	var roomTypeDto dto.RoomTypeDTO
+	if err := c.ShouldBindJSON(&roomTypeDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	roomType, err := services.UpdateRoomType(uint(roomTypeID), roomTypeDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roomType": roomType})
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"reservation-api/dto"
+	"reservation-api/models"
+	"reservation-api/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookReservation reserva y paga en un solo paso. Responde 201 con la reserva confirmada,
+// 202 si el pago quedó pendiente del proveedor, o el error junto con la saga compensada.
+func BookReservation(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var booking dto.BookingDTO
+	if err := c.ShouldBindJSON(&booking); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	booking.UserID = user.ID
+
+	saga, err := services.BookReservation(booking)
+	var reservationErr *services.ReservationError
+	if err != nil && saga != nil && errors.As(err, &reservationErr) {
+		c.JSON(reservationErrorStatus[reservationErr.Code], gin.H{"error": reservationErr.Message, "code": reservationErr.Code, "saga": saga})
+		return
+	}
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	if saga.Status != models.SagaCompleted {
+		c.JSON(http.StatusAccepted, gin.H{"saga": saga})
+		return
+	}
+
+	reservation, err := services.GetReservation(*saga.ReservationID)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"saga": saga, "reservation": reservation})
+}
+
+// GetBookingSagas lista las sagas con el estado indicado en ?status=, o las que no terminaron
+func GetBookingSagas(c *gin.Context) {
+	sagas, err := services.GetBookingSagas(c.Query("status"))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sagas": sagas})
+}
+
+// GetBookingSaga obtiene una saga con los pasos ejecutados y compensados
+func GetBookingSaga(c *gin.Context) {
+	sagaID, err := strconv.ParseUint(c.Param("sagaID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saga ID"})
+		return
+	}
+
+	saga, steps, err := services.GetBookingSaga(uint(sagaID))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saga": saga, "steps": steps})
+}
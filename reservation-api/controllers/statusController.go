@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"net/http"
+	"reservation-api/models"
+	"reservation-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transitionRequest es el cuerpo opcional de los endpoints de cambio de estado
+type transitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// bindTransitionRequest lee el motivo opcional del cambio de estado; un cuerpo vacío es válido.
+// Si el cuerpo es inválido ya se respondió.
+func bindTransitionRequest(c *gin.Context) (transitionRequest, bool) {
+	var request transitionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return request, false
+		}
+	}
+	return request, true
+}
+
+// transitionReservation cambia el estado de la reserva indicada en la URL.
+// El usuario autenticado queda registrado como autor del cambio. Un usuario solo puede
+// cambiar sus propias reservas; las rutas de uso exclusivo del hotel exigen además ser administrador.
+func transitionReservation(c *gin.Context, status string) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	request, ok := bindTransitionRequest(c)
+	if !ok {
+		return
+	}
+
+	reservation, err = services.TransitionReservation(reservation.ID, status, services.UserActor(user.ID), request.Reason)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
+
+// ConfirmReservation confirma una reserva pendiente
+func ConfirmReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusConfirmed)
+}
+
+// CheckInReservation registra la llegada del huésped
+func CheckInReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusCheckedIn)
+}
+
+// CheckOutReservation registra la salida del huésped
+func CheckOutReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusCheckedOut)
+}
+
+// NoShowReservation marca que el huésped no se presentó
+func NoShowReservation(c *gin.Context) {
+	transitionReservation(c, models.StatusNoShow)
+}
+
+// GetReservationHistory obtiene el historial de estados de una reserva
+func GetReservationHistory(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, ok := authorizeReservation(c, user)
+	if !ok {
+		return
+	}
+
+	history, err := services.GetReservationHistory(reservation.ID)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
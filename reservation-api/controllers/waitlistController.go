@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"reservation-api/dto"
+	"reservation-api/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JoinWaitlist anota al usuario autenticado en la lista de espera de fechas sin disponibilidad
+func JoinWaitlist(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var waitlistDto dto.WaitlistDTO
+	if err := c.ShouldBindJSON(&waitlistDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	entry, err := services.JoinWaitlist(user.ID, waitlistDto)
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"entry": entry})
+}
+
+// GetWaitlist obtiene las entradas de la lista de espera del usuario autenticado
+func GetWaitlist(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := services.GetWaitlistByUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// LeaveWaitlist saca al usuario autenticado de la lista de espera
+func LeaveWaitlist(c *gin.Context) {
+	user, err := authenticatedUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	entryID, err := strconv.ParseUint(c.Param("entryID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist entry ID"})
+		return
+	}
+
+	entry, err := services.LeaveWaitlist(user.ID, uint(entryID))
+	if err != nil {
+		respondReservationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
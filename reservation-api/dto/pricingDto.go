@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"reservation-api/pricing"
+	"time"
+)
+
+// RateRuleDTO son los datos para crear una tarifa de un tipo de habitación.
+// Las fechas tienen el formato YYYY-MM-DD y los días van de 0 (domingo) a 6 (sábado).
+type RateRuleDTO struct {
+	Name       string  `json:"name" binding:"required"`
+	StartDate  string  `json:"startDate"`
+	EndDate    string  `json:"endDate"`
+	DaysOfWeek []int   `json:"daysOfWeek" binding:"dive,min=0,max=6"`
+	Rate       float64 `json:"rate" binding:"min=0"`
+	Priority   int     `json:"priority"`
+
+	// Política de cancelación del hotel para esta tarifa; opcional
+	CancellationPolicyID *uint `json:"cancellationPolicyId"`
+}
+
+// CancellationPolicyDTO son los datos para crear una política de cancelación de un hotel.
+// Una política no reembolsable ignora los días y el porcentaje.
+type CancellationPolicyDTO struct {
+	Name           string  `json:"name" binding:"required"`
+	Refundable     bool    `json:"refundable"`
+	FreeDaysBefore int     `json:"freeDaysBefore" binding:"min=0"`
+	PenaltyPercent float64 `json:"penaltyPercent" binding:"min=0,max=100"`
+}
+
+// CancellationPreviewDTO es lo que se cobra y se devuelve si la reserva se cancela ahora
+type CancellationPreviewDTO struct {
+	ReservationID uint                       `json:"reservation_id"`
+	Policy        pricing.CancellationPolicy `json:"policy"`
+	FreeUntil     *time.Time                 `json:"free_until,omitempty"` // Vacío si la tarifa no es reembolsable
+	Currency      string                     `json:"currency"`
+	TotalPrice    float64                    `json:"total_price"`
+	Paid          float64                    `json:"paid"`    // Cobrado y no devuelto
+	Penalty       float64                    `json:"penalty"` // Lo que se queda el hotel
+	Refund        float64                    `json:"refund"`  // Lo que se devuelve de lo cobrado
+
+	// Operaciones con el proveedor que todavía no se pudieron hacer; se reintentan en segundo plano
+	PendingSettlements int `json:"pending_settlements,omitempty"`
+}
+
+// StayDiscountDTO son los datos para crear un descuento por cantidad de noches
+type StayDiscountDTO struct {
+	MinNights int     `json:"minNights" binding:"required,min=1"`
+	Percent   float64 `json:"percent" binding:"gt=0,max=100"`
+}
+
+// PromoCodeDTO son los datos para crear un código promocional. Las fechas tienen el formato
+// YYYY-MM-DD; sin hoteles el código vale para todos y los límites de uso en 0 no limitan.
+type PromoCodeDTO struct {
+	Code           string   `json:"code" binding:"required,max=50"`
+	Kind           string   `json:"kind" binding:"required,oneof=percent fixed"`
+	Amount         float64  `json:"amount" binding:"gt=0"`
+	ValidFrom      string   `json:"validFrom"`
+	ValidUntil     string   `json:"validUntil"`
+	HotelIDs       []string `json:"hotelIds"`
+	MinNights      int      `json:"minNights" binding:"min=0"`
+	MaxUses        int      `json:"maxUses" binding:"min=0"`
+	MaxUsesPerUser int      `json:"maxUsesPerUser" binding:"min=0"`
+	Stackable      bool     `json:"stackable"`
+}
+
+// HotelTaxDTO son los datos para crear un impuesto de un hotel
+type HotelTaxDTO struct {
+	Name   string  `json:"name" binding:"required"`
+	Kind   string  `json:"kind" binding:"required,oneof=percent per_night per_guest_night"`
+	Amount float64 `json:"amount" binding:"gt=0"`
+}
+
+// RoomTypeQuoteDTO es la cotización de un tipo de habitación
+type RoomTypeQuoteDTO struct {
+	RoomTypeID uint   `json:"room_type_id"`
+	Name       string `json:"name"`
+	pricing.Quote
+}
+
+// QuoteDTO es la respuesta del endpoint de cotización
+type QuoteDTO struct {
+	HotelID  string             `json:"hotel_id"`
+	CheckIn  string             `json:"check_in"`
+	CheckOut string             `json:"check_out"`
+	Guests   int                `json:"guests"`
+	Quotes   []RoomTypeQuoteDTO `json:"quotes"`
+}
@@ -4,7 +4,65 @@ import "time"
 
 type ReservationDTO struct {
 	HotelID    string    `json:"hotelId" binding:"required"`
+	RoomTypeID uint      `json:"roomTypeId" binding:"required"`
+	Guests     int       `json:"guests" binding:"omitempty,min=1"`
 	FechaDesde time.Time `json:"fechaDesde" binding:"required"`
 	FechaHasta time.Time `json:"fechaHasta" binding:"required"`
-	UserID     uint      `json:"-"` // Evitar que el usuario lo pase manualmente
+	HoldToken  string    `json:"holdToken"` // Bloqueo a convertir en reserva; opcional
+	PromoCode  string    `json:"promoCode"` // Código promocional; opcional
+	UserID     uint      `json:"-"`         // Evitar que el usuario lo pase manualmente
+}
+
+// HoldDTO es la habitación y el rango de fechas a bloquear mientras se confirma la reserva
+type HoldDTO struct {
+	HotelID    string    `json:"hotelId" binding:"required"`
+	RoomTypeID uint      `json:"roomTypeId" binding:"required"`
+	Guests     int       `json:"guests" binding:"omitempty,min=1"`
+	FechaDesde time.Time `json:"fechaDesde" binding:"required"`
+	FechaHasta time.Time `json:"fechaHasta" binding:"required"`
+}
+
+// BookingDTO es una reserva que se paga en el momento con el medio de pago indicado
+type BookingDTO struct {
+	ReservationDTO
+	PaymentMethod string `json:"paymentMethod" binding:"required"`
+}
+
+// ModifyReservationDTO son los nuevos datos de una reserva existente.
+// Si no se indica el tipo de habitación o los huéspedes se conservan los actuales.
+type ModifyReservationDTO struct {
+	RoomTypeID uint      `json:"roomTypeId"`
+	Guests     int       `json:"guests" binding:"omitempty,min=1"`
+	FechaDesde time.Time `json:"fechaDesde" binding:"required"`
+	FechaHasta time.Time `json:"fechaHasta" binding:"required"`
+	Reason     string    `json:"reason"`
+}
+
+// WaitlistDTO es la habitación y el rango de fechas que espera un usuario
+type WaitlistDTO struct {
+	HotelID    string    `json:"hotelId" binding:"required"`
+	RoomTypeID uint      `json:"roomTypeId" binding:"required"`
+	Guests     int       `json:"guests" binding:"omitempty,min=1"`
+	FechaDesde time.Time `json:"fechaDesde" binding:"required"`
+	FechaHasta time.Time `json:"fechaHasta" binding:"required"`
+}
+
+// AvailabilityDTO es la disponibilidad de un hotel para un rango de fechas
+type AvailabilityDTO struct {
+	HotelID        string                    `json:"hotel_id"`
+	CheckIn        string                    `json:"check_in"`
+	CheckOut       string                    `json:"check_out"`
+	Guests         int                       `json:"guests"`
+	Available      bool                      `json:"available"`
+	RemainingRooms int                       `json:"remaining_rooms"`
+	RoomTypes      []RoomTypeAvailabilityDTO `json:"room_types"`
+}
+
+// RoomTypeAvailabilityDTO son las habitaciones libres de un tipo de habitación
+type RoomTypeAvailabilityDTO struct {
+	RoomTypeID     uint    `json:"room_type_id"`
+	Name           string  `json:"name"`
+	Capacity       int     `json:"capacity"`
+	BaseRate       float64 `json:"base_rate"`
+	RemainingRooms int     `json:"remaining_rooms"`
 }
@@ -0,0 +1,12 @@
+package dto
+
+// RoomTypeDTO son los datos para crear o modificar un tipo de habitación
+type RoomTypeDTO struct {
+	Name     string  `json:"name" binding:"required"`
+	Capacity int     `json:"capacity" binding:"required,min=1"`
+	Count    int     `json:"count" binding:"min=0"`
+	BaseRate float64 `json:"baseRate" binding:"min=0"`
+
+	// Política de cancelación del hotel para la tarifa base; opcional
+	CancellationPolicyID *uint `json:"cancellationPolicyId"`
+}
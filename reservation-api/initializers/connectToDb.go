@@ -2,18 +2,34 @@ package initializers
 
 import (
 	"os"
+	"time"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func ConnectToDb() {
+// utcDSN fuerza loc=UTC en el DSN. Las noches del inventario y las fechas de tarifas y promociones
+// son medianoches UTC guardadas en columnas type:date; con otra zona el driver las convierte
+// antes de escribirlas y de compararlas, y se guarda o se busca otro día.
+func utcDSN(dsn string) (string, error) {
+	config, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	config.Loc = time.UTC
+	config.ParseTime = true
+	return config.FormatDSN(), nil
+}
 
-	var err error
+func ConnectToDb() {
 
-	dsn := os.Getenv("DB")
+	dsn, err := utcDSN(os.Getenv("DB"))
+	if err != nil {
+		panic("invalid DB connection string")
+	}
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
 
 	if err != nil {
@@ -0,0 +1,22 @@
+package initializers
+
+import (
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestUTCDSN(t *testing.T) {
+	dsn, err := utcDSN("api_user:@tcp(localhost:3306)/prueba?charset=utf8mb4&parseTime=True&loc=Local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Loc != time.UTC || !config.ParseTime || config.Params["charset"] != "utf8mb4" || config.DBName != "prueba" {
+		t.Errorf("expected the same DSN in UTC, got %s", dsn)
+	}
+}
@@ -4,5 +4,5 @@ package initializers
 import "reservation-api/models"
 
 func SyncDatabase() {
-	DB.AutoMigrate(&models.Reservation{})
+	DB.AutoMigrate(&models.Reservation{}, &models.ReservationStatusChange{}, &models.RoomType{}, &models.RoomInventory{}, &models.RateRule{}, &models.StayDiscount{}, &models.HotelTax{}, &models.IdempotencyKey{}, &models.RoomHold{}, &models.WaitlistEntry{}, &models.OutboxEntry{}, &models.PaymentIntent{}, &models.PaymentOperation{}, &models.BookingSaga{}, &models.BookingSagaStep{}, &models.CancellationPolicy{}, &models.PromoCode{}, &models.PromoRedemption{}, &models.PaymentSettlement{})
 }
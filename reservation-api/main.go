@@ -1,8 +1,12 @@
 package main
 
 import (
+	"log"
 	"reservation-api/initializers"
+	"reservation-api/middleware"
 	"reservation-api/routes"
+	"reservation-api/services"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -12,23 +16,76 @@ func init() {
 	initializers.LoadEnvVariables() // Cargar variables de entorno
 	initializers.ConnectToDb()      // Conectar a la base de datos
 	initializers.SyncDatabase()     // Sincronizar la base de datos
+
+	// Conectar a RabbitMQ. Si el broker no está disponible los eventos quedan
+	// en el outbox y el relay reintenta la conexión más tarde.
+	if err := initializers.ConnectRabbitMQ(); err != nil {
+		log.Printf("RabbitMQ not available, reservation events will be relayed later: %s", err)
+	}
 }
 
 func main() {
+	// Publicar en segundo plano los eventos pendientes del outbox
+	services.StartOutboxRelay()
+
 	r := gin.Default()
 
 	// Configuración CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3001"}, // Cambia esto por el origen correcto de tu frontend
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", middleware.IdempotencyHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.IdempotentReplayHeader},
 		AllowCredentials: true,
 	}))
 
 	// Rutas y controladores de reservas
 	routes.SetupReservationRoutes(r)
 
+	// Borrar periódicamente las claves de idempotencia vencidas
+	runEvery(time.Hour, func() error {
+		_, err := services.PurgeExpiredIdempotencyKeys(time.Now())
+		return err
+	})
+
+	// Liberar los bloqueos de habitación vencidos y devolver las noches al inventario
+	runEvery(time.Minute, func() error {
+		released, err := services.ReleaseExpiredHolds(time.Now())
+		if released > 0 {
+			log.Printf("released %d expired holds", released)
+		}
+		return err
+	})
+
+	// Terminar las sagas de reserva que esperan el pago, vencieron o deben reintentar una compensación
+	runEvery(time.Minute, func() error {
+		finished, err := services.ResolveStuckSagas(time.Now())
+		if finished > 0 {
+			log.Printf("resolved %d booking sagas", finished)
+		}
+		return err
+	})
+
+	// Reintentar las devoluciones, cobros y anulaciones que el proveedor de pagos no pudo hacer
+	runEvery(time.Minute, func() error {
+		settled, err := services.RetryPaymentSettlements(time.Now())
+		if settled > 0 {
+			log.Printf("settled %d pending payment operations", settled)
+		}
+		return err
+	})
+
 	// Ejecutar el servidor
 	r.Run() // El puerto lo define desde el .env
 }
+
+// runEvery ejecuta task en segundo plano cada interval y registra sus errores
+func runEvery(interval time.Duration, task func() error) {
+	go func() {
+		for range time.Tick(interval) {
+			if err := task(); err != nil {
+				log.Printf("background task failed: %v", err)
+			}
+		}
+	}()
+}
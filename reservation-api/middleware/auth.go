@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Rol de los administradores en los tokens de user-api
+const RoleAdmin = "admin"
+
+// Clave del usuario autenticado en el contexto de gin
+const userKey = "user"
+
+// AuthUser es el usuario autenticado con el token
+type AuthUser struct {
+	ID   uint
+	Role string
+}
+
+// IsAdmin indica si el usuario es administrador
+func (u AuthUser) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// ParseToken valida el token firmado por user-api y devuelve el usuario.
+// Los tokens tienen los claims sub (ID del usuario), role y exp.
+func ParseToken(tokenString, secret string) (AuthUser, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return AuthUser{}, fmt.Errorf("invalid token")
+	}
+
+	// exp es obligatorio: un token sin vencimiento no se acepta
+	if _, ok := claims["exp"].(float64); !ok {
+		return AuthUser{}, fmt.Errorf("token without expiration")
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok || sub <= 0 {
+		return AuthUser{}, fmt.Errorf("invalid subject")
+	}
+	role, _ := claims["role"].(string)
+
+	return AuthUser{ID: uint(sub), Role: role}, nil
+}
+
+// RequireAuth verifica el token de la cookie Authorization y guarda el usuario en el contexto
+func RequireAuth(c *gin.Context) {
+	tokenString, err := c.Cookie("Authorization")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No autorizado: Token no encontrado"})
+		return
+	}
+
+	secret := os.Getenv("SECRET")
+	if secret == "" {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error del servidor: JWT secret no configurado"})
+		return
+	}
+
+	user, err := ParseToken(tokenString, secret)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No autorizado: Token inválido"})
+		return
+	}
+
+	c.Set(userKey, user)
+	c.Next()
+}
+
+// RequireAdmin permite continuar solo a los administradores. Se usa después de RequireAuth.
+func RequireAdmin(c *gin.Context) {
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No autorizado: Usuario no autenticado"})
+		return
+	}
+
+	if !user.IsAdmin() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acceso denegado: Se requieren permisos de administrador"})
+		return
+	}
+
+	c.Next()
+}
+
+// CurrentUser devuelve el usuario autenticado por RequireAuth
+func CurrentUser(c *gin.Context) (AuthUser, bool) {
+	value, exists := c.Get(userKey)
+	if !exists {
+		return AuthUser{}, false
+	}
+	user, ok := value.(AuthUser)
+	return user, ok
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "test-secret"
+
+func signToken(t *testing.T, claims jwt.MapClaims, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestParseToken(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+
+	user, err := ParseToken(signToken(t, jwt.MapClaims{"sub": float64(7), "role": "admin", "exp": exp}, testSecret), testSecret)
+	if err != nil || user.ID != 7 || !user.IsAdmin() {
+		t.Fatalf("unexpected result: %+v %v", user, err)
+	}
+
+	invalid := map[string]string{
+		"wrong secret":  signToken(t, jwt.MapClaims{"sub": float64(7), "exp": exp}, "other"),
+		"expired":       signToken(t, jwt.MapClaims{"sub": float64(7), "exp": time.Now().Add(-time.Hour).Unix()}, testSecret),
+		"no expiration": signToken(t, jwt.MapClaims{"sub": float64(7)}, testSecret),
+		"no subject":    signToken(t, jwt.MapClaims{"role": "admin", "exp": exp}, testSecret),
+		"garbage":       "not-a-token",
+	}
+	for name, token := range invalid {
+		if _, err := ParseToken(token, testSecret); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRequireAuthAndAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("SECRET", testSecret)
+
+	router := gin.New()
+	router.GET("/me", RequireAuth, func(c *gin.Context) {
+		user, _ := CurrentUser(c)
+		c.JSON(http.StatusOK, gin.H{"id": user.ID})
+	})
+	router.GET("/admin", RequireAuth, RequireAdmin, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	exp := time.Now().Add(time.Hour).Unix()
+	userToken := signToken(t, jwt.MapClaims{"sub": float64(3), "role": "user", "exp": exp}, testSecret)
+	adminToken := signToken(t, jwt.MapClaims{"sub": float64(1), "role": "admin", "exp": exp}, testSecret)
+
+	cases := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/me", "", http.StatusUnauthorized},
+		{"/me", "invalid", http.StatusUnauthorized},
+		{"/me", userToken, http.StatusOK},
+		{"/admin", userToken, http.StatusForbidden},
+		{"/admin", adminToken, http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.token != "" {
+			req.AddCookie(&http.Cookie{Name: "Authorization", Value: tc.token})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%s with token %q: expected %d, got %d", tc.path, tc.token, tc.status, w.Code)
+		}
+	}
+}
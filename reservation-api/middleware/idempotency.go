@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header con el que el cliente identifica una solicitud que puede reintentar
+const IdempotencyHeader = "Idempotency-Key"
+
+// Header que indica que la respuesta es la guardada de la solicitud original
+const IdempotentReplayHeader = "Idempotent-Replayed"
+
+// Largo máximo de una clave de idempotencia
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyRecord es una clave ya usada por el usuario
+type IdempotencyRecord struct {
+	RequestHash string
+	Completed   bool // false mientras la solicitud original se procesa
+	StatusCode  int
+	Body        []byte
+}
+
+// IdempotencyStore guarda las claves de idempotencia de cada usuario
+type IdempotencyStore interface {
+	// Begin reserva la clave para la solicitud. Si la clave ya existe y no venció devuelve
+	// su registro sin modificarlo; si no existía (o venció) la reserva y devuelve nil.
+	Begin(userID uint, key, requestHash string, expiresAt time.Time) (*IdempotencyRecord, error)
+	// Complete guarda la respuesta de la solicitud
+	Complete(userID uint, key string, statusCode int, body []byte) error
+	// Release libera la clave para que el cliente pueda reintentar
+	Release(userID uint, key string) error
+}
+
+// responseRecorder copia la respuesta del handler para guardarla
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// requestHash identifica el método, la ruta y el cuerpo de la solicitud
+func requestHash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Idempotency hace que reintentar una solicitud con el mismo header Idempotency-Key
+// devuelva la respuesta original en lugar de ejecutarla de nuevo. Las claves son por
+// usuario y vencen después de ttl. Se usa después de RequireAuth; sin header no hace nada.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No autorizado: Usuario no autenticado"})
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+
+		existing, err := store.Begin(user.ID, key, hash, time.Now().Add(ttl))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Idempotency-Key"})
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != hash:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used with a different request", "code": "idempotency_key_reused"})
+			case !existing.Completed:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress", "code": "idempotency_key_in_progress"})
+			default:
+				c.Header(IdempotentReplayHeader, "true")
+				c.Data(existing.StatusCode, "application/json; charset=utf-8", existing.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		// Si el handler entra en pánico la clave se libera antes de que gin.Recovery responda 500;
+		// si no, quedaría en curso hasta vencer y el cliente no podría reintentar
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				releaseIdempotencyKey(store, user.ID, key)
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Los errores del servidor no se guardan: el cliente tiene que poder reintentar
+		if recorder.Status() >= http.StatusInternalServerError {
+			releaseIdempotencyKey(store, user.ID, key)
+			return
+		}
+		if err := store.Complete(user.ID, key, recorder.Status(), recorder.body.Bytes()); err != nil {
+			// Sin la respuesta guardada no se puede repetir: se libera la clave para permitir el reintento
+			log.Printf("Error al guardar la respuesta de la clave de idempotencia %q del usuario %d: %v", key, user.ID, err)
+			releaseIdempotencyKey(store, user.ID, key)
+		}
+	}
+}
+
+// releaseIdempotencyKey libera la clave; si falla solo se registra, porque la clave vence sola después del ttl
+func releaseIdempotencyKey(store IdempotencyStore, userID uint, key string) {
+	if err := store.Release(userID, key); err != nil {
+		log.Printf("Error al liberar la clave de idempotencia %q del usuario %d: %v", key, userID, err)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryStore struct {
+	records map[string]*IdempotencyRecord
+}
+
+func storeKey(userID uint, key string) string {
+	return fmt.Sprintf("%d/%s", userID, key)
+}
+
+func (s *memoryStore) Begin(userID uint, key, requestHash string, expiresAt time.Time) (*IdempotencyRecord, error) {
+	if record, ok := s.records[storeKey(userID, key)]; ok {
+		return record, nil
+	}
+	s.records[storeKey(userID, key)] = &IdempotencyRecord{RequestHash: requestHash}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(userID uint, key string, statusCode int, body []byte) error {
+	record := s.records[storeKey(userID, key)]
+	record.Completed, record.StatusCode, record.Body = true, statusCode, body
+	return nil
+}
+
+func (s *memoryStore) Release(userID uint, key string) error {
+	delete(s.records, storeKey(userID, key))
+	return nil
+}
+
+func idempotencyRouter(store IdempotencyStore, calls *int, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/create", func(c *gin.Context) {
+		c.Set(userKey, AuthUser{ID: 1})
+	}, Idempotency(store, time.Hour), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return r
+}
+
+func send(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysOriginalResponse(t *testing.T) {
+	calls := 0
+	r := idempotencyRouter(&memoryStore{records: map[string]*IdempotencyRecord{}}, &calls, http.StatusCreated)
+
+	first := send(r, "abc", `{"hotelId":"1"}`)
+	second := send(r, "abc", `{"hotelId":"1"}`)
+	if calls != 1 {
+		t.Fatalf("handler should run once, ran %d times", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("expected replay of %d %s, got %d %s", first.Code, first.Body, second.Code, second.Body)
+	}
+	if second.Header().Get(IdempotentReplayHeader) != "true" {
+		t.Error("replayed response should be marked")
+	}
+
+	if w := send(r, "abc", `{"hotelId":"2"}`); w.Code != http.StatusConflict {
+		t.Errorf("reusing the key with another body should conflict, got %d", w.Code)
+	}
+
+	send(r, "", `{"hotelId":"1"}`)
+	send(r, "", `{"hotelId":"1"}`)
+	if calls != 3 {
+		t.Errorf("requests without key should always run, ran %d times", calls)
+	}
+}
+
+func TestIdempotencyInProgressConflicts(t *testing.T) {
+	calls := 0
+	store := &memoryStore{records: map[string]*IdempotencyRecord{
+		storeKey(1, "abc"): {RequestHash: requestHash(http.MethodPost, "/create", []byte("{}"))},
+	}}
+	r := idempotencyRouter(store, &calls, http.StatusCreated)
+
+	if w := send(r, "abc", "{}"); w.Code != http.StatusConflict || calls != 0 {
+		t.Errorf("expected conflict while in progress, got %d after %d calls", w.Code, calls)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	calls := 0
+	store := &memoryStore{records: map[string]*IdempotencyRecord{}}
+	r := idempotencyRouter(store, &calls, http.StatusInternalServerError)
+
+	send(r, "abc", "{}")
+	send(r, "abc", "{}")
+	if calls != 2 || len(store.records) != 0 {
+		t.Errorf("server errors should not be stored: %d calls, %d records", calls, len(store.records))
+	}
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	store := &memoryStore{records: map[string]*IdempotencyRecord{}}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.POST("/create", func(c *gin.Context) {
+		c.Set(userKey, AuthUser{ID: 1})
+	}, Idempotency(store, time.Hour), func(c *gin.Context) {
+		panic("handler failed")
+	})
+
+	if w := send(r, "abc", "{}"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 from the recovery middleware, got %d", w.Code)
+	}
+	if len(store.records) != 0 {
+		t.Errorf("the key should be released after a panic, got %d records", len(store.records))
+	}
+}
+
+// failingCompleteStore no puede guardar respuestas
+type failingCompleteStore struct {
+	*memoryStore
+}
+
+func (s failingCompleteStore) Complete(userID uint, key string, statusCode int, body []byte) error {
+	return errors.New("database is down")
+}
+
+func TestIdempotencyReleasesKeyWhenResponseIsNotStored(t *testing.T) {
+	calls := 0
+	store := failingCompleteStore{&memoryStore{records: map[string]*IdempotencyRecord{}}}
+	r := idempotencyRouter(store, &calls, http.StatusCreated)
+
+	send(r, "abc", "{}")
+	if w := send(r, "abc", "{}"); w.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("the retry should run again instead of staying in progress, got %d after %d calls", w.Code, calls)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Estados de un bloqueo temporal de habitación
+const (
+	HoldActive    = "active"
+	HoldConverted = "converted" // Se convirtió en una reserva
+	HoldReleased  = "released"  // El usuario lo liberó
+	HoldExpired   = "expired"   // Lo liberó el barrido de bloqueos vencidos
+)
+
+// RoomHold es un bloqueo temporal de una habitación mientras el usuario confirma la reserva.
+// Mientras está activo la habitación figura como reservada en el inventario.
+type RoomHold struct {
+	ID            uint      `json:"-" gorm:"primary_key"`
+	Token         string    `json:"token" gorm:"size:64;uniqueIndex"`
+	UserID        uint      `json:"userId" gorm:"index"`
+	HotelID       string    `json:"hotelId" gorm:"size:64"`
+	RoomTypeID    uint      `json:"roomTypeId"`
+	Guests        int       `json:"guests"`
+	FechaDesde    time.Time `json:"fechaDesde"`
+	FechaHasta    time.Time `json:"fechaHasta"`
+	Status        string    `json:"status" gorm:"size:20;index:idx_hold_status_expiration"`
+	ExpiresAt     time.Time `json:"expiresAt" gorm:"index:idx_hold_status_expiration"`
+	ReservationID *uint     `json:"reservationId,omitempty"` // Reserva creada a partir del bloqueo
+	CreatedAt     time.Time `json:"createdAt"`
+}
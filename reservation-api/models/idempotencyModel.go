@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey es la respuesta guardada de una solicitud con el header Idempotency-Key.
+// La clave es única por usuario; StatusCode es 0 mientras la solicitud original se procesa.
+type IdempotencyKey struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	UserID       uint      `json:"userId" gorm:"uniqueIndex:idx_user_idempotency_key"`
+	Key          string    `json:"key" gorm:"column:idempotency_key;size:255;uniqueIndex:idx_user_idempotency_key"`
+	RequestHash  string    `json:"requestHash" gorm:"size:64"` // SHA-256 del método, la ruta y el cuerpo
+	StatusCode   int       `json:"statusCode"`
+	ResponseBody []byte    `json:"-" gorm:"type:blob"`
+	ExpiresAt    time.Time `json:"expiresAt" gorm:"index"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
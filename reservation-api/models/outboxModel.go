@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Estados de una entrada del outbox
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+)
+
+// OutboxEntry es un evento pendiente de publicar. Se guarda en la misma transacción
+// que el cambio que lo origina, así no se pierde un evento ni se publica uno que no ocurrió.
+type OutboxEntry struct {
+	ID            uint       `json:"id" gorm:"primary_key"`
+	EventType     string     `json:"eventType" gorm:"size:50"`
+	AggregateID   string     `json:"aggregateId" gorm:"size:64;index:idx_outbox_aggregate"` // Entidad del evento; sus eventos se publican en orden
+	Payload       string     `json:"payload" gorm:"type:text"`                              // Evento serializado en JSON
+	Status        string     `json:"status" gorm:"size:20;index:idx_outbox_pending"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty" gorm:"type:text"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"index:idx_outbox_pending"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+}
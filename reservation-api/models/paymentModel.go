@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Estados de un intento de pago
+const (
+	PaymentCreated           = "created"
+	PaymentPending           = "pending" // Esperando el webhook del proveedor
+	PaymentAuthorized        = "authorized"
+	PaymentCaptured          = "captured"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentRefunded          = "refunded"
+	PaymentVoided            = "voided"
+	PaymentFailed            = "failed"
+)
+
+// PaymentIntent es el pago de una reserva en el proveedor de pagos
+type PaymentIntent struct {
+	ID             uint      `json:"id" gorm:"primary_key"`
+	ReservationID  uint      `json:"reservationId" gorm:"index"`
+	Amount         float64   `json:"amount"`
+	Currency       string    `json:"currency" gorm:"size:3"`
+	Status         string    `json:"status" gorm:"size:20"`
+	Provider       string    `json:"provider" gorm:"size:30"`
+	ProviderRef    string    `json:"providerRef,omitempty" gorm:"size:100;index"`
+	AmountCaptured float64   `json:"amountCaptured"`
+	AmountRefunded float64   `json:"amountRefunded"`
+	FailureReason  string    `json:"failureReason,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// PaymentOperation es una operación hecha sobre un intento de pago, exitosa o no
+type PaymentOperation struct {
+	ID              uint      `json:"id" gorm:"primary_key"`
+	PaymentIntentID uint      `json:"paymentIntentId" gorm:"index"`
+	Operation       string    `json:"operation" gorm:"size:20"` // authorize, capture, void, refund o webhook
+	Amount          float64   `json:"amount"`
+	Result          string    `json:"result" gorm:"size:20"` // Estado devuelto por el proveedor o "error"
+	Error           string    `json:"error,omitempty" gorm:"type:text"`
+	Actor           string    `json:"actor"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Estados de una operación de pago diferida
+const (
+	SettlementPending   = "pending"
+	SettlementCompleted = "completed"
+	SettlementFailed    = "failed" // El pago ya no la admite o se agotaron los intentos
+)
+
+// PaymentSettlement es una operación sobre un pago que se registra junto con el cambio que la
+// origina (por ejemplo, la devolución de una cancelación) y se hace después de confirmarlo,
+// reintentando hasta que el proveedor responda
+type PaymentSettlement struct {
+	ID              uint       `json:"id" gorm:"primary_key"`
+	PaymentIntentID uint       `json:"paymentIntentId" gorm:"index"`
+	ReservationID   uint       `json:"reservationId" gorm:"index"`
+	Operation       string     `json:"operation" gorm:"size:20"` // capture, void o refund
+	Amount          float64    `json:"amount"`
+	Status          string     `json:"status" gorm:"size:20;index:idx_settlement_pending"`
+	Attempts        int        `json:"attempts"`
+	LastError       string     `json:"lastError,omitempty" gorm:"type:text"`
+	NextAttemptAt   time.Time  `json:"nextAttemptAt" gorm:"index:idx_settlement_pending"`
+	Actor           string     `json:"actor"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// RateRule es una tarifa por temporada o por día de la semana de un tipo de habitación
+type RateRule struct {
+	ID         uint       `json:"id" gorm:"primary_key"`
+	RoomTypeID uint       `json:"roomTypeId" gorm:"index"`
+	Name       string     `json:"name"`
+	StartDate  *time.Time `json:"startDate,omitempty" gorm:"type:date"` // Primera noche, inclusive
+	EndDate    *time.Time `json:"endDate,omitempty" gorm:"type:date"`   // Última noche, inclusive
+	DaysOfWeek string     `json:"daysOfWeek,omitempty"`                 // Días separados por coma, 0 = domingo
+	Rate       float64    `json:"rate"`
+	Priority   int        `json:"priority"`
+	CreatedAt  time.Time  `json:"createdAt"`
+
+	// Política de cancelación de la tarifa; sin política se usa la del tipo de habitación
+	CancellationPolicyID *uint `json:"cancellationPolicyId,omitempty"`
+}
+
+// CancellationPolicy es una política de cancelación del hotel que se asigna a sus tarifas
+type CancellationPolicy struct {
+	ID             uint      `json:"id" gorm:"primary_key"`
+	HotelID        string    `json:"hotelId" gorm:"index;size:64"`
+	Name           string    `json:"name"`
+	Refundable     bool      `json:"refundable"`
+	FreeDaysBefore int       `json:"freeDaysBefore"` // Días antes del check-in hasta los que se cancela sin cargo
+	PenaltyPercent float64   `json:"penaltyPercent"` // Porcentaje del total que se cobra después de ese plazo
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// StayDiscount es un descuento por cantidad mínima de noches en un hotel
+type StayDiscount struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	HotelID   string    `json:"hotelId" gorm:"index;size:64"`
+	MinNights int       `json:"minNights"`
+	Percent   float64   `json:"percent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// HotelTax es un impuesto que se cobra en las reservas de un hotel
+type HotelTax struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	HotelID   string    `json:"hotelId" gorm:"index;size:64"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"` // percent, per_night o per_guest_night
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
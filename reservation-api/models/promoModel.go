@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PromoCode es un código promocional que el usuario ingresa al reservar
+type PromoCode struct {
+	ID             uint       `json:"id" gorm:"primary_key"`
+	Code           string     `json:"code" gorm:"uniqueIndex;size:50"` // Se guarda en mayúsculas
+	Kind           string     `json:"kind" gorm:"size:10"`             // percent o fixed
+	Amount         float64    `json:"amount"`
+	ValidFrom      *time.Time `json:"validFrom,omitempty" gorm:"type:date"`  // Primer día en que se puede usar, inclusive
+	ValidUntil     *time.Time `json:"validUntil,omitempty" gorm:"type:date"` // Último día en que se puede usar, inclusive
+	HotelIDs       string     `json:"hotelIds,omitempty"`                    // Hoteles separados por coma; vacío para todos
+	MinNights      int        `json:"minNights"`
+	MaxUses        int        `json:"maxUses"`        // Usos en total; 0 sin límite
+	MaxUsesPerUser int        `json:"maxUsesPerUser"` // Usos por usuario; 0 sin límite
+	UsedCount      int        `json:"usedCount"`
+	Stackable      bool       `json:"stackable"` // Si se suma al descuento por cantidad de noches
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// PromoRedemption es el uso de un código en una reserva
+type PromoRedemption struct {
+	ID            uint       `json:"id" gorm:"primary_key"`
+	PromoCodeID   uint       `json:"promoCodeId" gorm:"index"`
+	UserID        uint       `json:"userId" gorm:"index"`
+	ReservationID uint       `json:"reservationId" gorm:"uniqueIndex"`
+	Discount      float64    `json:"discount"`
+	ReleasedAt    *time.Time `json:"releasedAt,omitempty"` // La reserva se canceló o falló y el uso se devolvió
+	CreatedAt     time.Time  `json:"createdAt"`
+}
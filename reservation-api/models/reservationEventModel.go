@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Versión del esquema de los eventos de reservas publicados en RabbitMQ.
+// Se incrementa solo si se quita o cambia el significado de un campo; agregar campos no la cambia.
+const ReservationEventSchemaVersion = 1
+
+// Tipos de eventos de reservas (se usan también como routing key en el exchange reservation_events)
+const (
+	ReservationCreatedEvent       = "reservation.created"
+	ReservationModifiedEvent      = "reservation.modified"
+	ReservationCancelledEvent     = "reservation.cancelled"
+	ReservationStatusChangedEvent = "reservation.status_changed" // confirmed, checked_in, checked_out, no_show y failed
+)
+
+// ReservationEvent es un cambio sobre una reserva publicado en el exchange reservation_events
+// (tipo topic, durable). Cada mensaje es un objeto JSON con estos campos:
+//
+//	schema_version   int     versión del esquema, hoy 1
+//	event_type       string  reservation.created | reservation.modified | reservation.cancelled | reservation.status_changed
+//	timestamp        string  momento del cambio en RFC 3339, UTC
+//	reservation_id   int     ID de la reserva
+//	user_id          int     usuario dueño de la reserva
+//	hotel_id         string  ID del hotel en hotel-api
+//	room_type_id     int     tipo de habitación reservado
+//	guests           int     cantidad de huéspedes
+//	check_in         string  fecha de entrada, YYYY-MM-DD
+//	check_out        string  fecha de salida, YYYY-MM-DD
+//	status           string  estado de la reserva después del cambio
+//	previous_status  string  estado anterior; se omite en reservation.created y reservation.modified
+//	currency         string  moneda del precio, ISO 4217
+//	total_price      number  precio total con descuentos e impuestos
+//	actor            string  quién hizo el cambio, por ejemplo "user:12"
+//
+// El message_id de AMQP es el ID de la entrada del outbox: los consumidores lo usan para
+// descartar mensajes repetidos, ya que la entrega es al menos una vez.
+type ReservationEvent struct {
+	SchemaVersion  int       `json:"schema_version"`
+	EventType      string    `json:"event_type"`
+	Timestamp      time.Time `json:"timestamp"`
+	ReservationID  uint      `json:"reservation_id"`
+	UserID         uint      `json:"user_id"`
+	HotelID        string    `json:"hotel_id"`
+	RoomTypeID     uint      `json:"room_type_id"`
+	Guests         int       `json:"guests"`
+	CheckIn        string    `json:"check_in"`
+	CheckOut       string    `json:"check_out"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Currency       string    `json:"currency"`
+	TotalPrice     float64   `json:"total_price"`
+	Actor          string    `json:"actor"`
+}
@@ -1,11 +1,56 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Estados de una reserva
+const (
+	StatusPending    = "pending"
+	StatusConfirmed  = "confirmed"
+	StatusCheckedIn  = "checked_in"
+	StatusCheckedOut = "checked_out"
+	StatusCancelled  = "cancelled"
+	StatusNoShow     = "no_show"
+	StatusFailed     = "failed" // El pago fue rechazado
+)
 
 type Reservation struct {
-	ID         uint      `json:"id" gorm:"primary_key"`
-	UserID     uint      `json:"userId"`
-	HotelID    string    `json:"hotelId"`
-	FechaDesde time.Time `json:"fechaDesde"`
-	FechaHasta time.Time `json:"fechaHasta"`
+	ID              uint      `json:"id" gorm:"primary_key"`
+	UserID          uint      `json:"userId"`
+	HotelID         string    `json:"hotelId"`
+	RoomTypeID      uint      `json:"roomTypeId"`
+	Guests          int       `json:"guests"`
+	FechaDesde      time.Time `json:"fechaDesde"`
+	FechaHasta      time.Time `json:"fechaHasta"`
+	Status          string    `json:"status" gorm:"size:20;index;default:pending"`
+	StatusChangedAt time.Time `json:"statusChangedAt"`
+
+	// Precio cotizado al reservar; no cambia aunque después cambien las tarifas
+	Currency       string          `json:"currency" gorm:"size:3"`
+	Subtotal       float64         `json:"subtotal"`
+	DiscountAmount float64         `json:"discountAmount"`
+	TaxAmount      float64         `json:"taxAmount"`
+	TotalPrice     float64         `json:"totalPrice"`
+	PriceBreakdown json.RawMessage `json:"priceBreakdown" gorm:"type:text"`
+
+	// Política de cancelación de la tarifa al reservar (JSON); vacía en reservas anteriores a las políticas
+	CancellationPolicy json.RawMessage `json:"cancellationPolicy,omitempty" gorm:"type:text"`
+
+	// Código promocional aplicado y su parte del descuento (incluida en DiscountAmount)
+	PromoCode     string  `json:"promoCode,omitempty" gorm:"size:50"`
+	PromoDiscount float64 `json:"promoDiscount,omitempty"`
+}
+
+// ReservationStatusChange es una transición de estado de una reserva
+type ReservationStatusChange struct {
+	ID            uint      `json:"id" gorm:"primary_key"`
+	ReservationID uint      `json:"reservationId" gorm:"index"`
+	FromStatus    string    `json:"fromStatus" gorm:"size:20"` // Vacío al crear la reserva
+	ToStatus      string    `json:"toStatus" gorm:"size:20"`
+	Actor         string    `json:"actor"` // Quién hizo el cambio, por ejemplo "user:12"
+	Reason        string    `json:"reason,omitempty"`
+	Details       string    `json:"details,omitempty" gorm:"type:text"` // JSON con los datos anteriores y nuevos si se modificó la reserva
+	CreatedAt     time.Time `json:"createdAt"`
 }
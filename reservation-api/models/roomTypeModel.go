@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// RoomType es un tipo de habitación de un hotel
+type RoomType struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	HotelID   string    `json:"hotelId" gorm:"index;size:64"`
+	Name      string    `json:"name"`
+	Capacity  int       `json:"capacity"` // Huéspedes por habitación
+	Count     int       `json:"count"`    // Cantidad de habitaciones de este tipo
+	BaseRate  float64   `json:"baseRate"` // Tarifa por noche
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Política de cancelación de la tarifa base; sin política se cancela sin cargo hasta el check-in
+	CancellationPolicyID *uint `json:"cancellationPolicyId,omitempty"`
+}
+
+// RoomInventory es la ocupación de un tipo de habitación en una noche.
+// Las filas se crean a medida que se reserva; una noche sin fila tiene todas las habitaciones libres.
+type RoomInventory struct {
+	ID         uint      `json:"id" gorm:"primary_key"`
+	RoomTypeID uint      `json:"roomTypeId" gorm:"uniqueIndex:idx_room_type_night"`
+	Date       time.Time `json:"date" gorm:"type:date;uniqueIndex:idx_room_type_night"`
+	Total      int       `json:"total"`    // Habitaciones reservables esa noche
+	Reserved   int       `json:"reserved"` // Habitaciones ya reservadas esa noche
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Estados de una saga de reserva
+const (
+	SagaRunning            = "running"
+	SagaAwaitingPayment    = "awaiting_payment" // El proveedor responde el pago por webhook
+	SagaCompleted          = "completed"
+	SagaCompensated        = "compensated"         // Falló un paso y se deshicieron los anteriores
+	SagaCompensationFailed = "compensation_failed" // No se pudo deshacer algún paso; el barrido lo reintenta
+)
+
+// Acciones registradas para cada paso
+const (
+	SagaActionExecute    = "execute"
+	SagaActionCompensate = "compensate"
+)
+
+// Pasos de una saga de reserva en orden
+const (
+	SagaStepReserveInventory   = "reserve_inventory"
+	SagaStepAuthorizePayment   = "authorize_payment"
+	SagaStepConfirmReservation = "confirm_reservation"
+)
+
+// BookingSaga es el estado persistido de una reserva con pago: tomar el inventario,
+// autorizar el pago y confirmar. Si un paso falla se compensan los anteriores.
+type BookingSaga struct {
+	ID              uint       `json:"id" gorm:"primary_key"`
+	UserID          uint       `json:"userId" gorm:"index"`
+	HotelID         string     `json:"hotelId" gorm:"size:64"`
+	Status          string     `json:"status" gorm:"size:20;index"`
+	Step            string     `json:"step" gorm:"size:30"` // Último paso alcanzado
+	ReservationID   *uint      `json:"reservationId,omitempty" gorm:"index"`
+	PaymentIntentID *uint      `json:"paymentIntentId,omitempty"`
+	LastError       string     `json:"lastError,omitempty" gorm:"type:text"`
+	Deadline        time.Time  `json:"deadline" gorm:"index"` // Después de esta hora la saga se compensa
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// BookingSagaStep es la ejecución de un paso o de una compensación de la saga
+type BookingSagaStep struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	SagaID    uint      `json:"sagaId" gorm:"index"`
+	Step      string    `json:"step" gorm:"size:30"`
+	Action    string    `json:"action" gorm:"size:20"`
+	Succeeded bool      `json:"succeeded"`
+	Error     string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Versión del esquema de los eventos de la lista de espera
+const WaitlistEventSchemaVersion = 1
+
+// Tipos de eventos de la lista de espera
+const (
+	WaitlistOfferedEvent = "waitlist.offered"
+)
+
+// WaitlistEvent avisa que a un usuario de la lista de espera se le bloqueó una habitación.
+// Se publica en el mismo exchange que los eventos de reservas (ver ReservationEvent).
+type WaitlistEvent struct {
+	SchemaVersion   int       `json:"schema_version"`
+	EventType       string    `json:"event_type"`
+	Timestamp       time.Time `json:"timestamp"`
+	WaitlistEntryID uint      `json:"waitlist_entry_id"`
+	UserID          uint      `json:"user_id"`
+	HotelID         string    `json:"hotel_id"`
+	RoomTypeID      uint      `json:"room_type_id"`
+	CheckIn         string    `json:"check_in"`  // YYYY-MM-DD
+	CheckOut        string    `json:"check_out"` // YYYY-MM-DD
+	HoldToken       string    `json:"hold_token"`
+	HoldExpiresAt   time.Time `json:"hold_expires_at"`
+}
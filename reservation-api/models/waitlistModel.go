@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Estados de una entrada de la lista de espera
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"   // Se le bloqueó una habitación que se liberó
+	WaitlistFulfilled = "fulfilled" // Convirtió el bloqueo ofrecido en una reserva
+	WaitlistExpired   = "expired"   // El bloqueo ofrecido venció o fue liberado sin reservar
+	WaitlistCancelled = "cancelled" // El usuario salió de la lista
+)
+
+// WaitlistEntry es un usuario esperando que se libere una habitación del tipo para el rango de fechas
+type WaitlistEntry struct {
+	ID         uint       `json:"id" gorm:"primary_key"`
+	UserID     uint       `json:"userId" gorm:"index"`
+	HotelID    string     `json:"hotelId" gorm:"size:64"`
+	RoomTypeID uint       `json:"roomTypeId" gorm:"index:idx_waitlist_room_type_status"`
+	Guests     int        `json:"guests"`
+	FechaDesde time.Time  `json:"fechaDesde"`
+	FechaHasta time.Time  `json:"fechaHasta"`
+	Status     string     `json:"status" gorm:"size:20;index:idx_waitlist_room_type_status"`
+	HoldToken  string     `json:"holdToken,omitempty" gorm:"size:64;index"` // Bloqueo ofrecido al usuario
+	OfferedAt  *time.Time `json:"offeredAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
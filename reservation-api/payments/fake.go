@@ -0,0 +1,163 @@
+package payments
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Medios de pago del proveedor falso. Cualquier otro token se autoriza.
+const (
+	FakeTokenApproved    = "tok_approved"
+	FakeTokenDeclined    = "tok_declined"
+	FakeTokenAsync       = "tok_async"       // Queda pendiente hasta que llegue un webhook
+	FakeTokenUnavailable = "tok_unavailable" // Simula que el proveedor no responde
+)
+
+type fakePayment struct {
+	pending    bool // Autorización asíncrona que espera el webhook
+	declined   bool
+	authorized float64
+	captured   float64
+	refunded   float64
+	voided     bool
+}
+
+// FakeGateway es un proveedor en memoria con resultados determinísticos según el medio de pago
+type FakeGateway struct {
+	mu         sync.Mutex
+	payments   map[string]*fakePayment
+	operations map[string]Result // Resultado de cada operación por su referencia
+}
+
+// NewFakeGateway crea un proveedor falso vacío
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{payments: map[string]*fakePayment{}, operations: map[string]Result{}}
+}
+
+// repeated devuelve el resultado de una operación ya hecha con la misma referencia
+func (g *FakeGateway) repeated(reference string) (Result, bool) {
+	if reference == "" {
+		return Result{}, false
+	}
+	result, ok := g.operations[reference]
+	return result, ok
+}
+
+// remember guarda el resultado de la operación para devolverlo si se repite la referencia
+func (g *FakeGateway) remember(reference string, result Result) Result {
+	if reference != "" {
+		g.operations[reference] = result
+	}
+	return result
+}
+
+// Name devuelve el nombre del proveedor
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+// Authorize autoriza el monto según el medio de pago. La misma referencia devuelve siempre el mismo pago.
+func (g *FakeGateway) Authorize(req AuthorizeRequest) (Result, error) {
+	ref := "fake_" + req.Reference
+
+	switch req.PaymentMethod {
+	case FakeTokenUnavailable:
+		return Result{}, ErrGatewayUnavailable
+	case FakeTokenDeclined:
+		return Result{ProviderRef: ref, Status: StatusDeclined, DeclineReason: "card_declined"}, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if req.PaymentMethod == FakeTokenAsync {
+		if _, ok := g.payments[ref]; !ok {
+			g.payments[ref] = &fakePayment{pending: true, authorized: req.Amount}
+		}
+		return Result{ProviderRef: ref, Status: StatusPending}, nil
+	}
+	if _, ok := g.payments[ref]; !ok {
+		g.payments[ref] = &fakePayment{authorized: req.Amount}
+	}
+	return Result{ProviderRef: ref, Status: StatusAuthorized}, nil
+}
+
+// ApplyWebhook resuelve una autorización asíncrona con el resultado que informa el webhook
+func (g *FakeGateway) ApplyWebhook(providerRef, status string, amount float64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[providerRef]
+	if !ok {
+		return fmt.Errorf("payment %s does not exist", providerRef)
+	}
+	if !payment.pending {
+		// Un webhook repetido no cambia nada
+		return nil
+	}
+	payment.pending = false
+
+	switch status {
+	case StatusAuthorized:
+	case StatusCaptured:
+		if amount == 0 {
+			amount = payment.authorized
+		}
+		payment.captured += amount
+	case StatusDeclined:
+		payment.declined = true
+	default:
+		return fmt.Errorf("unexpected webhook status %q", status)
+	}
+	return nil
+}
+
+// Capture cobra hasta el monto autorizado
+func (g *FakeGateway) Capture(providerRef string, amount float64, reference string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if result, ok := g.repeated(reference); ok {
+		return result, nil
+	}
+
+	payment, ok := g.payments[providerRef]
+	if !ok || payment.pending || payment.declined || payment.voided {
+		return Result{}, &RejectedError{Reason: fmt.Sprintf("payment %s is not authorized", providerRef)}
+	}
+	if payment.captured+amount > payment.authorized+0.001 {
+		return Result{}, &RejectedError{Reason: "capture exceeds authorized amount"}
+	}
+	payment.captured += amount
+	return g.remember(reference, Result{ProviderRef: providerRef, Status: StatusCaptured}), nil
+}
+
+// Void anula una autorización que todavía no se cobró
+func (g *FakeGateway) Void(providerRef string, reference string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if result, ok := g.repeated(reference); ok {
+		return result, nil
+	}
+
+	payment, ok := g.payments[providerRef]
+	if !ok || payment.pending || payment.declined || payment.captured > 0 {
+		return Result{}, &RejectedError{Reason: fmt.Sprintf("payment %s cannot be voided", providerRef)}
+	}
+	payment.voided = true
+	return g.remember(reference, Result{ProviderRef: providerRef, Status: StatusVoided}), nil
+}
+
+// Refund devuelve hasta el monto cobrado
+func (g *FakeGateway) Refund(providerRef string, amount float64, reference string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if result, ok := g.repeated(reference); ok {
+		return result, nil
+	}
+
+	payment, ok := g.payments[providerRef]
+	if !ok || payment.refunded+amount > payment.captured+0.001 {
+		return Result{}, &RejectedError{Reason: "refund exceeds captured amount"}
+	}
+	payment.refunded += amount
+	return g.remember(reference, Result{ProviderRef: providerRef, Status: StatusRefunded}), nil
+}
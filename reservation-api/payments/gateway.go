@@ -0,0 +1,83 @@
+// Package payments define la interfaz con los proveedores de pago y un proveedor falso
+// determinístico para desarrollo y tests. No accede a la base de datos.
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Estados de una operación en el proveedor
+const (
+	StatusPending    = "pending" // El resultado llega después por webhook
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusVoided     = "voided"
+	StatusRefunded   = "refunded"
+	StatusDeclined   = "declined"
+)
+
+// ErrGatewayUnavailable indica que no se pudo hablar con el proveedor; la operación se puede reintentar
+var ErrGatewayUnavailable = errors.New("payment gateway unavailable")
+
+// RejectedError indica que el proveedor respondió y rechazó la operación (por ejemplo, un monto
+// mayor al cobrado o un pago que no existe). Es definitivo: reintentar da el mismo resultado.
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return "payment operation rejected: " + e.Reason
+}
+
+// AuthorizeRequest es una autorización de pago
+type AuthorizeRequest struct {
+	Reference     string // Identifica el pago en nuestro sistema; el proveedor no autoriza dos veces la misma referencia
+	Amount        float64
+	Currency      string
+	PaymentMethod string // Token del medio de pago generado por el proveedor en el navegador
+}
+
+// Result es la respuesta del proveedor a una operación
+type Result struct {
+	ProviderRef   string
+	Status        string
+	DeclineReason string
+}
+
+// Gateway es un proveedor de pagos. reference identifica la operación en nuestro sistema: el
+// proveedor no repite una operación con la misma referencia y devuelve el resultado anterior,
+// así se puede reintentar sin cobrar o devolver dos veces. Vacía, la operación no se deduplica.
+// Si el proveedor rechaza la operación el error es *RejectedError; cualquier otro error es de
+// comunicación o de tiempo de espera y la operación se puede reintentar.
+type Gateway interface {
+	Name() string
+	Authorize(req AuthorizeRequest) (Result, error)
+	Capture(providerRef string, amount float64, reference string) (Result, error)
+	Void(providerRef string, reference string) (Result, error)
+	Refund(providerRef string, amount float64, reference string) (Result, error)
+}
+
+// WebhookListener lo implementan los proveedores que tienen que enterarse del resultado de una
+// operación asíncrona cuando llega el webhook, como el proveedor falso que los simula.
+// status es StatusAuthorized, StatusCaptured o StatusDeclined.
+type WebhookListener interface {
+	ApplyWebhook(providerRef, status string, amount float64) error
+}
+
+// Sign firma el cuerpo de un webhook con HMAC-SHA256
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature indica si la firma del webhook corresponde al cuerpo
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,115 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFakeGatewayAuthorize(t *testing.T) {
+	gateway := NewFakeGateway()
+
+	cases := map[string]string{
+		FakeTokenApproved: StatusAuthorized,
+		FakeTokenDeclined: StatusDeclined,
+		FakeTokenAsync:    StatusPending,
+	}
+	for token, expected := range cases {
+		result, err := gateway.Authorize(AuthorizeRequest{Reference: token, Amount: 100, Currency: "USD", PaymentMethod: token})
+		if err != nil || result.Status != expected || result.ProviderRef != "fake_"+token {
+			t.Errorf("%s: expected %s, got %+v %v", token, expected, result, err)
+		}
+	}
+
+	if _, err := gateway.Authorize(AuthorizeRequest{Reference: "x", PaymentMethod: FakeTokenUnavailable}); !errors.Is(err, ErrGatewayUnavailable) {
+		t.Errorf("expected gateway unavailable, got %v", err)
+	}
+}
+
+func TestFakeGatewayCaptureAndRefund(t *testing.T) {
+	gateway := NewFakeGateway()
+	result, _ := gateway.Authorize(AuthorizeRequest{Reference: "1", Amount: 100, PaymentMethod: FakeTokenApproved})
+
+	var rejected *RejectedError
+	if _, err := gateway.Refund(result.ProviderRef, 10, ""); !errors.As(err, &rejected) {
+		t.Errorf("refund before capture should be rejected, got %v", err)
+	}
+	if _, err := gateway.Capture(result.ProviderRef, 150, ""); !errors.As(err, &rejected) {
+		t.Errorf("capture above the authorized amount should be rejected, got %v", err)
+	}
+	if _, err := gateway.Capture(result.ProviderRef, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gateway.Void(result.ProviderRef, ""); err == nil {
+		t.Error("void after capture should fail")
+	}
+	if _, err := gateway.Refund(result.ProviderRef, 60, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gateway.Refund(result.ProviderRef, 60, ""); err == nil {
+		t.Error("refunds above the captured amount should fail")
+	}
+}
+
+func TestFakeGatewayRepeatedReference(t *testing.T) {
+	gateway := NewFakeGateway()
+	result, _ := gateway.Authorize(AuthorizeRequest{Reference: "1", Amount: 100, PaymentMethod: FakeTokenApproved})
+
+	// Reintentar el mismo cobro no cobra dos veces
+	for i := 0; i < 2; i++ {
+		if _, err := gateway.Capture(result.ProviderRef, 80, "ps_1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gateway.Capture(result.ProviderRef, 20, "ps_2"); err != nil {
+		t.Errorf("the rest of the authorization should still be capturable: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := gateway.Refund(result.ProviderRef, 60, "ps_3"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gateway.Refund(result.ProviderRef, 40, "ps_4"); err != nil {
+		t.Errorf("a repeated refund should not count twice: %v", err)
+	}
+}
+
+func TestFakeGatewayAsyncAuthorize(t *testing.T) {
+	gateway := NewFakeGateway()
+	result, _ := gateway.Authorize(AuthorizeRequest{Reference: "1", Amount: 100, PaymentMethod: FakeTokenAsync})
+
+	if _, err := gateway.Capture(result.ProviderRef, 100, ""); err == nil {
+		t.Error("capture before the webhook should fail")
+	}
+	if err := gateway.ApplyWebhook(result.ProviderRef, StatusAuthorized, 0); err != nil {
+		t.Fatal(err)
+	}
+	// El webhook repetido no cambia el pago
+	if err := gateway.ApplyWebhook(result.ProviderRef, StatusDeclined, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gateway.Capture(result.ProviderRef, 100, ""); err != nil {
+		t.Fatalf("capture after the webhook failed: %v", err)
+	}
+
+	declined, _ := gateway.Authorize(AuthorizeRequest{Reference: "2", Amount: 100, PaymentMethod: FakeTokenAsync})
+	gateway.ApplyWebhook(declined.ProviderRef, StatusDeclined, 0)
+	if _, err := gateway.Capture(declined.ProviderRef, 100, ""); err == nil {
+		t.Error("capture of a declined payment should fail")
+	}
+	if err := gateway.ApplyWebhook("fake_unknown", StatusAuthorized, 0); err == nil {
+		t.Error("webhook for an unknown payment should fail")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"event":"payment.authorized"}`)
+	signature := Sign("secret", body)
+
+	if !VerifySignature("secret", body, signature) {
+		t.Error("valid signature rejected")
+	}
+	if VerifySignature("other", body, signature) || VerifySignature("secret", []byte("{}"), signature) || VerifySignature("", body, Sign("", body)) {
+		t.Error("invalid signature accepted")
+	}
+}
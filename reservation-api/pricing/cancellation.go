@@ -0,0 +1,48 @@
+package pricing
+
+import "time"
+
+// CancellationPolicy son las condiciones de cancelación de una tarifa
+type CancellationPolicy struct {
+	Name           string  `json:"name"`
+	Refundable     bool    `json:"refundable"`       // false: no se devuelve nada al cancelar
+	FreeDaysBefore int     `json:"free_days_before"` // Días antes del check-in hasta los que se cancela sin cargo
+	PenaltyPercent float64 `json:"penalty_percent"`  // Porcentaje del total que se cobra después de ese plazo
+}
+
+// DefaultCancellationPolicy es la política de las tarifas que no tienen una: sin cargo hasta el check-in
+var DefaultCancellationPolicy = CancellationPolicy{Name: "flexible", Refundable: true, PenaltyPercent: 100}
+
+// Stricter indica si la política p es más restrictiva que other: una no reembolsable es la
+// más restrictiva, y entre reembolsables lo es la que exige cancelar antes o cobra más.
+func (p CancellationPolicy) Stricter(other CancellationPolicy) bool {
+	if p.Refundable != other.Refundable {
+		return !p.Refundable
+	}
+	if p.FreeDaysBefore != other.FreeDaysBefore {
+		return p.FreeDaysBefore > other.FreeDaysBefore
+	}
+	return p.PenaltyPercent > other.PenaltyPercent
+}
+
+// FreeUntil devuelve hasta cuándo se cancela sin cargo una estadía que empieza en checkIn,
+// o nil si la tarifa no es reembolsable
+func (p CancellationPolicy) FreeUntil(checkIn time.Time) *time.Time {
+	if !p.Refundable {
+		return nil
+	}
+	deadline := checkIn.AddDate(0, 0, -p.FreeDaysBefore)
+	return &deadline
+}
+
+// Penalty devuelve cuánto del total se cobra si la estadía que empieza en checkIn se cancela en now
+func (p CancellationPolicy) Penalty(total float64, checkIn, now time.Time) float64 {
+	freeUntil := p.FreeUntil(checkIn)
+	switch {
+	case freeUntil == nil:
+		return Round(total)
+	case now.Before(*freeUntil):
+		return 0
+	}
+	return Round(total * p.PenaltyPercent / 100)
+}
@@ -0,0 +1,215 @@
+// Package pricing calcula el precio de una estadía a partir de la tarifa base del tipo
+// de habitación, las tarifas por temporada y día de la semana, los descuentos por
+// cantidad de noches, los códigos promocionales y los impuestos del hotel. No accede
+// a la base de datos.
+package pricing
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Tipos de impuesto
+const (
+	TaxPercent       = "percent"         // porcentaje sobre el subtotal con descuento
+	TaxPerNight      = "per_night"       // monto fijo por noche
+	TaxPerGuestNight = "per_guest_night" // monto fijo por huésped y por noche (tasa municipal)
+)
+
+// Rule es una tarifa que reemplaza a la tarifa base en las noches que cumplen sus condiciones
+type Rule struct {
+	Name      string
+	StartDate *time.Time     // Primera noche (inclusive); nil para no limitar
+	EndDate   *time.Time     // Última noche (inclusive); nil para no limitar
+	Days      []time.Weekday // Días de la semana; vacío para todos
+	Rate      float64
+	Priority  int                 // Si varias tarifas aplican a la misma noche gana la de mayor prioridad
+	Policy    *CancellationPolicy // Política de cancelación de la tarifa; nil usa la de la tarifa base
+}
+
+// Discount es un descuento por cantidad mínima de noches
+type Discount struct {
+	MinNights int
+	Percent   float64
+}
+
+// Tax es un impuesto del hotel
+type Tax struct {
+	Name   string
+	Kind   string
+	Amount float64 // Porcentaje o monto según el tipo
+}
+
+// Input son los datos necesarios para cotizar una estadía
+type Input struct {
+	BaseRate   float64
+	BasePolicy *CancellationPolicy // Política de la tarifa base; nil usa DefaultCancellationPolicy
+	Rules      []Rule
+	Discounts  []Discount
+	Promo      *Promo // Código promocional ya validado; opcional
+	Taxes      []Tax
+	Nights     []time.Time // Noches de la estadía a medianoche UTC
+	Guests     int
+	Currency   string
+}
+
+// Night es el precio de una noche
+type Night struct {
+	Date string  `json:"date"`
+	Rate float64 `json:"rate"`
+	Rule string  `json:"rule"` // Nombre de la tarifa aplicada o "base"
+}
+
+// TaxLine es el monto de un impuesto
+type TaxLine struct {
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"`
+	Amount float64 `json:"amount"`
+}
+
+// Quote es el precio detallado de una estadía
+type Quote struct {
+	Currency        string    `json:"currency"`
+	Nights          []Night   `json:"nights"`
+	Subtotal        float64   `json:"subtotal"`
+	DiscountPercent float64   `json:"discount_percent"` // Descuento por noches aplicado
+	Discount        float64   `json:"discount"`         // Descuento total: por noches más el del código
+	PromoCode       string    `json:"promo_code,omitempty"`
+	PromoDiscount   float64   `json:"promo_discount,omitempty"`
+	Taxes           []TaxLine `json:"taxes"`
+	TaxTotal        float64   `json:"tax_total"`
+	Total           float64   `json:"total"`
+
+	// La política más restrictiva entre las tarifas de las noches
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy"`
+}
+
+// Round redondea un monto a centavos
+func Round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// applies indica si la tarifa aplica a la noche
+func (r Rule) applies(night time.Time) bool {
+	if r.StartDate != nil && night.Before(*r.StartDate) {
+		return false
+	}
+	if r.EndDate != nil && night.After(*r.EndDate) {
+		return false
+	}
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, day := range r.Days {
+		if night.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// nightRate devuelve la tarifa de la noche, la de mayor prioridad entre las que aplican o la base,
+// y su política de cancelación
+func nightRate(in Input, rules []Rule, night time.Time) (Night, CancellationPolicy) {
+	basePolicy := DefaultCancellationPolicy
+	if in.BasePolicy != nil {
+		basePolicy = *in.BasePolicy
+	}
+
+	for _, rule := range rules {
+		if rule.applies(night) {
+			policy := basePolicy
+			if rule.Policy != nil {
+				policy = *rule.Policy
+			}
+			return Night{Date: night.Format("2006-01-02"), Rate: Round(rule.Rate), Rule: rule.Name}, policy
+		}
+	}
+	return Night{Date: night.Format("2006-01-02"), Rate: Round(in.BaseRate), Rule: "base"}, basePolicy
+}
+
+// discountPercent devuelve el descuento del escalón más alto alcanzado por la estadía
+func discountPercent(discounts []Discount, nights int) float64 {
+	best, bestNights := 0.0, 0
+	for _, discount := range discounts {
+		if nights >= discount.MinNights && discount.MinNights >= bestNights {
+			best, bestNights = discount.Percent, discount.MinNights
+		}
+	}
+	return best
+}
+
+// applyPromo aplica el código sobre el subtotal con el descuento por noches. Un código que no
+// se acumula se calcula sobre el subtotal y solo reemplaza al descuento por noches si es mayor;
+// si no lo es la cotización queda sin código.
+func applyPromo(quote *Quote, promo Promo) {
+	if promo.Stackable {
+		quote.PromoDiscount = promo.discount(quote.Subtotal - quote.Discount)
+		quote.Discount = Round(quote.Discount + quote.PromoDiscount)
+	} else {
+		promoDiscount := promo.discount(quote.Subtotal)
+		if promoDiscount <= quote.Discount {
+			return
+		}
+		quote.DiscountPercent = 0
+		quote.PromoDiscount = promoDiscount
+		quote.Discount = promoDiscount
+	}
+	quote.PromoCode = promo.Code
+}
+
+// Calculate cotiza la estadía
+func Calculate(in Input) Quote {
+	// Ordenar por prioridad una sola vez; ante empate gana la definida primero
+	rules := append([]Rule(nil), in.Rules...)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority > rules[j].Priority })
+
+	quote := Quote{Currency: in.Currency, Nights: []Night{}, Taxes: []TaxLine{}}
+	for i, night := range in.Nights {
+		price, policy := nightRate(in, rules, night)
+		quote.Nights = append(quote.Nights, price)
+		quote.Subtotal += price.Rate
+		if i == 0 || policy.Stricter(quote.CancellationPolicy) {
+			quote.CancellationPolicy = policy
+		}
+	}
+	quote.Subtotal = Round(quote.Subtotal)
+
+	quote.DiscountPercent = discountPercent(in.Discounts, len(in.Nights))
+	quote.Discount = Round(quote.Subtotal * quote.DiscountPercent / 100)
+	if in.Promo != nil {
+		applyPromo(&quote, *in.Promo)
+	}
+	taxable := quote.Subtotal - quote.Discount
+
+	guests := in.Guests
+	if guests < 1 {
+		guests = 1
+	}
+	for _, tax := range in.Taxes {
+		var amount float64
+		switch tax.Kind {
+		case TaxPercent:
+			amount = taxable * tax.Amount / 100
+		case TaxPerNight:
+			amount = tax.Amount * float64(len(in.Nights))
+		case TaxPerGuestNight:
+			amount = tax.Amount * float64(guests*len(in.Nights))
+		default:
+			continue
+		}
+		line := TaxLine{Name: tax.Name, Kind: tax.Kind, Amount: Round(amount)}
+		quote.Taxes = append(quote.Taxes, line)
+		quote.TaxTotal += line.Amount
+	}
+	quote.TaxTotal = Round(quote.TaxTotal)
+
+	quote.Total = Round(taxable + quote.TaxTotal)
+	return quote
+}
+
+// IsValidTaxKind indica si el tipo de impuesto existe
+func IsValidTaxKind(kind string) bool {
+	return kind == TaxPercent || kind == TaxPerNight || kind == TaxPerGuestNight
+}
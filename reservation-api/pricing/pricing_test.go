@@ -0,0 +1,189 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func date(day int) time.Time {
+	return time.Date(2030, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+func stay(from, to int) []time.Time {
+	var nights []time.Time
+	for day := from; day < to; day++ {
+		nights = append(nights, date(day))
+	}
+	return nights
+}
+
+func TestCalculateAppliesRulesByPriority(t *testing.T) {
+	seasonStart, seasonEnd := date(3), date(4)
+	quote := Calculate(Input{
+		BaseRate: 100,
+		Rules: []Rule{
+			// 4 y 5 de enero de 2030 son viernes y sábado
+			{Name: "weekend", Days: []time.Weekday{time.Friday, time.Saturday}, Rate: 130, Priority: 1},
+			{Name: "high season", StartDate: &seasonStart, EndDate: &seasonEnd, Rate: 150, Priority: 2},
+		},
+		Nights:   stay(2, 6),
+		Currency: "USD",
+	})
+
+	expected := []struct {
+		rate float64
+		rule string
+	}{{100, "base"}, {150, "high season"}, {150, "high season"}, {130, "weekend"}}
+	if len(quote.Nights) != len(expected) {
+		t.Fatalf("expected %d nights, got %v", len(expected), quote.Nights)
+	}
+	for i, night := range quote.Nights {
+		if night.Rate != expected[i].rate || night.Rule != expected[i].rule {
+			t.Errorf("night %s: expected %v %s, got %v %s", night.Date, expected[i].rate, expected[i].rule, night.Rate, night.Rule)
+		}
+	}
+	if quote.Subtotal != 530 || quote.Total != 530 || quote.Currency != "USD" {
+		t.Errorf("unexpected totals: %+v", quote)
+	}
+}
+
+func TestCalculateDiscountsAndTaxes(t *testing.T) {
+	quote := Calculate(Input{
+		BaseRate:  100,
+		Discounts: []Discount{{MinNights: 3, Percent: 5}, {MinNights: 7, Percent: 10}, {MinNights: 14, Percent: 20}},
+		Taxes: []Tax{
+			{Name: "IVA", Kind: TaxPercent, Amount: 21},
+			{Name: "Tasa municipal", Kind: TaxPerGuestNight, Amount: 1.5},
+			{Name: "Limpieza", Kind: TaxPerNight, Amount: 2},
+		},
+		Nights: stay(1, 8),
+		Guests: 2,
+	})
+
+	if quote.Subtotal != 700 || quote.DiscountPercent != 10 || quote.Discount != 70 {
+		t.Fatalf("unexpected discount: %+v", quote)
+	}
+	// 21% de 630 + 1.5 * 2 huéspedes * 7 noches + 2 * 7 noches
+	if quote.TaxTotal != 132.3+21+14 {
+		t.Errorf("unexpected taxes: %v", quote.Taxes)
+	}
+	if quote.Total != Round(630+132.3+21+14) {
+		t.Errorf("unexpected total: %v", quote.Total)
+	}
+}
+
+func TestCalculateWithoutDiscount(t *testing.T) {
+	quote := Calculate(Input{
+		BaseRate:  99.99,
+		Discounts: []Discount{{MinNights: 3, Percent: 5}},
+		Nights:    stay(1, 3),
+	})
+	if quote.DiscountPercent != 0 || quote.Total != 199.98 {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+}
+
+func TestCalculateUsesStrictestCancellationPolicy(t *testing.T) {
+	moderate := CancellationPolicy{Name: "moderate", Refundable: true, FreeDaysBefore: 7, PenaltyPercent: 50}
+	nonRefundable := CancellationPolicy{Name: "non refundable"}
+	quote := Calculate(Input{
+		BaseRate:   100,
+		BasePolicy: &moderate,
+		Rules:      []Rule{{Name: "promo", Days: []time.Weekday{time.Saturday}, Rate: 80, Policy: &nonRefundable}},
+		Nights:     stay(2, 4), // 2 y 3 de enero de 2030 son miércoles y jueves
+	})
+	if quote.CancellationPolicy != moderate {
+		t.Errorf("expected base policy, got %+v", quote.CancellationPolicy)
+	}
+
+	quote = Calculate(Input{
+		BaseRate:   100,
+		BasePolicy: &moderate,
+		Rules:      []Rule{{Name: "promo", Days: []time.Weekday{time.Saturday}, Rate: 80, Policy: &nonRefundable}},
+		Nights:     stay(4, 7),
+	})
+	if quote.CancellationPolicy != nonRefundable {
+		t.Errorf("expected the promo policy, got %+v", quote.CancellationPolicy)
+	}
+
+	if quote := Calculate(Input{BaseRate: 100, Nights: stay(2, 3)}); quote.CancellationPolicy != DefaultCancellationPolicy {
+		t.Errorf("expected the default policy, got %+v", quote.CancellationPolicy)
+	}
+}
+
+func TestCancellationPenalty(t *testing.T) {
+	checkIn := date(20)
+	moderate := CancellationPolicy{Refundable: true, FreeDaysBefore: 7, PenaltyPercent: 25}
+
+	cases := []struct {
+		name     string
+		policy   CancellationPolicy
+		now      time.Time
+		expected float64
+	}{
+		{"before the free period ends", moderate, date(12), 0},
+		{"after the free period", moderate, date(13), 50},
+		{"default policy before check-in", DefaultCancellationPolicy, date(19), 0},
+		{"default policy on check-in day", DefaultCancellationPolicy, date(20), 200},
+		{"non refundable", CancellationPolicy{}, date(1), 200},
+	}
+	for _, tc := range cases {
+		if got := tc.policy.Penalty(200, checkIn, tc.now); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestCalculateStacksPromo(t *testing.T) {
+	quote := Calculate(Input{
+		BaseRate:  100,
+		Discounts: []Discount{{MinNights: 3, Percent: 10}},
+		Promo:     &Promo{Code: "SUMMER", Kind: PromoPercent, Amount: 10, Stackable: true},
+		Taxes:     []Tax{{Name: "IVA", Kind: TaxPercent, Amount: 10}},
+		Nights:    stay(1, 5),
+	})
+
+	// 10% de 400 por noches y 10% de los 360 restantes por el código
+	if quote.DiscountPercent != 10 || quote.PromoCode != "SUMMER" || quote.PromoDiscount != 36 || quote.Discount != 76 {
+		t.Fatalf("unexpected discount: %+v", quote)
+	}
+	if quote.TaxTotal != 32.4 || quote.Total != 356.4 {
+		t.Errorf("unexpected total: %+v", quote)
+	}
+}
+
+func TestCalculateNonStackablePromoKeepsTheBestDiscount(t *testing.T) {
+	discounts := []Discount{{MinNights: 3, Percent: 10}}
+
+	quote := Calculate(Input{
+		BaseRate:  100,
+		Discounts: discounts,
+		Promo:     &Promo{Code: "WELCOME", Kind: PromoFixed, Amount: 60},
+		Nights:    stay(1, 5),
+	})
+	if quote.PromoCode != "WELCOME" || quote.DiscountPercent != 0 || quote.Discount != 60 || quote.Total != 340 {
+		t.Errorf("expected the promo to replace the stay discount, got %+v", quote)
+	}
+
+	quote = Calculate(Input{
+		BaseRate:  100,
+		Discounts: discounts,
+		Promo:     &Promo{Code: "WELCOME", Kind: PromoFixed, Amount: 30},
+		Nights:    stay(1, 5),
+	})
+	if quote.PromoCode != "" || quote.PromoDiscount != 0 || quote.Discount != 40 {
+		t.Errorf("expected the stay discount to be kept, got %+v", quote)
+	}
+}
+
+func TestCalculateFixedPromoDoesNotExceedSubtotal(t *testing.T) {
+	quote := Calculate(Input{
+		BaseRate: 50,
+		Promo:    &Promo{Code: "FREE", Kind: PromoFixed, Amount: 500, Stackable: true},
+		Taxes:    []Tax{{Name: "Limpieza", Kind: TaxPerNight, Amount: 5}},
+		Nights:   stay(1, 3),
+	})
+	if quote.PromoDiscount != 100 || quote.Total != 10 {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+}
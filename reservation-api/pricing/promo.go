@@ -0,0 +1,38 @@
+package pricing
+
+// Tipos de código promocional
+const (
+	PromoPercent = "percent" // porcentaje sobre el subtotal
+	PromoFixed   = "fixed"   // monto fijo por estadía
+)
+
+// Promo es un código promocional ya validado que se aplica a la cotización
+type Promo struct {
+	Code      string
+	Kind      string
+	Amount    float64 // Porcentaje o monto según el tipo
+	Stackable bool    // true: se suma al descuento por noches; false: lo reemplaza si es mayor
+}
+
+// discount devuelve el descuento del código sobre base, sin superar base
+func (p Promo) discount(base float64) float64 {
+	var amount float64
+	switch p.Kind {
+	case PromoPercent:
+		amount = base * p.Amount / 100
+	case PromoFixed:
+		amount = p.Amount
+	}
+	if amount > base {
+		amount = base
+	}
+	if amount < 0 {
+		amount = 0
+	}
+	return Round(amount)
+}
+
+// IsValidPromoKind indica si el tipo de código promocional existe
+func IsValidPromoKind(kind string) bool {
+	return kind == PromoPercent || kind == PromoFixed
+}
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"reservation-api/controllers"
+	"reservation-api/middleware"
+	"reservation-api/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,16 +12,108 @@ import (
 func SetupReservationRoutes(r *gin.Engine) {
 	reservationGroup := r.Group("/reservations")
 	{
-		// Ruta para crear una nueva reserva
-		reservationGroup.POST("/create", controllers.CreateReservation)
+		// Rutas públicas: las usan search-api y el buscador sin sesión
+		reservationGroup.GET("/availability/:hotelID", controllers.GetHotelAvailability)
+		reservationGroup.GET("/hotels/:hotelID/room-types", controllers.GetRoomTypesByHotel)
 
-		// Ruta para obtener todas las reservas
-		reservationGroup.GET("/all", controllers.GetAllReservations)
+		// Ruta para cotizar una estadía con el detalle por noche
+		reservationGroup.GET("/hotels/:hotelID/quote", controllers.GetQuote)
+
+		// Notificaciones del proveedor de pagos; se autentican con la firma del cuerpo
+		reservationGroup.POST("/payments/webhook", controllers.PaymentWebhook)
+	}
+
+	// Guarda las respuestas de las solicitudes con Idempotency-Key
+	idempotency := middleware.Idempotency(services.IdempotencyStore{}, services.IdempotencyTTL())
+
+	// Rutas que requieren un usuario autenticado; cada usuario solo accede a sus reservas
+	auth := r.Group("/reservations")
+	auth.Use(middleware.RequireAuth)
+	{
+		// Ruta para crear una nueva reserva; acepta Idempotency-Key para que un reintento no la duplique
+		auth.POST("/create", idempotency, controllers.CreateReservation)
+
+		// Ruta para reservar y pagar en un solo paso; si el pago falla se libera la habitación
+		auth.POST("/book", idempotency, controllers.BookReservation)
+
+		// Rutas para bloquear una habitación durante la confirmación; el token se pasa como holdToken al crear la reserva
+		auth.POST("/holds", controllers.CreateHold)
+		auth.GET("/holds/:token", controllers.GetHold)
+		auth.DELETE("/holds/:token", controllers.ReleaseHold)
+
+		// Rutas de la lista de espera para fechas sin disponibilidad; al liberarse una habitación
+		// se le ofrece al primero de la lista como un bloqueo
+		auth.POST("/waitlist", controllers.JoinWaitlist)
+		auth.GET("/waitlist", controllers.GetWaitlist)
+		auth.DELETE("/waitlist/:entryID", controllers.LeaveWaitlist)
 
 		// Ruta para obtener las reservas de un usuario por su ID
-		reservationGroup.GET("/user/:userID", controllers.GetReservationsByUser)
+		auth.GET("/user/:userID", controllers.GetReservationsByUser)
+
+		// Ruta para obtener una reserva por su ID
+		auth.GET("/:reservationID", controllers.GetReservation)
+
+		// Ruta para cambiar las fechas, el tipo de habitación o los huéspedes de una reserva
+		auth.PUT("/:reservationID", controllers.ModifyReservation)
+
+		// Ruta para ver cuánto se cobra y se devuelve si se cancela ahora según la política de la tarifa
+		auth.GET("/:reservationID/cancellation", controllers.PreviewCancellation)
+
+		// Ruta para cancelar una reserva (se conserva por compatibilidad, equivale a POST /:reservationID/cancel).
+		// También acepta Idempotency-Key.
+		auth.DELETE("/cancel/:reservationID", idempotency, controllers.CancelReservation)
+		auth.POST("/:reservationID/cancel", idempotency, controllers.CancelReservation)
+
+		// Ruta para obtener el historial de estados de una reserva
+		auth.GET("/:reservationID/history", controllers.GetReservationHistory)
+
+		// Rutas para pagar una reserva pendiente; al autorizarse el pago la reserva queda confirmada
+		auth.POST("/:reservationID/payments", controllers.CreatePaymentIntent)
+		auth.GET("/:reservationID/payments", controllers.GetReservationPayments)
+		auth.GET("/payments/:paymentID", controllers.GetPayment)
+		auth.POST("/payments/:paymentID/authorize", controllers.AuthorizePayment)
+	}
+
+	// Rutas restringidas a administradores
+	admin := auth.Group("")
+	admin.Use(middleware.RequireAdmin)
+	{
+		// Ruta para obtener todas las reservas
+		admin.GET("/all", controllers.GetAllReservations)
+
+		// Cambios de estado que solo hace el hotel
+		admin.POST("/:reservationID/confirm", controllers.ConfirmReservation)
+		admin.POST("/:reservationID/check-in", controllers.CheckInReservation)
+		admin.POST("/:reservationID/check-out", controllers.CheckOutReservation)
+		admin.POST("/:reservationID/no-show", controllers.NoShowReservation)
+
+		// Operaciones del hotel sobre los pagos
+		admin.POST("/payments/:paymentID/capture", controllers.CapturePayment)
+		admin.POST("/payments/:paymentID/void", controllers.VoidPayment)
+		admin.POST("/payments/:paymentID/refund", controllers.RefundPayment)
+
+		// Rutas para seguir las sagas de reserva en curso, compensadas o con compensaciones fallidas
+		admin.GET("/sagas", controllers.GetBookingSagas)
+		admin.GET("/sagas/:sagaID", controllers.GetBookingSaga)
+
+		// Rutas para administrar los tipos de habitación de cada hotel
+		admin.POST("/hotels/:hotelID/room-types", controllers.CreateRoomType)
+		admin.PUT("/room-types/:roomTypeID", controllers.UpdateRoomType)
+
+		// Rutas para administrar tarifas, políticas de cancelación, descuentos e impuestos
+		admin.POST("/room-types/:roomTypeID/rates", controllers.CreateRateRule)
+		admin.GET("/room-types/:roomTypeID/rates", controllers.GetRateRules)
+		admin.POST("/hotels/:hotelID/discounts", controllers.CreateStayDiscount)
+		admin.GET("/hotels/:hotelID/discounts", controllers.GetStayDiscounts)
+		admin.POST("/hotels/:hotelID/cancellation-policies", controllers.CreateCancellationPolicy)
+		admin.GET("/hotels/:hotelID/cancellation-policies", controllers.GetCancellationPolicies)
+		admin.POST("/hotels/:hotelID/taxes", controllers.CreateHotelTax)
+		admin.GET("/hotels/:hotelID/taxes", controllers.GetHotelTaxes)
+		admin.DELETE("/pricing/:kind/:id", controllers.DeletePricingRule)
 
-		// Ruta para cancelar una reserva
-		reservationGroup.DELETE("/cancel/:reservationID", controllers.CancelReservation)
+		// Rutas para administrar los códigos promocionales que se ingresan al reservar
+		admin.POST("/promo-codes", controllers.CreatePromoCode)
+		admin.GET("/promo-codes", controllers.GetPromoCodes)
+		admin.POST("/promo-codes/:promoID/deactivate", controllers.DeactivatePromoCode)
 	}
 }
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"reservation-api/pricing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reservationPolicy devuelve la política de cancelación guardada al reservar. Las reservas
+// anteriores a las políticas usan la política por defecto.
+func reservationPolicy(reservation *models.Reservation) pricing.CancellationPolicy {
+	policy := pricing.DefaultCancellationPolicy
+	if len(reservation.CancellationPolicy) > 0 {
+		if err := json.Unmarshal(reservation.CancellationPolicy, &policy); err != nil {
+			return pricing.DefaultCancellationPolicy
+		}
+	}
+	return policy
+}
+
+// previewCancellation calcula el cargo y la devolución si la reserva se cancela en now,
+// a partir de los pagos de la reserva
+func previewCancellation(reservation *models.Reservation, intents []models.PaymentIntent, now time.Time) dto.CancellationPreviewDTO {
+	policy := reservationPolicy(reservation)
+
+	paid := 0.0
+	for _, intent := range intents {
+		if intent.Status == models.PaymentCaptured || intent.Status == models.PaymentPartiallyRefunded {
+			paid += intent.AmountCaptured - intent.AmountRefunded
+		}
+	}
+	paid = pricing.Round(paid)
+
+	penalty := policy.Penalty(reservation.TotalPrice, reservation.FechaDesde, now)
+	refund := 0.0
+	if paid > penalty {
+		refund = pricing.Round(paid - penalty)
+	}
+
+	return dto.CancellationPreviewDTO{
+		ReservationID: reservation.ID,
+		Policy:        policy,
+		FreeUntil:     policy.FreeUntil(reservation.FechaDesde),
+		Currency:      reservation.Currency,
+		TotalPrice:    reservation.TotalPrice,
+		Paid:          paid,
+		Penalty:       penalty,
+		Refund:        refund,
+	}
+}
+
+// PreviewCancellation calcula cuánto se cobraría y se devolvería si la reserva se cancela ahora
+func PreviewCancellation(reservationID uint, now time.Time) (*dto.CancellationPreviewDTO, error) {
+	reservation, err := GetReservation(reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanTransition(reservation.Status, models.StatusCancelled) {
+		return nil, ErrIllegalTransition
+	}
+
+	intents, err := GetPaymentIntents(reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := previewCancellation(reservation, intents, now)
+	return &preview, nil
+}
+
+// CancelReservation cancela una reserva por su ID y libera sus habitaciones. La reserva no se
+// borra: queda cancelada con su historial. Según la política de cancelación se devuelve lo
+// cobrado que supera el cargo, se cobra el cargo de un pago solo autorizado y se anula el resto.
+// Esas operaciones se registran junto con la cancelación y se hacen después de confirmarla:
+// si el proveedor de pagos no responde la reserva queda cancelada y se reintentan en segundo plano.
+func CancelReservation(reservationID uint, actor, reason string) (*models.Reservation, *dto.CancellationPreviewDTO, error) {
+	var reservation *models.Reservation
+	var preview dto.CancellationPreviewDTO
+	var settlements []models.PaymentSettlement
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = lockReservation(tx, reservationID)
+		if err != nil {
+			return err
+		}
+
+		var intents []models.PaymentIntent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("reservation_id = ?", reservationID).Order("id").Find(&intents).Error; err != nil {
+			return err
+		}
+		preview = previewCancellation(reservation, intents, time.Now())
+
+		// La reserva se cancela antes de tocar los pagos para que un cobro no la confirme
+		reservation, err = transitionReservation(tx, reservationID, models.StatusCancelled, actor, reason)
+		if err != nil {
+			return err
+		}
+		settlements, err = scheduleCancellationSettlement(tx, reservation, intents, preview.Penalty, actor)
+		return err
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to cancel reservation: %v", err)
+	}
+	notifyOutbox()
+
+	for _, settlement := range settlements {
+		completed, err := runPaymentSettlement(settlement.ID)
+		if err != nil {
+			log.Printf("Failed to settle payment %d of cancelled reservation %d: %s", settlement.PaymentIntentID, reservationID, err)
+		}
+		if !completed {
+			preview.PendingSettlements++
+		}
+	}
+	notifyOutbox()
+
+	return reservation, &preview, nil
+}
+
+// scheduleCancellationSettlement deja fallidos los pagos que no llegaron a autorizarse y registra
+// las operaciones con el proveedor que dejan cobrado solo el cargo, sin hacerlas todavía
+func scheduleCancellationSettlement(tx *gorm.DB, reservation *models.Reservation, intents []models.PaymentIntent, penalty float64, actor string) ([]models.PaymentSettlement, error) {
+	for i := range intents {
+		intent := &intents[i]
+		if intent.Status == models.PaymentCreated || intent.Status == models.PaymentPending {
+			// Un webhook posterior ya no puede autorizar el pago de una reserva cancelada
+			if err := applyPaymentStatus(tx, intent, models.PaymentFailed, "reservation cancelled", 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	settlements := planCancellationSettlement(intents, penalty)
+	now := time.Now().UTC()
+	for i := range settlements {
+		settlements[i].ReservationID = reservation.ID
+		settlements[i].Status = models.SettlementPending
+		settlements[i].NextAttemptAt = now
+		settlements[i].Actor = actor
+		if err := tx.Create(&settlements[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return settlements, nil
+}
+
+// planCancellationSettlement calcula las operaciones que dejan los pagos de la reserva cancelada
+// cobrando solo el cargo: primero se descuenta de lo ya cobrado y se devuelve el resto, y lo que
+// falte se cobra de los pagos autorizados. Los pagos autorizados que no hacen falta se anulan.
+func planCancellationSettlement(intents []models.PaymentIntent, penalty float64) []models.PaymentSettlement {
+	var settlements []models.PaymentSettlement
+	remaining := penalty
+
+	for _, intent := range intents {
+		if intent.Status != models.PaymentCaptured && intent.Status != models.PaymentPartiallyRefunded {
+			continue
+		}
+		net := pricing.Round(intent.AmountCaptured - intent.AmountRefunded)
+		keep := min(net, remaining)
+		remaining = pricing.Round(remaining - keep)
+		if refund := pricing.Round(net - keep); refund > 0 {
+			settlements = append(settlements, models.PaymentSettlement{PaymentIntentID: intent.ID, Operation: settlementRefund, Amount: refund})
+		}
+	}
+
+	for _, intent := range intents {
+		if intent.Status != models.PaymentAuthorized {
+			continue
+		}
+		if charge := min(pricing.Round(intent.Amount-intent.AmountCaptured), remaining); charge > 0 {
+			remaining = pricing.Round(remaining - charge)
+			settlements = append(settlements, models.PaymentSettlement{PaymentIntentID: intent.ID, Operation: settlementCapture, Amount: charge})
+		} else {
+			settlements = append(settlements, models.PaymentSettlement{PaymentIntentID: intent.ID, Operation: settlementVoid})
+		}
+	}
+
+	return settlements
+}
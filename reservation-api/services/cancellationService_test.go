@@ -0,0 +1,67 @@
+package services
+
+import (
+	"reservation-api/models"
+	"testing"
+	"time"
+)
+
+func TestPlanCancellationSettlement(t *testing.T) {
+	intents := []models.PaymentIntent{
+		{ID: 1, Status: models.PaymentCaptured, Amount: 100, AmountCaptured: 100},
+		{ID: 2, Status: models.PaymentPartiallyRefunded, Amount: 100, AmountCaptured: 100, AmountRefunded: 70},
+		{ID: 3, Status: models.PaymentAuthorized, Amount: 200},
+		{ID: 4, Status: models.PaymentAuthorized, Amount: 50},
+		{ID: 5, Status: models.PaymentFailed, Amount: 100},
+	}
+
+	cases := []struct {
+		name     string
+		penalty  float64
+		expected []models.PaymentSettlement
+	}{
+		{"free cancellation", 0, []models.PaymentSettlement{
+			{PaymentIntentID: 1, Operation: settlementRefund, Amount: 100},
+			{PaymentIntentID: 2, Operation: settlementRefund, Amount: 30},
+			{PaymentIntentID: 3, Operation: settlementVoid},
+			{PaymentIntentID: 4, Operation: settlementVoid},
+		}},
+		{"penalty covered by what was captured", 110, []models.PaymentSettlement{
+			{PaymentIntentID: 2, Operation: settlementRefund, Amount: 20},
+			{PaymentIntentID: 3, Operation: settlementVoid},
+			{PaymentIntentID: 4, Operation: settlementVoid},
+		}},
+		{"penalty charged from the authorizations", 350, []models.PaymentSettlement{
+			{PaymentIntentID: 3, Operation: settlementCapture, Amount: 200},
+			{PaymentIntentID: 4, Operation: settlementCapture, Amount: 20},
+		}},
+	}
+
+	for _, tc := range cases {
+		got := planCancellationSettlement(intents, tc.penalty)
+		if len(got) != len(tc.expected) {
+			t.Errorf("%s: expected %d operations, got %+v", tc.name, len(tc.expected), got)
+			continue
+		}
+		for i, expected := range tc.expected {
+			if got[i].PaymentIntentID != expected.PaymentIntentID || got[i].Operation != expected.Operation || got[i].Amount != expected.Amount {
+				t.Errorf("%s: operation %d: expected %+v, got %+v", tc.name, i, expected, got[i])
+			}
+		}
+	}
+}
+
+func TestSettlementBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  time.Minute,
+		3:  8 * time.Minute,
+		4:  16 * time.Minute,
+		5:  settlementMaxBackoff, // 32 minutos supera el máximo
+		40: settlementMaxBackoff,
+	}
+	for attempts, expected := range cases {
+		if got := settlementBackoff(attempts); got != expected {
+			t.Errorf("%d attempts: expected %s, got %s", attempts, expected, got)
+		}
+	}
+}
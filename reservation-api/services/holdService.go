@@ -0,0 +1,276 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Minutos que dura un bloqueo cuando no se configura HOLD_TTL_MINUTES
+const defaultHoldTTLMinutes = 15
+
+// Bloqueos vigentes que puede tener un usuario cuando no se configura MAX_ACTIVE_HOLDS_PER_USER
+const defaultMaxActiveHolds = 3
+
+// holdTTL devuelve cuánto dura un bloqueo temporal
+func holdTTL() time.Duration {
+	minutes := envInt("HOLD_TTL_MINUTES", defaultHoldTTLMinutes)
+	if minutes == 0 {
+		minutes = defaultHoldTTLMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// maxActiveHolds devuelve cuántos bloqueos vigentes puede tener un usuario a la vez
+func maxActiveHolds() int {
+	limit := envInt("MAX_ACTIVE_HOLDS_PER_USER", defaultMaxActiveHolds)
+	if limit == 0 {
+		limit = defaultMaxActiveHolds
+	}
+	return limit
+}
+
+// checkHoldLimit verifica que el usuario no tenga ya el máximo de bloqueos vigentes. Sus bloqueos
+// se leen con FOR UPDATE para que dos pedidos simultáneos del mismo usuario se esperen entre sí.
+func checkHoldLimit(tx *gorm.DB, userID uint, now time.Time) error {
+	var active []models.RoomHold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, models.HoldActive, now).
+		Find(&active).Error
+	if err != nil {
+		return err
+	}
+	if len(active) >= maxActiveHolds() {
+		return ErrHoldLimitReached
+	}
+	return nil
+}
+
+// newHoldToken genera un token aleatorio que no se puede adivinar
+func newHoldToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// sameStay indica si dos rangos de fechas tienen las mismas noches
+func sameStay(fromA, toA, fromB, toB time.Time) bool {
+	a, b := nights(fromA, toA), nights(fromB, toB)
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	return a[0].Equal(b[0])
+}
+
+// CreateHold bloquea una habitación del tipo elegido para cada noche del rango durante
+// HOLD_TTL_MINUTES. El token devuelto se usa para convertir el bloqueo en una reserva.
+// Un usuario no puede tener más de MAX_ACTIVE_HOLDS_PER_USER bloqueos vigentes a la vez.
+func CreateHold(userID uint, holdDto dto.HoldDTO) (*models.RoomHold, error) {
+	guests := holdDto.Guests
+	if guests == 0 {
+		guests = 1
+	}
+
+	// Mismas validaciones que una reserva: no tiene sentido bloquear algo que no se podrá reservar
+	if err := ValidateReservation(dto.ReservationDTO{
+		HotelID:    holdDto.HotelID,
+		RoomTypeID: holdDto.RoomTypeID,
+		Guests:     guests,
+		FechaDesde: holdDto.FechaDesde,
+		FechaHasta: holdDto.FechaHasta,
+		UserID:     userID,
+	}); err != nil {
+		return nil, err
+	}
+
+	var hold *models.RoomHold
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkHoldLimit(tx, userID, time.Now()); err != nil {
+			return err
+		}
+		roomType, err := roomTypeForStay(tx, holdDto.HotelID, holdDto.RoomTypeID, guests)
+		if err != nil {
+			return err
+		}
+		hold, err = placeHold(tx, roomType, userID, guests, holdDto.FechaDesde, holdDto.FechaHasta, holdTTL())
+		return err
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create hold: %v", err)
+	}
+
+	return hold, nil
+}
+
+// placeHold toma una habitación del tipo para cada noche del rango y crea el bloqueo dentro de la transacción
+func placeHold(tx *gorm.DB, roomType models.RoomType, userID uint, guests int, from, to time.Time, ttl time.Duration) (*models.RoomHold, error) {
+	token, err := newHoldToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate hold token: %v", err)
+	}
+
+	if err := reserveInventory(tx, roomType, from, to); err != nil {
+		return nil, err
+	}
+
+	hold := models.RoomHold{
+		Token:      token,
+		UserID:     userID,
+		HotelID:    roomType.HotelID,
+		RoomTypeID: roomType.ID,
+		Guests:     guests,
+		FechaDesde: from,
+		FechaHasta: to,
+		Status:     models.HoldActive,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+	if err := tx.Create(&hold).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// GetHold obtiene un bloqueo del usuario por su token
+func GetHold(userID uint, token string) (*models.RoomHold, error) {
+	var hold models.RoomHold
+	err := initializers.DB.Where("token = ? AND user_id = ?", token, userID).First(&hold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hold: %v", err)
+	}
+	return &hold, nil
+}
+
+// lockHold obtiene el bloqueo del usuario bloqueando la fila hasta el final de la transacción
+func lockHold(tx *gorm.DB, userID uint, token string) (*models.RoomHold, error) {
+	var hold models.RoomHold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ? AND user_id = ?", token, userID).First(&hold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// ReleaseHold libera un bloqueo activo del usuario y devuelve la habitación al inventario
+func ReleaseHold(userID uint, token string) (*models.RoomHold, error) {
+	var hold *models.RoomHold
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = lockHold(tx, userID, token)
+		if err != nil {
+			return err
+		}
+		if hold.Status != models.HoldActive {
+			return ErrHoldExpired
+		}
+		return endHold(tx, hold, models.HoldReleased)
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to release hold: %v", err)
+	}
+	notifyOutbox()
+
+	return hold, nil
+}
+
+// endHold devuelve la habitación del bloqueo al inventario y lo deja en el estado indicado.
+// Si el bloqueo se había ofrecido a la lista de espera, la oferta vence y la habitación
+// se ofrece al siguiente usuario.
+func endHold(tx *gorm.DB, hold *models.RoomHold, status string) error {
+	if err := releaseInventory(tx, hold.RoomTypeID, hold.FechaDesde, hold.FechaHasta); err != nil {
+		return fmt.Errorf("failed to release inventory: %v", err)
+	}
+	hold.Status = status
+	if err := tx.Model(hold).Update("status", status).Error; err != nil {
+		return err
+	}
+
+	if err := updateWaitlistOffer(tx, hold.Token, models.WaitlistExpired); err != nil {
+		return err
+	}
+	return offerFreedRoom(tx, hold.RoomTypeID, hold.FechaDesde, hold.FechaHasta)
+}
+
+// lockHoldForReservation obtiene el bloqueo que se va a convertir en la reserva y verifica
+// que siga vigente y que sea de la misma habitación y fechas
+func lockHoldForReservation(tx *gorm.DB, token string, reservation *models.Reservation) (*models.RoomHold, error) {
+	hold, err := lockHold(tx, reservation.UserID, token)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != models.HoldActive || !hold.ExpiresAt.After(time.Now()) {
+		return nil, ErrHoldExpired
+	}
+	if hold.HotelID != reservation.HotelID || hold.RoomTypeID != reservation.RoomTypeID ||
+		!sameStay(hold.FechaDesde, hold.FechaHasta, reservation.FechaDesde, reservation.FechaHasta) {
+		return nil, ErrHoldMismatch
+	}
+	return hold, nil
+}
+
+// convertHold marca el bloqueo como usado por la reserva; la habitación pasa a la reserva.
+// Si era una oferta de la lista de espera, la entrada queda cumplida.
+func convertHold(tx *gorm.DB, hold *models.RoomHold, reservationID uint) error {
+	hold.Status = models.HoldConverted
+	hold.ReservationID = &reservationID
+	if err := tx.Model(hold).Select("Status", "ReservationID").Updates(hold).Error; err != nil {
+		return err
+	}
+	return updateWaitlistOffer(tx, hold.Token, models.WaitlistFulfilled)
+}
+
+// ReleaseExpiredHolds libera los bloqueos activos vencidos antes de now y devuelve cuántos liberó
+func ReleaseExpiredHolds(now time.Time) (int, error) {
+	released := 0
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var holds []models.RoomHold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND expires_at <= ?", models.HoldActive, now).
+			Order("id").
+			Find(&holds).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range holds {
+			if err := endHold(tx, &holds[i], models.HoldExpired); err != nil {
+				return err
+			}
+			released++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to release expired holds: %v", err)
+	}
+	if released > 0 {
+		notifyOutbox()
+	}
+
+	return released, nil
+}
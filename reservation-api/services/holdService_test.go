@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"reservation-api/models"
+	"testing"
+	"time"
+)
+
+func TestSameStay(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2030, 3, d, hour, 0, 0, 0, time.UTC) }
+
+	if !sameStay(day(1, 14), day(3, 10), day(1, 0), day(3, 0)) {
+		t.Error("ranges with the same nights should match")
+	}
+	if sameStay(day(1, 0), day(3, 0), day(1, 0), day(4, 0)) {
+		t.Error("ranges with different check-out should not match")
+	}
+	if sameStay(day(1, 0), day(3, 0), day(2, 0), day(4, 0)) {
+		t.Error("ranges with different check-in should not match")
+	}
+}
+
+func TestCheckHoldLimit(t *testing.T) {
+	db := openTestDB(t)
+	t.Setenv("MAX_ACTIVE_HOLDS_PER_USER", "2")
+	now := time.Now()
+
+	holds := []models.RoomHold{
+		{Token: "a", UserID: 1, Status: models.HoldActive, ExpiresAt: now.Add(time.Minute)},
+		{Token: "b", UserID: 1, Status: models.HoldActive, ExpiresAt: now.Add(-time.Minute)}, // vencido, todavía sin liberar
+		{Token: "c", UserID: 1, Status: models.HoldConverted, ExpiresAt: now.Add(time.Minute)},
+		{Token: "d", UserID: 2, Status: models.HoldActive, ExpiresAt: now.Add(time.Minute)},
+	}
+	for i := range holds {
+		db.Create(&holds[i])
+	}
+
+	if err := checkHoldLimit(db, 1, now); err != nil {
+		t.Fatalf("one active hold should be below the limit, got %v", err)
+	}
+
+	db.Create(&models.RoomHold{Token: "e", UserID: 1, Status: models.HoldActive, ExpiresAt: now.Add(time.Minute)})
+	if err := checkHoldLimit(db, 1, now); !errors.Is(err, ErrHoldLimitReached) {
+		t.Errorf("expected the hold limit to be reached, got %v", err)
+	}
+	if err := checkHoldLimit(db, 2, now); err != nil {
+		t.Errorf("other users are not affected by the limit, got %v", err)
+	}
+}
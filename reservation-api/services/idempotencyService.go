@@ -0,0 +1,97 @@
+package services
+
+import (
+	"reservation-api/initializers"
+	"reservation-api/middleware"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Horas que se guardan las claves de idempotencia cuando no se configura IDEMPOTENCY_TTL_HOURS
+const defaultIdempotencyTTLHours = 24
+
+// IdempotencyTTL devuelve cuánto tiempo se guarda la respuesta de una clave de idempotencia
+func IdempotencyTTL() time.Duration {
+	hours := envInt("IDEMPOTENCY_TTL_HOURS", defaultIdempotencyTTLHours)
+	if hours == 0 {
+		hours = defaultIdempotencyTTLHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// IdempotencyStore guarda las claves de idempotencia en la base de datos
+type IdempotencyStore struct{}
+
+// Begin reserva la clave del usuario o devuelve la que ya existe
+func (IdempotencyStore) Begin(userID uint, key, requestHash string, expiresAt time.Time) (*middleware.IdempotencyRecord, error) {
+	var record *middleware.IdempotencyRecord
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Si dos solicitudes llegan a la vez el índice único deja pasar solo a una
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   expiresAt,
+		})
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected == 1 {
+			return nil
+		}
+
+		var existing models.IdempotencyKey
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND idempotency_key = ?", userID, key).
+			First(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		// Una clave vencida se reutiliza como si fuera nueva
+		if existing.ExpiresAt.Before(time.Now()) {
+			return tx.Model(&existing).Select("RequestHash", "StatusCode", "ResponseBody", "ExpiresAt", "CreatedAt").Updates(models.IdempotencyKey{
+				RequestHash: requestHash,
+				ExpiresAt:   expiresAt,
+				CreatedAt:   time.Now(),
+			}).Error
+		}
+
+		record = &middleware.IdempotencyRecord{
+			RequestHash: existing.RequestHash,
+			Completed:   existing.StatusCode != 0,
+			StatusCode:  existing.StatusCode,
+			Body:        existing.ResponseBody,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Complete guarda la respuesta de la solicitud original
+func (IdempotencyStore) Complete(userID uint, key string, statusCode int, body []byte) error {
+	return initializers.DB.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND idempotency_key = ?", userID, key).
+		Updates(map[string]interface{}{"status_code": statusCode, "response_body": body}).Error
+}
+
+// Release borra la clave para que la solicitud se pueda reintentar
+func (IdempotencyStore) Release(userID uint, key string) error {
+	return initializers.DB.Where("user_id = ? AND idempotency_key = ?", userID, key).Delete(&models.IdempotencyKey{}).Error
+}
+
+// PurgeExpiredIdempotencyKeys borra las claves vencidas y devuelve cuántas borró
+func PurgeExpiredIdempotencyKeys(now time.Time) (int64, error) {
+	result := initializers.DB.Where("expires_at < ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// nights devuelve las noches entre from (inclusive) y to (exclusive), a medianoche UTC
+func nights(from, to time.Time) []time.Time {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	var result []time.Time
+	for night := start; night.Before(end); night = night.AddDate(0, 0, 1) {
+		result = append(result, night)
+	}
+	return result
+}
+
+// CreateRoomType crea un tipo de habitación para el hotel
+func CreateRoomType(hotelID string, roomTypeDto dto.RoomTypeDTO) (*models.RoomType, error) {
+	if err := checkCancellationPolicy(initializers.DB, hotelID, roomTypeDto.CancellationPolicyID); err != nil {
+		return nil, err
+	}
+
+	roomType := models.RoomType{
+		HotelID:              hotelID,
+		Name:                 roomTypeDto.Name,
+		Capacity:             roomTypeDto.Capacity,
+		Count:                roomTypeDto.Count,
+		BaseRate:             roomTypeDto.BaseRate,
+		CancellationPolicyID: roomTypeDto.CancellationPolicyID,
+	}
+
+	if err := initializers.DB.Create(&roomType).Error; err != nil {
+		return nil, fmt.Errorf("failed to create room type: %v", err)
+	}
+
+	return &roomType, nil
+}
+
+// GetRoomTypesByHotel obtiene los tipos de habitación del hotel
+func GetRoomTypesByHotel(hotelID string) ([]models.RoomType, error) {
+	var roomTypes []models.RoomType
+	if err := initializers.DB.Where("hotel_id = ?", hotelID).Order("id").Find(&roomTypes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch room types for hotel %s: %v", hotelID, err)
+	}
+	return roomTypes, nil
+}
+
+// UpdateRoomType modifica un tipo de habitación. Si cambia la cantidad de habitaciones se
+// actualiza el inventario de las noches futuras, siempre que no queden reservas sin habitación.
+func UpdateRoomType(roomTypeID uint, roomTypeDto dto.RoomTypeDTO) (*models.RoomType, error) {
+	var roomType models.RoomType
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&roomType, roomTypeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoomTypeNotFound
+			}
+			return err
+		}
+
+		if roomTypeDto.Count != roomType.Count {
+			today := nights(time.Now(), time.Now().AddDate(0, 0, 1))[0]
+			future := tx.Model(&models.RoomInventory{}).Where("room_type_id = ? AND date >= ?", roomTypeID, today)
+
+			var overbooked int64
+			if err := future.Session(&gorm.Session{}).Where("reserved > ?", roomTypeDto.Count).Count(&overbooked).Error; err != nil {
+				return err
+			}
+			if overbooked > 0 {
+				return ErrInventoryInUse
+			}
+
+			if err := future.Session(&gorm.Session{}).UpdateColumn("total", roomTypeDto.Count).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := checkCancellationPolicy(tx, roomType.HotelID, roomTypeDto.CancellationPolicyID); err != nil {
+			return err
+		}
+
+		roomType.Name = roomTypeDto.Name
+		roomType.Capacity = roomTypeDto.Capacity
+		roomType.Count = roomTypeDto.Count
+		roomType.BaseRate = roomTypeDto.BaseRate
+		roomType.CancellationPolicyID = roomTypeDto.CancellationPolicyID
+		return tx.Save(&roomType).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &roomType, nil
+}
+
+// roomTypeForStay obtiene el tipo de habitación del hotel y verifica que entren los huéspedes
+func roomTypeForStay(tx *gorm.DB, hotelID string, roomTypeID uint, guests int) (models.RoomType, error) {
+	var roomType models.RoomType
+	err := tx.Where("id = ? AND hotel_id = ?", roomTypeID, hotelID).First(&roomType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return roomType, ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return roomType, err
+	}
+	if guests > roomType.Capacity {
+		return roomType, ErrRoomTypeCapacity
+	}
+	return roomType, nil
+}
+
+// reserveInventory toma una habitación del tipo para cada noche del rango dentro de la transacción.
+// Las filas de inventario se bloquean (SELECT ... FOR UPDATE) en orden de fecha, así dos reservas
+// concurrentes sobre la misma noche se esperan entre sí y nunca se vende más de lo que hay.
+func reserveInventory(tx *gorm.DB, roomType models.RoomType, from, to time.Time) error {
+	dates := nights(from, to)
+	if len(dates) == 0 {
+		return ErrNoAvailability
+	}
+
+	// Crear las noches que todavía no tienen inventario; si otra transacción las crea primero no pasa nada
+	rows := make([]models.RoomInventory, len(dates))
+	for i, date := range dates {
+		rows[i] = models.RoomInventory{RoomTypeID: roomType.ID, Date: date, Total: roomType.Count}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to prepare inventory: %v", err)
+	}
+
+	var inventory []models.RoomInventory
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("room_type_id = ? AND date >= ? AND date < ?", roomType.ID, dates[0], dates[len(dates)-1].AddDate(0, 0, 1)).
+		Order("date").
+		Find(&inventory).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock inventory: %v", err)
+	}
+	if len(inventory) != len(dates) {
+		return fmt.Errorf("inventory for room type %d is incomplete", roomType.ID)
+	}
+
+	ids := make([]uint, len(inventory))
+	for i, night := range inventory {
+		if night.Reserved >= night.Total {
+			return ErrNoAvailability
+		}
+		ids[i] = night.ID
+	}
+
+	return tx.Model(&models.RoomInventory{}).Where("id IN ?", ids).
+		UpdateColumn("reserved", gorm.Expr("reserved + 1")).Error
+}
+
+// releaseInventory devuelve la habitación de cada noche del rango dentro de la transacción
+func releaseInventory(tx *gorm.DB, roomTypeID uint, from, to time.Time) error {
+	dates := nights(from, to)
+	if len(dates) == 0 {
+		return nil
+	}
+
+	return tx.Model(&models.RoomInventory{}).
+		Where("room_type_id = ? AND date >= ? AND date < ? AND reserved > 0", roomTypeID, dates[0], dates[len(dates)-1].AddDate(0, 0, 1)).
+		UpdateColumn("reserved", gorm.Expr("reserved - 1")).Error
+}
+
+// remainingRooms calcula las habitaciones libres del tipo en todas las noches del rango:
+// la noche más ocupada define cuántas quedan.
+func remainingRooms(roomType models.RoomType, inventory []models.RoomInventory) int {
+	remaining := roomType.Count
+	for _, night := range inventory {
+		if free := night.Total - night.Reserved; free < remaining {
+			remaining = free
+		}
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"reservation-api/models"
+	"testing"
+	"time"
+)
+
+func TestInventoryIgnoresLocalZone(t *testing.T) {
+	for _, zone := range []*time.Location{time.FixedZone("UTC+3", 3*60*60), time.FixedZone("UTC-5", -5*60*60)} {
+		t.Run(zone.String(), func(t *testing.T) {
+			withLocalZone(t, zone)
+			db := openTestDB(t)
+			roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+			db.Create(&roomType)
+
+			// Fechas como las manda el cliente, a medianoche de la zona del servidor
+			checkIn := time.Date(2030, 3, 1, 0, 0, 0, 0, time.Local)
+			checkOut := time.Date(2030, 3, 3, 0, 0, 0, 0, time.Local)
+			if err := reserveInventory(db, roomType, checkIn, checkOut); err != nil {
+				t.Fatal(err)
+			}
+
+			var inventory []models.RoomInventory
+			db.Where("room_type_id = ?", roomType.ID).Order("date").Find(&inventory)
+			if len(inventory) != 2 || !inventory[0].Date.Equal(time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)) || !inventory[1].Date.Equal(time.Date(2030, 3, 2, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("expected the nights of March 1 and 2 as UTC midnights, got %+v", inventory)
+			}
+
+			availability, err := GetHotelAvailability("h1", checkIn, checkOut, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(availability) != 1 || availability[0].RemainingRooms != 0 {
+				t.Errorf("the reserved nights should be full, got %+v", availability)
+			}
+
+			if err := releaseInventory(db, roomType.ID, checkIn, checkOut); err != nil {
+				t.Fatal(err)
+			}
+			for _, reserved := range reservedNights(t, db, roomType, nights(checkIn, checkOut)[0], nights(checkIn, checkOut)[1].AddDate(0, 0, 1)) {
+				if reserved != 0 {
+					t.Errorf("released nights should be free, got %d reserved", reserved)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reservationSnapshot son los datos modificables de una reserva que se guardan en el historial
+type reservationSnapshot struct {
+	RoomTypeID uint      `json:"roomTypeId"`
+	Guests     int       `json:"guests"`
+	FechaDesde time.Time `json:"fechaDesde"`
+	FechaHasta time.Time `json:"fechaHasta"`
+	TotalPrice float64   `json:"totalPrice"`
+}
+
+func snapshot(reservation *models.Reservation) reservationSnapshot {
+	return reservationSnapshot{
+		RoomTypeID: reservation.RoomTypeID,
+		Guests:     reservation.Guests,
+		FechaDesde: reservation.FechaDesde,
+		FechaHasta: reservation.FechaHasta,
+		TotalPrice: reservation.TotalPrice,
+	}
+}
+
+// canModify indica si la reserva todavía se puede modificar: el huésped no llegó y no fue cancelada
+func canModify(status string) bool {
+	return status == models.StatusPending || status == models.StatusConfirmed
+}
+
+// ModifyReservation cambia las fechas, el tipo de habitación o los huéspedes de la reserva.
+// En una sola transacción devuelve al inventario las noches anteriores, toma las nuevas y
+// vuelve a cotizar el precio; si alguna noche nueva está completa la reserva queda como estaba.
+// El código promocional se conserva si la nueva estadía cumple sus condiciones; si no, se devuelve su uso.
+// Si el pago ya se autorizó o cobró, la modificación solo se acepta si no cambia el precio.
+func ModifyReservation(reservationID uint, modifyDto dto.ModifyReservationDTO, actor string) (*models.Reservation, error) {
+	if err := ValidateDates(modifyDto.FechaDesde, modifyDto.FechaHasta, time.Now(), dateRulesFromEnv()); err != nil {
+		return nil, err
+	}
+
+	var reservation *models.Reservation
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = lockReservation(tx, reservationID)
+		if err != nil {
+			return err
+		}
+		if !canModify(reservation.Status) {
+			return ErrNotModifiable
+		}
+		before := snapshot(reservation)
+
+		roomTypeID := modifyDto.RoomTypeID
+		if roomTypeID == 0 {
+			roomTypeID = reservation.RoomTypeID
+		}
+		guests := modifyDto.Guests
+		if guests == 0 {
+			guests = reservation.Guests
+		}
+
+		roomType, err := roomTypeForStay(tx, reservation.HotelID, roomTypeID, guests)
+		if err != nil {
+			return err
+		}
+
+		// Liberar primero las noches actuales: el rango nuevo puede superponerse con el anterior
+		if err := releaseInventory(tx, reservation.RoomTypeID, reservation.FechaDesde, reservation.FechaHasta); err != nil {
+			return fmt.Errorf("failed to release inventory: %v", err)
+		}
+		if err := reserveInventory(tx, roomType, modifyDto.FechaDesde, modifyDto.FechaHasta); err != nil {
+			return err
+		}
+
+		promo, err := reservationPromo(tx, reservation, len(nights(modifyDto.FechaDesde, modifyDto.FechaHasta)))
+		if err != nil {
+			return err
+		}
+		quote, err := quoteRoomType(tx, roomType, modifyDto.FechaDesde, modifyDto.FechaHasta, guests, toPricingPromo(promo))
+		if err != nil {
+			return err
+		}
+		if err := applyQuote(reservation, quote); err != nil {
+			return err
+		}
+		if err := syncPromoRedemption(tx, reservation); err != nil {
+			return err
+		}
+		if err := syncPaymentAmount(tx, reservation); err != nil {
+			return err
+		}
+
+		reservation.RoomTypeID = roomType.ID
+		reservation.Guests = guests
+		reservation.FechaDesde = modifyDto.FechaDesde
+		reservation.FechaHasta = modifyDto.FechaHasta
+		if err := tx.Save(reservation).Error; err != nil {
+			return err
+		}
+
+		details, err := json.Marshal(map[string]reservationSnapshot{"before": before, "after": snapshot(reservation)})
+		if err != nil {
+			return err
+		}
+
+		// La modificación queda en el historial sin cambiar el estado
+		err = tx.Create(&models.ReservationStatusChange{
+			ReservationID: reservation.ID,
+			FromStatus:    reservation.Status,
+			ToStatus:      reservation.Status,
+			Actor:         actor,
+			Reason:        modifyDto.Reason,
+			Details:       string(details),
+			CreatedAt:     time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return enqueueReservationEvent(tx, models.ReservationModifiedEvent, reservation, "", actor)
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to modify reservation: %v", err)
+	}
+	notifyOutbox()
+
+	return reservation, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"reservation-api/dto"
+	"reservation-api/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCanModify(t *testing.T) {
+	for _, status := range []string{models.StatusPending, models.StatusConfirmed} {
+		if !canModify(status) {
+			t.Errorf("%s reservations should be modifiable", status)
+		}
+	}
+	for _, status := range []string{models.StatusCheckedIn, models.StatusCheckedOut, models.StatusCancelled, models.StatusNoShow} {
+		if canModify(status) {
+			t.Errorf("%s reservations should not be modifiable", status)
+		}
+	}
+}
+
+// bookedReservation crea una reserva confirmada de from a to que ya ocupa su inventario
+func bookedReservation(t *testing.T, db *gorm.DB, roomType models.RoomType, from, to time.Time) *models.Reservation {
+	t.Helper()
+	if err := reserveInventory(db, roomType, from, to); err != nil {
+		t.Fatal(err)
+	}
+	reservation := models.Reservation{
+		UserID: 7, HotelID: roomType.HotelID, RoomTypeID: roomType.ID, Guests: 2,
+		FechaDesde: from, FechaHasta: to, Status: models.StatusConfirmed, TotalPrice: 200,
+	}
+	if err := db.Create(&reservation).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &reservation
+}
+
+// reservedNights devuelve cuántas habitaciones hay reservadas en cada noche de from a to
+func reservedNights(t *testing.T, db *gorm.DB, roomType models.RoomType, from, to time.Time) []int {
+	t.Helper()
+	var inventory []models.RoomInventory
+	err := db.Where("room_type_id = ? AND date >= ? AND date < ?", roomType.ID, from, to).Order("date").Find(&inventory).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	reserved := make([]int, len(inventory))
+	for i, night := range inventory {
+		reserved[i] = night.Reserved
+	}
+	return reserved
+}
+
+// assertUnchanged verifica que una modificación rechazada dejó la reserva y su inventario como estaban
+func assertUnchanged(t *testing.T, db *gorm.DB, roomType models.RoomType, original *models.Reservation) {
+	t.Helper()
+	var stored models.Reservation
+	db.First(&stored, original.ID)
+	if !stored.FechaDesde.Equal(original.FechaDesde) || !stored.FechaHasta.Equal(original.FechaHasta) || stored.TotalPrice != original.TotalPrice {
+		t.Errorf("reservation should be unchanged, got %s - %s for %.2f", stored.FechaDesde, stored.FechaHasta, stored.TotalPrice)
+	}
+	if got := reservedNights(t, db, roomType, original.FechaDesde, original.FechaHasta); len(got) != 2 || got[0] != 1 || got[1] != 1 {
+		t.Errorf("original nights should still be reserved, got %v", got)
+	}
+	var changes int64
+	db.Model(&models.ReservationStatusChange{}).Where("reservation_id = ?", original.ID).Count(&changes)
+	if changes != 0 {
+		t.Errorf("a rejected modification should not be recorded, got %d history entries", changes)
+	}
+}
+
+func TestModifyReservationRollsBackWithoutAvailability(t *testing.T) {
+	db := openTestDB(t)
+	roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+	db.Create(&roomType)
+
+	reservation := bookedReservation(t, db, roomType, testNight(10), testNight(12))
+	fillNights(t, roomType, testNight(12), testNight(13))
+
+	// La primera noche nueva se superpone con la reserva; la última está completa
+	modify := dto.ModifyReservationDTO{FechaDesde: testNight(11), FechaHasta: testNight(13)}
+	if _, err := ModifyReservation(reservation.ID, modify, "user:7"); !errors.Is(err, ErrNoAvailability) {
+		t.Fatalf("expected no availability, got %v", err)
+	}
+	assertUnchanged(t, db, roomType, reservation)
+}
+
+func TestModifyReservationRollsBackLockedPayment(t *testing.T) {
+	db := openTestDB(t)
+	roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+	db.Create(&roomType)
+
+	reservation := bookedReservation(t, db, roomType, testNight(10), testNight(12))
+	db.Create(&models.PaymentIntent{ReservationID: reservation.ID, Amount: 200, Status: models.PaymentAuthorized})
+
+	// Hay lugar para la noche extra, pero cambia el precio de un pago ya autorizado
+	modify := dto.ModifyReservationDTO{FechaDesde: testNight(10), FechaHasta: testNight(13)}
+	if _, err := ModifyReservation(reservation.ID, modify, "user:7"); !errors.Is(err, ErrPaymentAmountLocked) {
+		t.Fatalf("expected the payment amount to be locked, got %v", err)
+	}
+	assertUnchanged(t, db, roomType, reservation)
+	for _, reserved := range reservedNights(t, db, roomType, testNight(12), testNight(13)) {
+		if reserved != 0 {
+			t.Errorf("the extra night should not stay reserved, got %d", reserved)
+		}
+	}
+
+	// El mismo cambio sin pago autorizado se aplica
+	db.Model(&models.PaymentIntent{}).Where("reservation_id = ?", reservation.ID).Update("status", models.PaymentVoided)
+	modified, err := ModifyReservation(reservation.ID, modify, "user:7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified.FechaHasta.Equal(testNight(13)) {
+		t.Errorf("expected the new check-out, got %s", modified.FechaHasta)
+	}
+	if got := reservedNights(t, db, roomType, testNight(10), testNight(13)); len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 1 {
+		t.Errorf("expected the three new nights reserved, got %v", got)
+	}
+}
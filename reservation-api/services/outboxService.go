@@ -0,0 +1,247 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"strconv"
+	"time"
+
+	"github.com/streadway/amqp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Nombre del exchange donde se publican los eventos de reservas y de la lista de espera
+const reservationEventsExchange = "reservation_events"
+
+const (
+	outboxPollInterval = 2 * time.Second
+	outboxLease        = 30 * time.Second // Tiempo que una entrada queda reservada mientras se publica
+	outboxMaxBackoff   = 5 * time.Minute
+	publishTimeout     = 5 * time.Second
+)
+
+// Avisa al relay que hay entradas nuevas para no esperar al próximo ciclo
+var outboxSignal = make(chan struct{}, 1)
+
+// enqueueEvent guarda el evento en el outbox dentro de la transacción del cambio que lo origina
+func enqueueEvent(tx *gorm.DB, eventType, aggregateID string, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", eventType, err)
+	}
+
+	now := time.Now().UTC()
+	return tx.Create(&models.OutboxEntry{
+		EventType:     eventType,
+		AggregateID:   aggregateID,
+		Payload:       string(payload),
+		Status:        models.OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// enqueueReservationEvent guarda en el outbox el evento del cambio sobre la reserva
+func enqueueReservationEvent(tx *gorm.DB, eventType string, reservation *models.Reservation, previousStatus, actor string) error {
+	return enqueueEvent(tx, eventType, strconv.FormatUint(uint64(reservation.ID), 10), models.ReservationEvent{
+		SchemaVersion:  models.ReservationEventSchemaVersion,
+		EventType:      eventType,
+		Timestamp:      time.Now().UTC(),
+		ReservationID:  reservation.ID,
+		UserID:         reservation.UserID,
+		HotelID:        reservation.HotelID,
+		RoomTypeID:     reservation.RoomTypeID,
+		Guests:         reservation.Guests,
+		CheckIn:        reservation.FechaDesde.Format("2006-01-02"),
+		CheckOut:       reservation.FechaHasta.Format("2006-01-02"),
+		Status:         reservation.Status,
+		PreviousStatus: previousStatus,
+		Currency:       reservation.Currency,
+		TotalPrice:     reservation.TotalPrice,
+		Actor:          actor,
+	})
+}
+
+// notifyOutbox despierta al relay después de confirmar una transacción
+func notifyOutbox() {
+	select {
+	case outboxSignal <- struct{}{}:
+	default:
+	}
+}
+
+// StartOutboxRelay publica en segundo plano las entradas pendientes del outbox
+func StartOutboxRelay() {
+	go func() {
+		ticker := time.NewTicker(outboxPollInterval)
+		defer ticker.Stop()
+
+		for {
+			relayPendingEvents()
+
+			select {
+			case <-ticker.C:
+			case <-outboxSignal:
+			}
+		}
+	}()
+}
+
+// relayPendingEvents publica todas las entradas vencidas hasta vaciar el outbox
+func relayPendingEvents() {
+	for {
+		entry, err := claimNextOutboxEntry()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to read outbox: %s", err)
+			return
+		}
+
+		if err := publishOutboxEntry(entry); err != nil {
+			log.Printf("Failed to publish outbox entry %d (attempt %d): %s", entry.ID, entry.Attempts+1, err)
+			markOutboxRetry(entry, err)
+			return
+		}
+
+		markOutboxDelivered(entry)
+	}
+}
+
+// claimNextOutboxEntry reserva la entrada pendiente más antigua para que ningún otro relay la tome
+func claimNextOutboxEntry() (models.OutboxEntry, error) {
+	var entry models.OutboxEntry
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		if err := nextOutboxEntryQuery(tx, now).First(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entry).Update("next_attempt_at", now.Add(outboxLease)).Error
+	})
+	return entry, err
+}
+
+// nextOutboxEntryQuery busca la entrada pendiente más antigua que ya se puede publicar. Una entrada
+// no se toma mientras haya otra anterior de la misma entidad sin publicar, aunque esa esté esperando
+// su reintento: así los eventos de una reserva llegan en el orden en que ocurrieron.
+func nextOutboxEntryQuery(tx *gorm.DB, now time.Time) *gorm.DB {
+	earlier := tx.Session(&gorm.Session{NewDB: true}).
+		Table("outbox_entries AS earlier").
+		Select("1").
+		Where("earlier.aggregate_id = outbox_entries.aggregate_id AND earlier.status = ? AND earlier.id < outbox_entries.id", models.OutboxPending)
+
+	return tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, now).
+		Where("NOT EXISTS (?)", earlier).
+		Order("id")
+}
+
+// markOutboxDelivered marca la entrada como publicada
+func markOutboxDelivered(entry models.OutboxEntry) {
+	now := time.Now().UTC()
+	err := initializers.DB.Model(&entry).Updates(map[string]interface{}{
+		"status":       models.OutboxDelivered,
+		"delivered_at": now,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+	}).Error
+	if err != nil {
+		log.Printf("Failed to mark outbox entry %d as delivered: %s", entry.ID, err)
+	}
+}
+
+// markOutboxRetry reprograma la entrada con backoff exponencial
+func markOutboxRetry(entry models.OutboxEntry, cause error) {
+	err := initializers.DB.Model(&entry).Updates(map[string]interface{}{
+		"next_attempt_at": time.Now().UTC().Add(outboxBackoff(entry.Attempts)),
+		"last_error":      cause.Error(),
+		"attempts":        gorm.Expr("attempts + 1"),
+	}).Error
+	if err != nil {
+		log.Printf("Failed to reschedule outbox entry %d: %s", entry.ID, err)
+	}
+}
+
+// outboxBackoff es la espera antes del próximo intento de una entrada que ya falló attempts veces
+func outboxBackoff(attempts int) time.Duration {
+	backoff := time.Duration(1<<uint(min(attempts, 16))) * time.Second
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	return backoff
+}
+
+// publishOutboxEntry publica la entrada en RabbitMQ y espera la confirmación del broker
+func publishOutboxEntry(entry models.OutboxEntry) error {
+	// Reconectar si el broker se cayó desde la última publicación
+	if !initializers.RabbitMQConnected() {
+		if err := initializers.ConnectRabbitMQ(); err != nil {
+			return fmt.Errorf("RabbitMQ is not available: %w", err)
+		}
+	}
+
+	// Asegurarse de que el exchange de eventos esté declarado
+	err := initializers.RabbitMQChannel.ExchangeDeclare(
+		reservationEventsExchange, // nombre del exchange
+		"topic",                   // tipo
+		true,                      // durable
+		false,                     // auto-deleted
+		false,                     // internal
+		false,                     // no-wait
+		nil,                       // argumentos adicionales
+	)
+	if err != nil {
+		initializers.CloseRabbitMQ()
+		return fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	// Publicar el evento usando el tipo como routing key. Con mandatory el broker devuelve el
+	// mensaje si ninguna cola lo recibe, en vez de descartarlo y confirmarlo igual.
+	err = initializers.RabbitMQChannel.Publish(
+		reservationEventsExchange, // exchange
+		entry.EventType,           // routing key
+		true,                      // mandatory
+		false,                     // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    strconv.FormatUint(uint64(entry.ID), 10),
+			Timestamp:    entry.CreatedAt,
+			Type:         entry.EventType,
+			Body:         []byte(entry.Payload),
+		},
+	)
+	if err != nil {
+		initializers.CloseRabbitMQ()
+		return fmt.Errorf("failed to publish reservation event: %w", err)
+	}
+
+	select {
+	case confirm, ok := <-initializers.RabbitMQConfirms:
+		if !ok || !confirm.Ack {
+			initializers.CloseRabbitMQ()
+			return fmt.Errorf("broker did not acknowledge reservation event")
+		}
+	case <-time.After(publishTimeout):
+		// Sin confirmación no sabemos qué pasó; se reabre el canal y se reintenta
+		initializers.CloseRabbitMQ()
+		return fmt.Errorf("timed out waiting for broker confirmation")
+	}
+
+	// El broker envía la devolución antes que la confirmación, así que si hubo una ya está en el canal
+	select {
+	case returned := <-initializers.RabbitMQReturns:
+		return fmt.Errorf("reservation event was not routed to any queue: %s", returned.ReplyText)
+	default:
+	}
+
+	log.Printf("Reservation event %s sent for %s", entry.EventType, entry.AggregateID)
+	return nil
+}
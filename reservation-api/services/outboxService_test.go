@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"reservation-api/models"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB arma las consultas con el dialecto de MySQL sin conectarse a la base
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test@/test", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestNextOutboxEntryQuery(t *testing.T) {
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var entry models.OutboxEntry
+	stmt := nextOutboxEntryQuery(dryRunDB(t), now).First(&entry).Statement
+	sql := stmt.SQL.String()
+
+	for _, expected := range []string{
+		"status = ? AND next_attempt_at <= ?",
+		"NOT EXISTS (SELECT 1 FROM outbox_entries AS earlier WHERE earlier.aggregate_id = outbox_entries.aggregate_id AND earlier.status = ? AND earlier.id < outbox_entries.id)",
+		"ORDER BY id",
+		"FOR UPDATE SKIP LOCKED",
+	} {
+		if !strings.Contains(sql, expected) {
+			t.Errorf("query should contain %q:\n%s", expected, sql)
+		}
+	}
+
+	// Los argumentos terminan con el LIMIT 1 de First
+	if len(stmt.Vars) != 4 || stmt.Vars[0] != models.OutboxPending || stmt.Vars[1] != now || stmt.Vars[2] != models.OutboxPending {
+		t.Errorf("unexpected query arguments: %v", stmt.Vars)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  time.Second,
+		1:  2 * time.Second,
+		5:  32 * time.Second,
+		8:  256 * time.Second,
+		9:  outboxMaxBackoff, // 512s supera el máximo
+		40: outboxMaxBackoff,
+	}
+	for attempts, expected := range cases {
+		if got := outboxBackoff(attempts); got != expected {
+			t.Errorf("%d attempts: expected %s, got %s", attempts, expected, got)
+		}
+	}
+}
+
+func TestClaimNextOutboxEntryKeepsAggregateOrder(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Now().UTC()
+
+	entries := []models.OutboxEntry{
+		{EventType: models.ReservationCreatedEvent, AggregateID: "1", NextAttemptAt: now.Add(time.Hour)}, // esperando su reintento
+		{EventType: models.ReservationModifiedEvent, AggregateID: "1", NextAttemptAt: now.Add(-time.Minute)},
+		{EventType: models.ReservationCreatedEvent, AggregateID: "2", NextAttemptAt: now.Add(-time.Minute)},
+	}
+	for i := range entries {
+		entries[i].Status = models.OutboxPending
+		entries[i].Payload = "{}"
+		entries[i].CreatedAt = now
+		db.Create(&entries[i])
+	}
+
+	// La modificación de la reserva 1 no se adelanta a su creación, que todavía no se publicó
+	entry, err := claimNextOutboxEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.ID != entries[2].ID {
+		t.Fatalf("expected entry %d of another reservation, got %d", entries[2].ID, entry.ID)
+	}
+	markOutboxDelivered(entry)
+
+	// La entrada tomada queda reservada y no se vuelve a entregar
+	if entry, err := claimNextOutboxEntry(); err == nil {
+		t.Fatalf("expected nothing to claim, got entry %d", entry.ID)
+	}
+
+	// Cuando se publica la creación, la modificación ya se puede tomar
+	db.Model(&entries[0]).Update("next_attempt_at", now.Add(-time.Hour))
+	first, _ := claimNextOutboxEntry()
+	markOutboxDelivered(first)
+	second, err := claimNextOutboxEntry()
+	if err != nil || first.ID != entries[0].ID || second.ID != entries[1].ID {
+		t.Errorf("expected entries %d and %d in order, got %d and %d (%v)", entries[0].ID, entries[1].ID, first.ID, second.ID, err)
+	}
+}
+
+func TestMarkOutboxRetry(t *testing.T) {
+	db := openTestDB(t)
+	entry := models.OutboxEntry{EventType: models.ReservationCreatedEvent, AggregateID: "1", Payload: "{}", Status: models.OutboxPending, Attempts: 3, NextAttemptAt: time.Now().UTC()}
+	db.Create(&entry)
+
+	before := time.Now().UTC()
+	markOutboxRetry(entry, errors.New("broker down"))
+
+	var stored models.OutboxEntry
+	db.First(&stored, entry.ID)
+	if stored.Attempts != 4 || stored.LastError != "broker down" || stored.Status != models.OutboxPending {
+		t.Errorf("unexpected entry after retry: %+v", stored)
+	}
+	if wait := stored.NextAttemptAt.Sub(before); wait < outboxBackoff(3) || wait > outboxBackoff(3)+time.Minute {
+		t.Errorf("expected the next attempt in %s, got %s", outboxBackoff(3), wait)
+	}
+}
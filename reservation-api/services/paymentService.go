@@ -0,0 +1,499 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"reservation-api/payments"
+	"reservation-api/pricing"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Autor de los cambios de estado que hace el módulo de pagos
+const paymentsActor = "payments"
+
+var (
+	paymentGatewayOnce sync.Once
+	paymentGateway     payments.Gateway
+)
+
+// getPaymentGateway crea el proveedor de pagos configurado en PAYMENT_GATEWAY la primera vez que se usa.
+// Por ahora solo existe el proveedor falso; los proveedores reales se agregan en este switch.
+func getPaymentGateway() payments.Gateway {
+	paymentGatewayOnce.Do(func() {
+		switch os.Getenv("PAYMENT_GATEWAY") {
+		default:
+			paymentGateway = payments.NewFakeGateway()
+		}
+	})
+	return paymentGateway
+}
+
+// Intentos de pago que todavía pueden terminar en un cobro
+var activePaymentStatuses = []string{models.PaymentCreated, models.PaymentPending, models.PaymentAuthorized, models.PaymentCaptured}
+
+// CreatePaymentIntent crea el intento de pago de una reserva pendiente por su precio total.
+// Si ya hay un intento activo se devuelve ese.
+func CreatePaymentIntent(reservationID uint) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		reservation, err := lockReservation(tx, reservationID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("reservation_id = ? AND status IN ?", reservationID, activePaymentStatuses).First(&intent).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if reservation.Status != models.StatusPending {
+			return ErrPaymentNotAllowed
+		}
+
+		intent = models.PaymentIntent{
+			ReservationID: reservation.ID,
+			Amount:        reservation.TotalPrice,
+			Currency:      reservation.Currency,
+			Status:        models.PaymentCreated,
+			Provider:      getPaymentGateway().Name(),
+		}
+		return tx.Create(&intent).Error
+	})
+	if err != nil {
+		return nil, paymentError("create payment intent", err)
+	}
+
+	return &intent, nil
+}
+
+// syncPaymentAmount ajusta el intento de pago activo al nuevo precio de la reserva. Un intento que
+// todavía no se envió al proveedor se actualiza; si ya está pendiente, autorizado o cobrado el monto
+// quedó fijo en el proveedor y la reserva no puede cambiar de precio.
+func syncPaymentAmount(tx *gorm.DB, reservation *models.Reservation) error {
+	var intent models.PaymentIntent
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reservation_id = ? AND status IN ?", reservation.ID, activePaymentStatuses).
+		First(&intent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !canReprice(intent.Status, intent.Amount, reservation.TotalPrice) {
+		return ErrPaymentAmountLocked
+	}
+	if intent.Status != models.PaymentCreated {
+		return nil
+	}
+	return tx.Model(&intent).Updates(map[string]interface{}{"amount": reservation.TotalPrice, "currency": reservation.Currency}).Error
+}
+
+// canReprice indica si una reserva con un intento de pago en el estado dado puede pasar a costar total
+func canReprice(status string, amount, total float64) bool {
+	return status == models.PaymentCreated || pricing.Round(amount) == pricing.Round(total)
+}
+
+// GetPaymentIntents obtiene los intentos de pago de la reserva
+func GetPaymentIntents(reservationID uint) ([]models.PaymentIntent, error) {
+	var intents []models.PaymentIntent
+	if err := initializers.DB.Where("reservation_id = ?", reservationID).Order("id").Find(&intents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch payments for reservation %d: %v", reservationID, err)
+	}
+	return intents, nil
+}
+
+// GetPaymentIntent obtiene un intento de pago por su ID
+func GetPaymentIntent(intentID uint) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := initializers.DB.First(&intent, intentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment %d: %v", intentID, err)
+	}
+	return &intent, nil
+}
+
+// GetPaymentOperations obtiene las operaciones hechas sobre el intento de pago en orden
+func GetPaymentOperations(intentID uint) ([]models.PaymentOperation, error) {
+	var operations []models.PaymentOperation
+	if err := initializers.DB.Where("payment_intent_id = ?", intentID).Order("id").Find(&operations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch operations for payment %d: %v", intentID, err)
+	}
+	return operations, nil
+}
+
+// AuthorizePayment autoriza el pago con el medio de pago del usuario. Si el proveedor lo
+// autoriza la reserva queda confirmada y si lo rechaza queda fallida; si el resultado es
+// asíncrono, la reserva sigue pendiente hasta que llegue el webhook.
+func AuthorizePayment(intentID uint, paymentMethod, actor string) (*models.PaymentIntent, error) {
+	var intent *models.PaymentIntent
+	var result error
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		intent, err = lockPaymentIntent(tx, intentID)
+		if err != nil {
+			return err
+		}
+		if intent.Status != models.PaymentCreated {
+			return ErrPaymentNotAllowed
+		}
+
+		// La llamada al proveedor se hace con el intento bloqueado para no autorizar dos veces
+		response, gatewayErr := getPaymentGateway().Authorize(payments.AuthorizeRequest{
+			Reference:     fmt.Sprintf("pi_%d", intent.ID),
+			Amount:        intent.Amount,
+			Currency:      intent.Currency,
+			PaymentMethod: paymentMethod,
+		})
+		if gatewayErr != nil {
+			// Se registra el intento fallido y la transacción se confirma igual
+			result = gatewayError(gatewayErr)
+			return recordPaymentOperation(tx, intent, "authorize", intent.Amount, "", gatewayErr, actor)
+		}
+		if err := recordPaymentOperation(tx, intent, "authorize", intent.Amount, response.Status, nil, actor); err != nil {
+			return err
+		}
+
+		intent.ProviderRef = response.ProviderRef
+		if response.Status == payments.StatusDeclined {
+			result = ErrPaymentDeclined
+		}
+		return applyPaymentStatus(tx, intent, response.Status, response.DeclineReason, 0)
+	})
+	if err != nil {
+		return nil, paymentError("authorize payment", err)
+	}
+	notifyOutbox()
+
+	return intent, result
+}
+
+// CapturePayment cobra el monto indicado de un pago autorizado; 0 cobra todo lo autorizado
+func CapturePayment(intentID uint, amount float64, actor string) (*models.PaymentIntent, error) {
+	return runPaymentOperation(intentID, actor, func(tx *gorm.DB, intent *models.PaymentIntent) error {
+		return capturePayment(tx, intent, amount, "", actor)
+	})
+}
+
+// capturePayment cobra el monto del pago bloqueado dentro de la transacción.
+// reference deduplica la operación en el proveedor si se reintenta; vacía no se deduplica.
+func capturePayment(tx *gorm.DB, intent *models.PaymentIntent, amount float64, reference, actor string) error {
+	if intent.Status != models.PaymentAuthorized {
+		return ErrPaymentNotAllowed
+	}
+	remaining := pricing.Round(intent.Amount - intent.AmountCaptured)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		return ErrInvalidPaymentAmount
+	}
+
+	response, err := getPaymentGateway().Capture(intent.ProviderRef, amount, reference)
+	if err != nil {
+		return gatewayOperationFailed(tx, intent, "capture", amount, err, actor)
+	}
+	if err := recordPaymentOperation(tx, intent, "capture", amount, response.Status, nil, actor); err != nil {
+		return err
+	}
+	return applyPaymentStatus(tx, intent, models.PaymentCaptured, "", amount)
+}
+
+// VoidPayment anula un pago autorizado que todavía no se cobró
+func VoidPayment(intentID uint, actor string) (*models.PaymentIntent, error) {
+	return runPaymentOperation(intentID, actor, func(tx *gorm.DB, intent *models.PaymentIntent) error {
+		return voidPayment(tx, intent, "", actor)
+	})
+}
+
+// voidPayment anula el pago bloqueado dentro de la transacción
+func voidPayment(tx *gorm.DB, intent *models.PaymentIntent, reference, actor string) error {
+	if intent.Status != models.PaymentAuthorized {
+		return ErrPaymentNotAllowed
+	}
+
+	response, err := getPaymentGateway().Void(intent.ProviderRef, reference)
+	if err != nil {
+		return gatewayOperationFailed(tx, intent, "void", 0, err, actor)
+	}
+	if err := recordPaymentOperation(tx, intent, "void", 0, response.Status, nil, actor); err != nil {
+		return err
+	}
+	intent.Status = models.PaymentVoided
+	return tx.Model(intent).Update("status", intent.Status).Error
+}
+
+// RefundPayment devuelve el monto indicado de un pago cobrado; 0 devuelve todo lo cobrado
+func RefundPayment(intentID uint, amount float64, actor string) (*models.PaymentIntent, error) {
+	return runPaymentOperation(intentID, actor, func(tx *gorm.DB, intent *models.PaymentIntent) error {
+		return refundPayment(tx, intent, amount, "", actor)
+	})
+}
+
+// refundPayment devuelve el monto del pago bloqueado dentro de la transacción
+func refundPayment(tx *gorm.DB, intent *models.PaymentIntent, amount float64, reference, actor string) error {
+	if intent.Status != models.PaymentCaptured && intent.Status != models.PaymentPartiallyRefunded {
+		return ErrPaymentNotAllowed
+	}
+	remaining := pricing.Round(intent.AmountCaptured - intent.AmountRefunded)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		return ErrInvalidPaymentAmount
+	}
+
+	response, err := getPaymentGateway().Refund(intent.ProviderRef, amount, reference)
+	if err != nil {
+		return gatewayOperationFailed(tx, intent, "refund", amount, err, actor)
+	}
+	if err := recordPaymentOperation(tx, intent, "refund", amount, response.Status, nil, actor); err != nil {
+		return err
+	}
+
+	intent.AmountRefunded = pricing.Round(intent.AmountRefunded + amount)
+	intent.Status = models.PaymentPartiallyRefunded
+	if intent.AmountRefunded >= intent.AmountCaptured {
+		intent.Status = models.PaymentRefunded
+	}
+	return tx.Model(intent).Select("Status", "AmountRefunded").Updates(intent).Error
+}
+
+// PaymentWebhook es la notificación asíncrona del proveedor sobre un pago
+type PaymentWebhook struct {
+	Event       string  `json:"event"` // payment.authorized, payment.captured o payment.failed
+	ProviderRef string  `json:"provider_ref"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"`
+}
+
+// Eventos de los webhooks de pagos y el estado en que dejan el pago
+var webhookPaymentStatus = map[string]string{
+	"payment.authorized": models.PaymentAuthorized,
+	"payment.captured":   models.PaymentCaptured,
+	"payment.failed":     models.PaymentFailed,
+}
+
+// Estado del pago y resultado de la operación en el proveedor
+var webhookGatewayStatus = map[string]string{
+	models.PaymentAuthorized: payments.StatusAuthorized,
+	models.PaymentCaptured:   payments.StatusCaptured,
+	models.PaymentFailed:     payments.StatusDeclined,
+}
+
+// HandlePaymentWebhook aplica la notificación del proveedor. La firma es el HMAC-SHA256 del
+// cuerpo con PAYMENT_WEBHOOK_SECRET. Un webhook repetido no vuelve a aplicarse.
+func HandlePaymentWebhook(body []byte, signature string) (*models.PaymentIntent, error) {
+	if !payments.VerifySignature(os.Getenv("PAYMENT_WEBHOOK_SECRET"), body, signature) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var webhook PaymentWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil || webhook.ProviderRef == "" {
+		return nil, ErrInvalidWebhook
+	}
+	status, ok := webhookPaymentStatus[webhook.Event]
+	if !ok {
+		return nil, ErrInvalidWebhook
+	}
+
+	var intent models.PaymentIntent
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("provider_ref = ?", webhook.ProviderRef).First(&intent).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPaymentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Un webhook repetido o que llega después de un estado posterior no cambia nada
+		if !canApplyWebhook(intent.Status, status) {
+			return nil
+		}
+
+		// El proveedor falso simula el webhook y tiene que registrar su resultado
+		if listener, ok := getPaymentGateway().(payments.WebhookListener); ok {
+			if err := listener.ApplyWebhook(webhook.ProviderRef, webhookGatewayStatus[status], webhook.Amount); err != nil {
+				return err
+			}
+		}
+
+		if err := recordPaymentOperation(tx, &intent, "webhook", webhook.Amount, status, nil, paymentsActor); err != nil {
+			return err
+		}
+		captured := 0.0
+		if status == models.PaymentCaptured {
+			captured = webhook.Amount
+			if captured == 0 {
+				captured = intent.Amount - intent.AmountCaptured
+			}
+		}
+		return applyPaymentStatus(tx, &intent, status, webhook.Reason, captured)
+	})
+	if err != nil {
+		return nil, paymentError("handle payment webhook", err)
+	}
+	notifyOutbox()
+
+	return &intent, nil
+}
+
+// canApplyWebhook indica si el webhook que lleva el pago al estado to se aplica en el estado actual
+func canApplyWebhook(current, to string) bool {
+	switch to {
+	case models.PaymentAuthorized, models.PaymentFailed:
+		return current == models.PaymentCreated || current == models.PaymentPending
+	case models.PaymentCaptured:
+		return current == models.PaymentCreated || current == models.PaymentPending || current == models.PaymentAuthorized
+	}
+	return false
+}
+
+// applyPaymentStatus deja el pago en el estado del proveedor y mueve la reserva en consecuencia:
+// un pago autorizado o cobrado confirma la reserva pendiente y uno rechazado la deja fallida
+func applyPaymentStatus(tx *gorm.DB, intent *models.PaymentIntent, status, reason string, captured float64) error {
+	switch status {
+	case payments.StatusPending:
+		intent.Status = models.PaymentPending
+	case payments.StatusAuthorized:
+		intent.Status = models.PaymentAuthorized
+	case payments.StatusCaptured:
+		intent.Status = models.PaymentCaptured
+		intent.AmountCaptured = pricing.Round(intent.AmountCaptured + captured)
+	case payments.StatusDeclined, models.PaymentFailed:
+		intent.Status = models.PaymentFailed
+		intent.FailureReason = reason
+	default:
+		return fmt.Errorf("unexpected payment status %q", status)
+	}
+	if err := tx.Model(intent).Select("Status", "ProviderRef", "AmountCaptured", "FailureReason").Updates(intent).Error; err != nil {
+		return err
+	}
+
+	var reservationStatus string
+	switch intent.Status {
+	case models.PaymentAuthorized, models.PaymentCaptured:
+		reservationStatus = models.StatusConfirmed
+	case models.PaymentFailed:
+		reservationStatus = models.StatusFailed
+	default:
+		return nil
+	}
+
+	// Si la reserva ya no está pendiente (por ejemplo, el usuario la canceló) su estado no cambia
+	reservation, err := lockReservation(tx, intent.ReservationID)
+	if err != nil {
+		return err
+	}
+	if !CanTransition(reservation.Status, reservationStatus) {
+		return nil
+	}
+	_, err = transitionReservation(tx, reservation.ID, reservationStatus, paymentsActor, reason)
+	return err
+}
+
+// runPaymentOperation bloquea el intento de pago y ejecuta la operación en una transacción
+func runPaymentOperation(intentID uint, actor string, operation func(tx *gorm.DB, intent *models.PaymentIntent) error) (*models.PaymentIntent, error) {
+	var intent *models.PaymentIntent
+	var result error
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		intent, err = lockPaymentIntent(tx, intentID)
+		if err != nil {
+			return err
+		}
+
+		err = operation(tx, intent)
+		if isGatewayFailure(err) {
+			// La operación fallida ya quedó registrada; se confirma la transacción
+			result = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, paymentError("update payment", err)
+	}
+	notifyOutbox()
+
+	return intent, result
+}
+
+// lockPaymentIntent obtiene el intento de pago bloqueando la fila hasta el final de la transacción
+func lockPaymentIntent(tx *gorm.DB, intentID uint) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&intent, intentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// gatewayOperationFailed registra la operación que el proveedor no pudo hacer y devuelve
+// ErrPaymentRejected si la rechazó o ErrPaymentGatewayUnavailable si no respondió
+func gatewayOperationFailed(tx *gorm.DB, intent *models.PaymentIntent, operation string, amount float64, cause error, actor string) error {
+	if err := recordPaymentOperation(tx, intent, operation, amount, "", cause, actor); err != nil {
+		return err
+	}
+	return gatewayError(cause)
+}
+
+// gatewayError traduce un error del proveedor: un rechazo es definitivo y el resto se puede reintentar
+func gatewayError(cause error) error {
+	var rejected *payments.RejectedError
+	if errors.As(cause, &rejected) {
+		return ErrPaymentRejected
+	}
+	return ErrPaymentGatewayUnavailable
+}
+
+// isGatewayFailure indica si el proveedor no hizo la operación; el intento ya quedó registrado
+func isGatewayFailure(err error) bool {
+	return errors.Is(err, ErrPaymentGatewayUnavailable) || errors.Is(err, ErrPaymentRejected)
+}
+
+// recordPaymentOperation guarda la operación hecha sobre el pago
+func recordPaymentOperation(tx *gorm.DB, intent *models.PaymentIntent, operation string, amount float64, result string, cause error, actor string) error {
+	record := models.PaymentOperation{
+		PaymentIntentID: intent.ID,
+		Operation:       operation,
+		Amount:          amount,
+		Result:          result,
+		Actor:           actor,
+	}
+	if cause != nil {
+		record.Result = "error"
+		record.Error = cause.Error()
+	}
+	return tx.Create(&record).Error
+}
+
+// paymentError devuelve los errores de negocio tal cual y envuelve los inesperados
+func paymentError(action string, err error) error {
+	var reservationErr *ReservationError
+	if errors.As(err, &reservationErr) {
+		return err
+	}
+	return fmt.Errorf("failed to %s: %v", action, err)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reservation-api/models"
+	"reservation-api/payments"
+	"testing"
+)
+
+func TestCanApplyWebhook(t *testing.T) {
+	cases := []struct {
+		current, to string
+		expected    bool
+	}{
+		{models.PaymentPending, models.PaymentAuthorized, true},
+		{models.PaymentPending, models.PaymentFailed, true},
+		{models.PaymentAuthorized, models.PaymentCaptured, true},
+		{models.PaymentAuthorized, models.PaymentAuthorized, false}, // webhook repetido
+		{models.PaymentCaptured, models.PaymentFailed, false},
+		{models.PaymentVoided, models.PaymentCaptured, false},
+	}
+	for _, tc := range cases {
+		if got := canApplyWebhook(tc.current, tc.to); got != tc.expected {
+			t.Errorf("%s -> %s: expected %v, got %v", tc.current, tc.to, tc.expected, got)
+		}
+	}
+}
+
+func TestCanReprice(t *testing.T) {
+	cases := []struct {
+		status        string
+		amount, total float64
+		expected      bool
+	}{
+		{models.PaymentCreated, 100, 150, true}, // todavía no se envió al proveedor
+		{models.PaymentAuthorized, 100, 100, true},
+		{models.PaymentAuthorized, 100, 150, false},
+		{models.PaymentCaptured, 100, 80, false},
+		{models.PaymentPending, 100, 120, false},
+	}
+	for _, tc := range cases {
+		if got := canReprice(tc.status, tc.amount, tc.total); got != tc.expected {
+			t.Errorf("%s %.2f -> %.2f: expected %v, got %v", tc.status, tc.amount, tc.total, tc.expected, got)
+		}
+	}
+}
+
+func TestGatewayError(t *testing.T) {
+	if err := gatewayError(payments.ErrGatewayUnavailable); err != ErrPaymentGatewayUnavailable {
+		t.Errorf("a provider that does not answer should be retryable, got %v", err)
+	}
+	if err := gatewayError(fmt.Errorf("timeout: %w", context.DeadlineExceeded)); err != ErrPaymentGatewayUnavailable {
+		t.Errorf("a timeout should be retryable, got %v", err)
+	}
+	if err := gatewayError(fmt.Errorf("refund: %w", &payments.RejectedError{Reason: "refund exceeds captured amount"})); err != ErrPaymentRejected {
+		t.Errorf("a rejection should be final, got %v", err)
+	}
+}
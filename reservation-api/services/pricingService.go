@@ -0,0 +1,348 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"reservation-api/pricing"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Moneda de los precios cuando no se configura CURRENCY
+const defaultCurrency = "USD"
+
+// currency devuelve la moneda en la que se cotizan las reservas
+func currency() string {
+	if value := os.Getenv("CURRENCY"); value != "" {
+		return strings.ToUpper(value)
+	}
+	return defaultCurrency
+}
+
+// parseOptionalDate convierte una fecha YYYY-MM-DD opcional a medianoche UTC, como las noches de
+// nights; la conexión usa loc=UTC, así que la columna type:date guarda ese mismo día.
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// CreateRateRule crea una tarifa por temporada o día de la semana para el tipo de habitación
+func CreateRateRule(roomTypeID uint, ruleDto dto.RateRuleDTO) (*models.RateRule, error) {
+	var roomType models.RoomType
+	if err := initializers.DB.First(&roomType, roomTypeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoomTypeNotFound
+		}
+		return nil, err
+	}
+
+	startDate, err := parseOptionalDate(ruleDto.StartDate)
+	if err != nil {
+		return nil, ErrInvalidPricingRule
+	}
+	endDate, err := parseOptionalDate(ruleDto.EndDate)
+	if err != nil {
+		return nil, ErrInvalidPricingRule
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return nil, ErrInvalidPricingRule
+	}
+	if err := checkCancellationPolicy(initializers.DB, roomType.HotelID, ruleDto.CancellationPolicyID); err != nil {
+		return nil, err
+	}
+
+	days := make([]string, len(ruleDto.DaysOfWeek))
+	for i, day := range ruleDto.DaysOfWeek {
+		days[i] = strconv.Itoa(day)
+	}
+
+	rule := models.RateRule{
+		RoomTypeID: roomTypeID,
+		Name:       ruleDto.Name,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		DaysOfWeek: strings.Join(days, ","),
+		Rate:       ruleDto.Rate,
+		Priority:   ruleDto.Priority,
+
+		CancellationPolicyID: ruleDto.CancellationPolicyID,
+	}
+	if err := initializers.DB.Create(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create rate rule: %v", err)
+	}
+	return &rule, nil
+}
+
+// GetRateRules obtiene las tarifas del tipo de habitación
+func GetRateRules(roomTypeID uint) ([]models.RateRule, error) {
+	var rules []models.RateRule
+	if err := initializers.DB.Where("room_type_id = ?", roomTypeID).Order("priority desc, id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch rate rules: %v", err)
+	}
+	return rules, nil
+}
+
+// CreateCancellationPolicy crea una política de cancelación para las tarifas del hotel
+func CreateCancellationPolicy(hotelID string, policyDto dto.CancellationPolicyDTO) (*models.CancellationPolicy, error) {
+	policy := models.CancellationPolicy{
+		HotelID:        hotelID,
+		Name:           policyDto.Name,
+		Refundable:     policyDto.Refundable,
+		FreeDaysBefore: policyDto.FreeDaysBefore,
+		PenaltyPercent: policyDto.PenaltyPercent,
+	}
+	if err := initializers.DB.Create(&policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to create cancellation policy: %v", err)
+	}
+	return &policy, nil
+}
+
+// GetCancellationPolicies obtiene las políticas de cancelación del hotel
+func GetCancellationPolicies(hotelID string) ([]models.CancellationPolicy, error) {
+	var policies []models.CancellationPolicy
+	if err := initializers.DB.Where("hotel_id = ?", hotelID).Order("id").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch cancellation policies: %v", err)
+	}
+	return policies, nil
+}
+
+// checkCancellationPolicy verifica que la política indicada, si hay una, sea del hotel
+func checkCancellationPolicy(db *gorm.DB, hotelID string, policyID *uint) error {
+	if policyID == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&models.CancellationPolicy{}).Where("id = ? AND hotel_id = ?", *policyID, hotelID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCancellationPolicyNotFound
+	}
+	return nil
+}
+
+// CreateStayDiscount crea un descuento por cantidad de noches para el hotel
+func CreateStayDiscount(hotelID string, discountDto dto.StayDiscountDTO) (*models.StayDiscount, error) {
+	discount := models.StayDiscount{
+		HotelID:   hotelID,
+		MinNights: discountDto.MinNights,
+		Percent:   discountDto.Percent,
+	}
+	if err := initializers.DB.Create(&discount).Error; err != nil {
+		return nil, fmt.Errorf("failed to create stay discount: %v", err)
+	}
+	return &discount, nil
+}
+
+// GetStayDiscounts obtiene los descuentos por cantidad de noches del hotel
+func GetStayDiscounts(hotelID string) ([]models.StayDiscount, error) {
+	var discounts []models.StayDiscount
+	if err := initializers.DB.Where("hotel_id = ?", hotelID).Order("min_nights").Find(&discounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch stay discounts: %v", err)
+	}
+	return discounts, nil
+}
+
+// CreateHotelTax crea un impuesto para el hotel
+func CreateHotelTax(hotelID string, taxDto dto.HotelTaxDTO) (*models.HotelTax, error) {
+	if !pricing.IsValidTaxKind(taxDto.Kind) {
+		return nil, ErrInvalidPricingRule
+	}
+
+	tax := models.HotelTax{
+		HotelID: hotelID,
+		Name:    taxDto.Name,
+		Kind:    taxDto.Kind,
+		Amount:  taxDto.Amount,
+	}
+	if err := initializers.DB.Create(&tax).Error; err != nil {
+		return nil, fmt.Errorf("failed to create hotel tax: %v", err)
+	}
+	return &tax, nil
+}
+
+// GetHotelTaxes obtiene los impuestos del hotel
+func GetHotelTaxes(hotelID string) ([]models.HotelTax, error) {
+	var taxes []models.HotelTax
+	if err := initializers.DB.Where("hotel_id = ?", hotelID).Order("id").Find(&taxes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch hotel taxes: %v", err)
+	}
+	return taxes, nil
+}
+
+// DeletePricingRule elimina una tarifa ("rates"), un descuento ("discounts") o un impuesto ("taxes")
+func DeletePricingRule(kind string, id uint) error {
+	var model interface{}
+	switch kind {
+	case "rates":
+		model = &models.RateRule{}
+	case "discounts":
+		model = &models.StayDiscount{}
+	case "taxes":
+		model = &models.HotelTax{}
+	default:
+		return ErrPricingRuleNotFound
+	}
+
+	result := initializers.DB.Delete(model, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete pricing rule: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPricingRuleNotFound
+	}
+	return nil
+}
+
+// toPricingRule convierte una tarifa guardada en una regla del motor de precios
+func toPricingRule(rule models.RateRule) pricing.Rule {
+	pricingRule := pricing.Rule{
+		Name:      rule.Name,
+		StartDate: rule.StartDate,
+		EndDate:   rule.EndDate,
+		Rate:      rule.Rate,
+		Priority:  rule.Priority,
+	}
+	for _, value := range strings.Split(rule.DaysOfWeek, ",") {
+		if day, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			pricingRule.Days = append(pricingRule.Days, time.Weekday(day))
+		}
+	}
+	return pricingRule
+}
+
+// toPricingPolicy convierte una política guardada en una política del motor de precios
+func toPricingPolicy(policy models.CancellationPolicy) *pricing.CancellationPolicy {
+	return &pricing.CancellationPolicy{
+		Name:           policy.Name,
+		Refundable:     policy.Refundable,
+		FreeDaysBefore: policy.FreeDaysBefore,
+		PenaltyPercent: policy.PenaltyPercent,
+	}
+}
+
+// quoteRoomType cotiza la estadía en el tipo de habitación usando db, que puede ser una transacción.
+// promo es el código promocional ya validado o nil.
+func quoteRoomType(db *gorm.DB, roomType models.RoomType, checkIn, checkOut time.Time, guests int, promo *pricing.Promo) (pricing.Quote, error) {
+	var rules []models.RateRule
+	if err := db.Where("room_type_id = ?", roomType.ID).Order("id").Find(&rules).Error; err != nil {
+		return pricing.Quote{}, err
+	}
+	var discounts []models.StayDiscount
+	if err := db.Where("hotel_id = ?", roomType.HotelID).Find(&discounts).Error; err != nil {
+		return pricing.Quote{}, err
+	}
+	var taxes []models.HotelTax
+	if err := db.Where("hotel_id = ?", roomType.HotelID).Order("id").Find(&taxes).Error; err != nil {
+		return pricing.Quote{}, err
+	}
+	var policies []models.CancellationPolicy
+	if err := db.Where("hotel_id = ?", roomType.HotelID).Find(&policies).Error; err != nil {
+		return pricing.Quote{}, err
+	}
+	policyByID := make(map[uint]*pricing.CancellationPolicy, len(policies))
+	for _, policy := range policies {
+		policyByID[policy.ID] = toPricingPolicy(policy)
+	}
+
+	input := pricing.Input{
+		BaseRate: roomType.BaseRate,
+		Nights:   nights(checkIn, checkOut),
+		Guests:   guests,
+		Currency: currency(),
+		Promo:    promo,
+	}
+	if roomType.CancellationPolicyID != nil {
+		input.BasePolicy = policyByID[*roomType.CancellationPolicyID]
+	}
+	for _, rule := range rules {
+		pricingRule := toPricingRule(rule)
+		if rule.CancellationPolicyID != nil {
+			pricingRule.Policy = policyByID[*rule.CancellationPolicyID]
+		}
+		input.Rules = append(input.Rules, pricingRule)
+	}
+	for _, discount := range discounts {
+		input.Discounts = append(input.Discounts, pricing.Discount{MinNights: discount.MinNights, Percent: discount.Percent})
+	}
+	for _, tax := range taxes {
+		input.Taxes = append(input.Taxes, pricing.Tax{Name: tax.Name, Kind: tax.Kind, Amount: tax.Amount})
+	}
+
+	return pricing.Calculate(input), nil
+}
+
+// QuoteStay cotiza la estadía en el hotel. Si roomTypeID es 0 se cotizan todos los tipos
+// de habitación donde entran los huéspedes.
+func QuoteStay(hotelID string, roomTypeID uint, checkIn, checkOut time.Time, guests int) ([]dto.RoomTypeQuoteDTO, error) {
+	if len(nights(checkIn, checkOut)) == 0 {
+		return nil, ErrInvalidDateRange
+	}
+
+	query := initializers.DB.Where("hotel_id = ?", hotelID)
+	if roomTypeID != 0 {
+		query = query.Where("id = ?", roomTypeID)
+	}
+	var roomTypes []models.RoomType
+	if err := query.Order("id").Find(&roomTypes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch room types for hotel %s: %v", hotelID, err)
+	}
+	if roomTypeID != 0 && len(roomTypes) == 0 {
+		return nil, ErrRoomTypeNotFound
+	}
+
+	quotes := []dto.RoomTypeQuoteDTO{}
+	for _, roomType := range roomTypes {
+		if roomType.Capacity < guests {
+			if roomTypeID != 0 {
+				return nil, ErrRoomTypeCapacity
+			}
+			continue
+		}
+
+		quote, err := quoteRoomType(initializers.DB, roomType, checkIn, checkOut, guests, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to quote room type %d: %v", roomType.ID, err)
+		}
+		quotes = append(quotes, dto.RoomTypeQuoteDTO{RoomTypeID: roomType.ID, Name: roomType.Name, Quote: quote})
+	}
+
+	return quotes, nil
+}
+
+// applyQuote guarda en la reserva el precio cotizado
+func applyQuote(reservation *models.Reservation, quote pricing.Quote) error {
+	breakdown, err := json.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	policy, err := json.Marshal(quote.CancellationPolicy)
+	if err != nil {
+		return err
+	}
+
+	reservation.Currency = quote.Currency
+	reservation.Subtotal = quote.Subtotal
+	reservation.DiscountAmount = quote.Discount
+	reservation.TaxAmount = quote.TaxTotal
+	reservation.TotalPrice = quote.Total
+	reservation.PriceBreakdown = breakdown
+	reservation.CancellationPolicy = policy
+	reservation.PromoCode = quote.PromoCode
+	reservation.PromoDiscount = quote.PromoDiscount
+	return nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"reservation-api/models"
+	"testing"
+	"time"
+)
+
+// withLocalZone cambia time.Local durante el test
+func withLocalZone(t *testing.T, zone *time.Location) {
+	t.Helper()
+	previous := time.Local
+	time.Local = zone
+	t.Cleanup(func() { time.Local = previous })
+}
+
+func TestToPricingRule(t *testing.T) {
+	// Las fechas de la tarifa no dependen de la zona del servidor
+	withLocalZone(t, time.FixedZone("UTC+3", 3*60*60))
+	start, err := parseOptionalDate("2030-07-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, _ := parseOptionalDate("2030-07-31")
+
+	rule := toPricingRule(models.RateRule{Name: "winter", StartDate: start, EndDate: end, Rate: 150, DaysOfWeek: "5, 6"})
+
+	if !rule.StartDate.Equal(time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC)) || !rule.EndDate.Equal(time.Date(2030, 7, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected UTC midnights, got %s - %s", rule.StartDate, rule.EndDate)
+	}
+	if len(rule.Days) != 2 || rule.Days[0] != time.Friday || rule.Days[1] != time.Saturday {
+		t.Errorf("unexpected days: %v", rule.Days)
+	}
+
+	// Sin fechas la regla no se limita
+	if open := toPricingRule(models.RateRule{Rate: 100}); open.StartDate != nil || open.EndDate != nil {
+		t.Errorf("expected an open rule, got %+v", open)
+	}
+}
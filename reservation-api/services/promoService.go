@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"reservation-api/pricing"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// normalizePromoCode pasa el código a mayúsculas para que no importe cómo lo escribe el usuario
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreatePromoCode crea un código promocional
+func CreatePromoCode(promoDto dto.PromoCodeDTO) (*models.PromoCode, error) {
+	code := normalizePromoCode(promoDto.Code)
+	if code == "" || !pricing.IsValidPromoKind(promoDto.Kind) {
+		return nil, ErrInvalidPromoCode
+	}
+	if promoDto.Kind == pricing.PromoPercent && promoDto.Amount > 100 {
+		return nil, ErrInvalidPromoCode
+	}
+
+	validFrom, err := parseOptionalDate(promoDto.ValidFrom)
+	if err != nil {
+		return nil, ErrInvalidPromoCode
+	}
+	validUntil, err := parseOptionalDate(promoDto.ValidUntil)
+	if err != nil {
+		return nil, ErrInvalidPromoCode
+	}
+	if validFrom != nil && validUntil != nil && validUntil.Before(*validFrom) {
+		return nil, ErrInvalidPromoCode
+	}
+
+	var count int64
+	if err := initializers.DB.Model(&models.PromoCode{}).Where("code = ?", code).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check promo code: %v", err)
+	}
+	if count > 0 {
+		return nil, ErrPromoCodeExists
+	}
+
+	var hotelIDs []string
+	for _, hotelID := range promoDto.HotelIDs {
+		if hotelID = strings.TrimSpace(hotelID); hotelID != "" {
+			hotelIDs = append(hotelIDs, hotelID)
+		}
+	}
+
+	promo := models.PromoCode{
+		Code:           code,
+		Kind:           promoDto.Kind,
+		Amount:         promoDto.Amount,
+		ValidFrom:      validFrom,
+		ValidUntil:     validUntil,
+		HotelIDs:       strings.Join(hotelIDs, ","),
+		MinNights:      promoDto.MinNights,
+		MaxUses:        promoDto.MaxUses,
+		MaxUsesPerUser: promoDto.MaxUsesPerUser,
+		Stackable:      promoDto.Stackable,
+		Active:         true,
+	}
+	if err := initializers.DB.Create(&promo).Error; err != nil {
+		return nil, fmt.Errorf("failed to create promo code: %v", err)
+	}
+	return &promo, nil
+}
+
+// GetPromoCodes obtiene todos los códigos promocionales
+func GetPromoCodes() ([]models.PromoCode, error) {
+	var promos []models.PromoCode
+	if err := initializers.DB.Order("id").Find(&promos).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch promo codes: %v", err)
+	}
+	return promos, nil
+}
+
+// DeactivatePromoCode desactiva el código para que no se pueda usar en reservas nuevas.
+// Las reservas que ya lo usaron conservan el descuento.
+func DeactivatePromoCode(promoID uint) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := initializers.DB.First(&promo, promoID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch promo code %d: %v", promoID, err)
+	}
+
+	promo.Active = false
+	if err := initializers.DB.Model(&promo).Update("active", false).Error; err != nil {
+		return nil, fmt.Errorf("failed to deactivate promo code %d: %v", promoID, err)
+	}
+	return &promo, nil
+}
+
+// checkPromoStay verifica que el código valga para una estadía de stayNights noches en el hotel
+func checkPromoStay(promo models.PromoCode, hotelID string, stayNights int) error {
+	if promo.HotelIDs != "" {
+		eligible := false
+		for _, eligibleHotel := range strings.Split(promo.HotelIDs, ",") {
+			if eligibleHotel == hotelID {
+				eligible = true
+				break
+			}
+		}
+		if !eligible {
+			return ErrPromoNotEligible
+		}
+	}
+	if stayNights < promo.MinNights {
+		return ErrPromoNotEligible
+	}
+	return nil
+}
+
+// checkPromoCode verifica que el código se pueda usar en now para una estadía de stayNights
+// noches en el hotel, sabiendo cuántas veces ya lo usó el usuario
+func checkPromoCode(promo models.PromoCode, hotelID string, stayNights int, userUses int64, now time.Time) error {
+	// Se comparan días de calendario: las fechas de validez son medianoches UTC
+	today := nights(now, now.AddDate(0, 0, 1))[0]
+	if !promo.Active || (promo.ValidFrom != nil && today.Before(*promo.ValidFrom)) || (promo.ValidUntil != nil && today.After(*promo.ValidUntil)) {
+		return ErrPromoCodeExpired
+	}
+	if err := checkPromoStay(promo, hotelID, stayNights); err != nil {
+		return err
+	}
+
+	if promo.MaxUses > 0 && promo.UsedCount >= promo.MaxUses {
+		return ErrPromoUsageExceeded
+	}
+	if promo.MaxUsesPerUser > 0 && userUses >= int64(promo.MaxUsesPerUser) {
+		return ErrPromoUsageExceeded
+	}
+	return nil
+}
+
+// lockPromoForReservation obtiene el código bloqueando la fila hasta el final de la transacción y
+// verifica que la reserva lo pueda usar. Con la fila bloqueada dos reservas simultáneas no pueden
+// superar los límites de uso.
+func lockPromoForReservation(tx *gorm.DB, code string, reservation *models.Reservation) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", normalizePromoCode(code)).First(&promo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var userUses int64
+	err = tx.Model(&models.PromoRedemption{}).
+		Where("promo_code_id = ? AND user_id = ? AND released_at IS NULL", promo.ID, reservation.UserID).
+		Count(&userUses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stayNights := len(nights(reservation.FechaDesde, reservation.FechaHasta))
+	if err := checkPromoCode(promo, reservation.HotelID, stayNights, userUses, time.Now()); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// toPricingPromo convierte un código guardado en un código del motor de precios
+func toPricingPromo(promo *models.PromoCode) *pricing.Promo {
+	if promo == nil {
+		return nil
+	}
+	return &pricing.Promo{Code: promo.Code, Kind: promo.Kind, Amount: promo.Amount, Stackable: promo.Stackable}
+}
+
+// redeemPromoCode registra el uso del código en la reserva ya guardada dentro de la transacción
+func redeemPromoCode(tx *gorm.DB, promo *models.PromoCode, reservation *models.Reservation) error {
+	err := tx.Create(&models.PromoRedemption{
+		PromoCodeID:   promo.ID,
+		UserID:        reservation.UserID,
+		ReservationID: reservation.ID,
+		Discount:      reservation.PromoDiscount,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.PromoCode{}).Where("id = ?", promo.ID).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+// reservationPromo devuelve el código que usa la reserva si la nueva estadía todavía cumple sus
+// condiciones de hotel y noches. La vigencia y los límites de uso se verificaron al reservar.
+func reservationPromo(tx *gorm.DB, reservation *models.Reservation, stayNights int) (*models.PromoCode, error) {
+	if reservation.PromoCode == "" {
+		return nil, nil
+	}
+
+	var promo models.PromoCode
+	err := tx.Where("code = ?", reservation.PromoCode).First(&promo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if checkPromoStay(promo, reservation.HotelID, stayNights) != nil {
+		return nil, nil
+	}
+	return &promo, nil
+}
+
+// syncPromoRedemption actualiza el uso del código después de volver a cotizar la reserva:
+// guarda el nuevo descuento o, si el código ya no se aplica, devuelve el uso
+func syncPromoRedemption(tx *gorm.DB, reservation *models.Reservation) error {
+	if reservation.PromoCode == "" {
+		return releasePromoRedemption(tx, reservation.ID, time.Now())
+	}
+	return tx.Model(&models.PromoRedemption{}).
+		Where("reservation_id = ? AND released_at IS NULL", reservation.ID).
+		Update("discount", reservation.PromoDiscount).Error
+}
+
+// releasePromoRedemption devuelve el uso del código de la reserva, si tiene uno, para que
+// vuelva a estar disponible
+func releasePromoRedemption(tx *gorm.DB, reservationID uint, now time.Time) error {
+	var redemption models.PromoRedemption
+	err := tx.Where("reservation_id = ? AND released_at IS NULL", reservationID).First(&redemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&redemption).Update("released_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.PromoCode{}).
+		Where("id = ? AND used_count > 0", redemption.PromoCodeID).
+		UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+}
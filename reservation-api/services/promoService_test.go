@@ -0,0 +1,55 @@
+package services
+
+import (
+	"reservation-api/models"
+	"testing"
+	"time"
+)
+
+func TestCheckPromoCode(t *testing.T) {
+	now := time.Date(2030, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		date := time.Date(2030, 3, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	promo := models.PromoCode{
+		Code:           "SPRING",
+		ValidFrom:      day(1),
+		ValidUntil:     day(10),
+		HotelIDs:       "hotel-a,hotel-b",
+		MinNights:      2,
+		MaxUses:        100,
+		MaxUsesPerUser: 1,
+		UsedCount:      10,
+		Active:         true,
+	}
+
+	cases := []struct {
+		name     string
+		change   func(p *models.PromoCode)
+		hotelID  string
+		nights   int
+		userUses int64
+		expected error
+	}{
+		{"valid on the last day", func(p *models.PromoCode) {}, "hotel-b", 2, 0, nil},
+		{"inactive", func(p *models.PromoCode) { p.Active = false }, "hotel-a", 2, 0, ErrPromoCodeExpired},
+		{"not started", func(p *models.PromoCode) { p.ValidFrom = day(11) }, "hotel-a", 2, 0, ErrPromoCodeExpired},
+		{"expired", func(p *models.PromoCode) { p.ValidUntil = day(9) }, "hotel-a", 2, 0, ErrPromoCodeExpired},
+		{"valid on the first day", func(p *models.PromoCode) { p.ValidFrom = day(10) }, "hotel-a", 2, 0, nil},
+		{"other hotel", func(p *models.PromoCode) {}, "hotel-c", 2, 0, ErrPromoNotEligible},
+		{"any hotel", func(p *models.PromoCode) { p.HotelIDs = "" }, "hotel-c", 2, 0, nil},
+		{"too short", func(p *models.PromoCode) {}, "hotel-a", 1, 0, ErrPromoNotEligible},
+		{"global limit", func(p *models.PromoCode) { p.UsedCount = 100 }, "hotel-a", 2, 0, ErrPromoUsageExceeded},
+		{"user limit", func(p *models.PromoCode) {}, "hotel-a", 2, 1, ErrPromoUsageExceeded},
+		{"no limits", func(p *models.PromoCode) { p.MaxUses, p.MaxUsesPerUser = 0, 0 }, "hotel-a", 2, 5, nil},
+	}
+
+	for _, c := range cases {
+		p := promo
+		c.change(&p)
+		if err := checkPromoCode(p, c.hotelID, c.nights, c.userUses, now); err != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, err)
+		}
+	}
+}
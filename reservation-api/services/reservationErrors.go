@@ -0,0 +1,98 @@
+package services
+
+// ReservationError es un error de negocio de las reservas. Code identifica el
+// motivo para que el controlador elija el código HTTP y el cliente pueda distinguirlo.
+type ReservationError struct {
+	Code    string
+	Message string
+}
+
+func (e *ReservationError) Error() string {
+	return e.Message
+}
+
+// Errores de validación de fechas
+var (
+	ErrInvalidDateRange = &ReservationError{Code: "invalid_date_range", Message: "check-out must be after check-in"}
+	ErrDateInPast       = &ReservationError{Code: "date_in_past", Message: "check-in cannot be in the past"}
+	ErrStayTooLong      = &ReservationError{Code: "stay_too_long", Message: "stay exceeds the maximum number of nights"}
+	ErrLeadTimeTooShort = &ReservationError{Code: "lead_time_too_short", Message: "check-in is too soon to be booked"}
+	ErrBookingTooFar    = &ReservationError{Code: "booking_too_far_ahead", Message: "check-in is too far in the future to be booked"}
+)
+
+// Errores de las entidades relacionadas con la reserva
+var (
+	ErrHotelNotFound          = &ReservationError{Code: "hotel_not_found", Message: "hotel not found"}
+	ErrHotelLookupUnavailable = &ReservationError{Code: "hotel_lookup_unavailable", Message: "hotel could not be verified, try again later"}
+	ErrUserNotFound           = &ReservationError{Code: "user_not_found", Message: "user not found"}
+	ErrUserLookupUnavailable  = &ReservationError{Code: "user_lookup_unavailable", Message: "user could not be verified, try again later"}
+)
+
+// Errores del ciclo de vida de la reserva
+var (
+	ErrReservationNotFound = &ReservationError{Code: "reservation_not_found", Message: "reservation not found"}
+	ErrIllegalTransition   = &ReservationError{Code: "illegal_transition", Message: "reservation cannot change to the requested status"}
+	ErrNotModifiable       = &ReservationError{Code: "reservation_not_modifiable", Message: "only pending or confirmed reservations can be modified"}
+)
+
+// Errores del inventario de habitaciones
+var (
+	ErrRoomTypeNotFound = &ReservationError{Code: "room_type_not_found", Message: "room type not found"}
+	ErrRoomTypeCapacity = &ReservationError{Code: "room_type_capacity_exceeded", Message: "room type capacity exceeded"}
+	ErrNoAvailability   = &ReservationError{Code: "no_availability", Message: "no rooms available for the selected dates"}
+	ErrInventoryInUse   = &ReservationError{Code: "inventory_in_use", Message: "room count is lower than the rooms already reserved"}
+)
+
+// Errores de los bloqueos temporales de habitación
+var (
+	ErrHoldNotFound = &ReservationError{Code: "hold_not_found", Message: "hold not found"}
+	ErrHoldExpired  = &ReservationError{Code: "hold_expired", Message: "hold has expired or was already used"}
+	ErrHoldMismatch = &ReservationError{Code: "hold_mismatch", Message: "reservation does not match the held room and dates"}
+
+	ErrHoldLimitReached = &ReservationError{Code: "hold_limit_reached", Message: "you already have the maximum number of active holds"}
+)
+
+// Errores de la lista de espera
+var (
+	ErrWaitlistEntryNotFound = &ReservationError{Code: "waitlist_entry_not_found", Message: "waitlist entry not found"}
+	ErrRoomsAvailable        = &ReservationError{Code: "rooms_available", Message: "rooms are available for the selected dates, book directly"}
+	ErrWaitlistEntryClosed   = &ReservationError{Code: "waitlist_entry_closed", Message: "waitlist entry is no longer waiting"}
+	ErrWaitlistEntryExists   = &ReservationError{Code: "waitlist_entry_exists", Message: "you are already on the waitlist for this room type and dates"}
+)
+
+// Errores de pagos
+var (
+	ErrPaymentNotFound           = &ReservationError{Code: "payment_not_found", Message: "payment not found"}
+	ErrPaymentNotAllowed         = &ReservationError{Code: "payment_operation_not_allowed", Message: "operation is not allowed in the current payment status"}
+	ErrPaymentDeclined           = &ReservationError{Code: "payment_declined", Message: "payment was declined"}
+	ErrPaymentGatewayUnavailable = &ReservationError{Code: "payment_gateway_unavailable", Message: "payment provider is not available, try again later"}
+	ErrPaymentRejected           = &ReservationError{Code: "payment_operation_rejected", Message: "payment provider rejected the operation"}
+	ErrInvalidPaymentAmount      = &ReservationError{Code: "invalid_payment_amount", Message: "amount exceeds what can be captured or refunded"}
+	ErrInvalidWebhookSignature   = &ReservationError{Code: "invalid_webhook_signature", Message: "webhook signature is not valid"}
+	ErrInvalidWebhook            = &ReservationError{Code: "invalid_webhook", Message: "webhook payload is not valid"}
+	ErrPaymentAmountLocked       = &ReservationError{Code: "payment_amount_locked", Message: "the new price differs from the payment already authorized or captured, cancel and book again"}
+)
+
+// Errores de las sagas de reserva
+var (
+	ErrSagaNotFound = &ReservationError{Code: "saga_not_found", Message: "booking saga not found"}
+)
+
+// Errores de precios
+var (
+	ErrInvalidPricingRule  = &ReservationError{Code: "invalid_pricing_rule", Message: "pricing rule is not valid"}
+	ErrPricingRuleNotFound = &ReservationError{Code: "pricing_rule_not_found", Message: "pricing rule not found"}
+
+	ErrCancellationPolicyNotFound = &ReservationError{Code: "cancellation_policy_not_found", Message: "cancellation policy not found for this hotel"}
+)
+
+// Errores de los códigos promocionales
+var (
+	ErrInvalidPromoCode   = &ReservationError{Code: "invalid_promo_code", Message: "promo code is not valid"}
+	ErrPromoCodeExists    = &ReservationError{Code: "promo_code_exists", Message: "a promo code with this code already exists"}
+	ErrPromoCodeNotFound  = &ReservationError{Code: "promo_code_not_found", Message: "promo code not found"}
+	ErrPromoCodeExpired   = &ReservationError{Code: "promo_code_expired", Message: "promo code is not active at this time"}
+	ErrPromoNotEligible   = &ReservationError{Code: "promo_code_not_eligible", Message: "promo code does not apply to this hotel or stay"}
+	ErrPromoUsageExceeded = &ReservationError{Code: "promo_code_usage_exceeded", Message: "promo code has reached its usage limit"}
+	ErrPromoNotCombinable = &ReservationError{Code: "promo_code_not_combinable", Message: "promo code cannot be combined with the stay discount, which is already better"}
+)
@@ -3,11 +3,15 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"reservation-api/dto"
 	"reservation-api/initializers"
 	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // CheckUserExists verifica si un usuario existe en la user-api
@@ -28,20 +32,110 @@ func CheckUserExists(userID uint) (bool, error) {
 	return false, fmt.Errorf("unexpected response from user API: %d", resp.StatusCode)
 }
 
-// CreateReservation crea una nueva reserva
+// CreateReservation crea una nueva reserva tomando una habitación del tipo elegido
+// para cada noche. Si alguna noche está completa no se crea nada. Si se indica un
+// bloqueo vigente del usuario se usa la habitación bloqueada. Con un código promocional
+// se valida y se cuenta su uso en la misma transacción.
 func CreateReservation(reservationDto dto.ReservationDTO) (*models.Reservation, error) {
-	// Crear la reserva directamente sin verificar el hotel
+	if err := ValidateReservation(reservationDto); err != nil {
+		return nil, err
+	}
+
+	var reservation *models.Reservation
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = createReservation(tx, reservationDto)
+		return err
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create reservation: %v", err)
+	}
+	notifyOutbox()
+
+	return reservation, nil
+}
+
+// createReservation toma el inventario y guarda la reserva pendiente dentro de la transacción.
+// La reserva ya tiene que estar validada.
+func createReservation(tx *gorm.DB, reservationDto dto.ReservationDTO) (*models.Reservation, error) {
+	guests := reservationDto.Guests
+	if guests == 0 {
+		guests = 1
+	}
+
 	reservation := models.Reservation{
 		UserID:     reservationDto.UserID,
 		HotelID:    reservationDto.HotelID,
+		RoomTypeID: reservationDto.RoomTypeID,
+		Guests:     guests,
 		FechaDesde: reservationDto.FechaDesde,
 		FechaHasta: reservationDto.FechaHasta,
+		Status:     models.StatusPending,
 	}
 
-	if err := initializers.DB.Create(&reservation).Error; err != nil {
-		return nil, fmt.Errorf("failed to create reservation: %v", err)
+	roomType, err := roomTypeForStay(tx, reservation.HotelID, reservation.RoomTypeID, guests)
+	if err != nil {
+		return nil, err
+	}
+
+	// Con un bloqueo vigente la habitación ya está tomada en el inventario
+	var hold *models.RoomHold
+	if reservationDto.HoldToken != "" {
+		hold, err = lockHoldForReservation(tx, reservationDto.HoldToken, &reservation)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := reserveInventory(tx, roomType, reservation.FechaDesde, reservation.FechaHasta); err != nil {
+		return nil, err
+	}
+
+	// Validar el código con su fila bloqueada para que las reservas simultáneas no superen los límites de uso
+	var promo *models.PromoCode
+	if reservationDto.PromoCode != "" {
+		promo, err = lockPromoForReservation(tx, reservationDto.PromoCode, &reservation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Guardar el precio vigente para que un cambio de tarifas no altere la reserva
+	quote, err := quoteRoomType(tx, roomType, reservation.FechaDesde, reservation.FechaHasta, guests, toPricingPromo(promo))
+	if err != nil {
+		return nil, err
+	}
+	if promo != nil && quote.PromoCode == "" {
+		return nil, ErrPromoNotCombinable
+	}
+	if err := applyQuote(&reservation, quote); err != nil {
+		return nil, err
+	}
+
+	reservation.StatusChangedAt = time.Now()
+	if err := tx.Create(&reservation).Error; err != nil {
+		return nil, err
+	}
+	if hold != nil {
+		if err := convertHold(tx, hold, reservation.ID); err != nil {
+			return nil, err
+		}
+	}
+	if promo != nil {
+		if err := redeemPromoCode(tx, promo, &reservation); err != nil {
+			return nil, err
+		}
 	}
 
+	actor := UserActor(reservation.UserID)
+	if err := recordStatusChange(tx, &reservation, "", actor, ""); err != nil {
+		return nil, err
+	}
+	if err := enqueueReservationEvent(tx, models.ReservationCreatedEvent, &reservation, "", actor); err != nil {
+		return nil, err
+	}
 	return &reservation, nil
 }
 
@@ -55,6 +149,19 @@ func GetAllReservations() ([]models.Reservation, error) {
 	return reservations, nil
 }
 
+// GetReservation obtiene una reserva por su ID
+func GetReservation(reservationID uint) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := initializers.DB.First(&reservation, reservationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservation %d: %v", reservationID, err)
+	}
+	return &reservation, nil
+}
+
 // GetReservationsByUser obtiene todas las reservas de un usuario por su ID
 func GetReservationsByUser(userID uint) ([]models.Reservation, error) {
 	var reservations []models.Reservation
@@ -66,21 +173,41 @@ func GetReservationsByUser(userID uint) ([]models.Reservation, error) {
 	return reservations, nil
 }
 
-// CancelReservation cancela una reserva por su ID
-func CancelReservation(reservationID uint) error {
-	var reservation models.Reservation
+// GetHotelAvailability calcula cuántas habitaciones de cada tipo quedan libres en el hotel
+// para todas las noches entre checkIn (inclusive) y checkOut (exclusive).
+// Solo se consideran los tipos de habitación donde entran los huéspedes.
+func GetHotelAvailability(hotelID string, checkIn, checkOut time.Time, guests int) ([]dto.RoomTypeAvailabilityDTO, error) {
+	roomTypes, err := GetRoomTypesByHotel(hotelID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Verificar si la reserva existe
-	result := initializers.DB.Where("id = ?", reservationID).First(&reservation)
-	if result.Error != nil {
-		return fmt.Errorf("reservation not found")
+	dates := nights(checkIn, checkOut)
+	availability := []dto.RoomTypeAvailabilityDTO{}
+	if len(dates) == 0 {
+		return availability, nil
 	}
+	for _, roomType := range roomTypes {
+		if roomType.Capacity < guests {
+			continue
+		}
 
-	// Eliminar la reserva
-	result = initializers.DB.Delete(&reservation)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete reservation")
+		var inventory []models.RoomInventory
+		err := initializers.DB.
+			Where("room_type_id = ? AND date >= ? AND date < ?", roomType.ID, dates[0], dates[len(dates)-1].AddDate(0, 0, 1)).
+			Find(&inventory).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch inventory for room type %d: %v", roomType.ID, err)
+		}
+
+		availability = append(availability, dto.RoomTypeAvailabilityDTO{
+			RoomTypeID:     roomType.ID,
+			Name:           roomType.Name,
+			Capacity:       roomType.Capacity,
+			BaseRate:       roomType.BaseRate,
+			RemainingRooms: remainingRooms(roomType, inventory),
+		})
 	}
 
-	return nil
+	return availability, nil
 }
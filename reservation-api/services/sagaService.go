@@ -0,0 +1,376 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actor con el que la saga registra los cambios de estado y las operaciones de pago
+const sagaActor = "booking-saga"
+
+// Minutos que tiene una saga para terminar cuando no se configura SAGA_TIMEOUT_MINUTES.
+// Incluye la espera del webhook cuando el proveedor responde el pago de forma asíncrona.
+const defaultSagaTimeoutMinutes = 30
+
+// sagaTimeout devuelve cuánto tiempo puede estar en curso una saga antes de compensarla
+func sagaTimeout() time.Duration {
+	minutes := envInt("SAGA_TIMEOUT_MINUTES", defaultSagaTimeoutMinutes)
+	if minutes == 0 {
+		minutes = defaultSagaTimeoutMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Estados de las sagas que todavía no terminaron
+var inFlightSagaStatuses = []string{models.SagaRunning, models.SagaAwaitingPayment, models.SagaCompensationFailed}
+
+// BookReservation reserva y paga en un solo paso con una saga: toma el inventario creando la
+// reserva pendiente, autoriza el pago y confirma la reserva. Si el pago falla se libera el
+// inventario. Si el proveedor responde de forma asíncrona la saga queda esperando el webhook
+// y el barrido la termina. Con un error de negocio también se devuelve la saga.
+func BookReservation(bookingDto dto.BookingDTO) (*models.BookingSaga, error) {
+	reservationDto := bookingDto.ReservationDTO
+	if err := ValidateReservation(reservationDto); err != nil {
+		return nil, err
+	}
+
+	saga := models.BookingSaga{
+		UserID:   reservationDto.UserID,
+		HotelID:  reservationDto.HotelID,
+		Status:   models.SagaRunning,
+		Step:     models.SagaStepReserveInventory,
+		Deadline: time.Now().Add(sagaTimeout()),
+	}
+	if err := initializers.DB.Create(&saga).Error; err != nil {
+		return nil, fmt.Errorf("failed to start booking saga: %v", err)
+	}
+
+	// Paso 1: la reserva y el avance de la saga se guardan en la misma transacción
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		reservation, err := createReservation(tx, reservationDto)
+		if err != nil {
+			return err
+		}
+		saga.ReservationID = &reservation.ID
+		saga.Step = models.SagaStepAuthorizePayment
+		if err := tx.Model(&saga).Select("ReservationID", "Step").Updates(&saga).Error; err != nil {
+			return err
+		}
+		return recordSagaStep(tx, &saga, models.SagaStepReserveInventory, models.SagaActionExecute, nil)
+	})
+	if err != nil {
+		// No se tomó nada del inventario: no hay pasos que compensar
+		if finishErr := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := recordSagaStep(tx, &saga, models.SagaStepReserveInventory, models.SagaActionExecute, err); err != nil {
+				return err
+			}
+			return finishSaga(tx, &saga, models.SagaCompensated, err.Error())
+		}); finishErr != nil {
+			return nil, fmt.Errorf("failed to update booking saga %d: %v", saga.ID, finishErr)
+		}
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return &saga, err
+		}
+		return nil, fmt.Errorf("failed to reserve inventory: %v", err)
+	}
+	notifyOutbox()
+
+	// Paso 2: autorizar el pago. Si autoriza, el pago confirma la reserva; si lo rechaza, la deja fallida.
+	var stepErr error
+	intent, err := CreatePaymentIntent(*saga.ReservationID)
+	if err == nil {
+		saga.PaymentIntentID = &intent.ID
+		_, stepErr = AuthorizePayment(intent.ID, bookingDto.PaymentMethod, sagaActor)
+	} else {
+		stepErr = err
+	}
+
+	// Paso 3: confirmar o compensar según cómo quedaron el pago y la reserva
+	abortReason := ""
+	if stepErr != nil {
+		abortReason = stepErr.Error()
+	}
+	if err := resolveBookingSaga(saga.ID, time.Now(), abortReason); err != nil {
+		return nil, err
+	}
+	notifyOutbox()
+
+	if err := initializers.DB.First(&saga, saga.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch booking saga %d: %v", saga.ID, err)
+	}
+	var reservationErr *ReservationError
+	if errors.As(stepErr, &reservationErr) {
+		return &saga, stepErr
+	}
+	if stepErr != nil {
+		return nil, fmt.Errorf("failed to authorize payment: %v", stepErr)
+	}
+	return &saga, nil
+}
+
+// Decisiones posibles al evaluar una saga
+const (
+	sagaComplete   = "complete"
+	sagaWait       = "wait"
+	sagaCompensate = "compensate"
+)
+
+// sagaOutcome decide qué hacer con la saga según el estado de la reserva y del último pago
+// ("" si no hay pago): se completa si el pago está autorizado o cobrado y la reserva
+// confirmada, espera si el pago sigue pendiente, la reserva pendiente y la saga no venció
+// ni se abortó, y en cualquier otro caso se compensa.
+func sagaOutcome(reservationStatus, paymentStatus string, expired, aborted bool) string {
+	paid := paymentStatus == models.PaymentAuthorized || paymentStatus == models.PaymentCaptured
+	waiting := paymentStatus == "" || paymentStatus == models.PaymentCreated || paymentStatus == models.PaymentPending
+
+	switch {
+	case paid && reservationStatus == models.StatusConfirmed:
+		return sagaComplete
+	case waiting && reservationStatus == models.StatusPending && !expired && !aborted:
+		return sagaWait
+	}
+	return sagaCompensate
+}
+
+// resolveBookingSaga bloquea la saga y la avanza según el estado de la reserva y del pago:
+// la completa si el pago está autorizado y la reserva confirmada, la deja esperando si el
+// pago sigue pendiente y no venció, y si no compensa los pasos hechos. abortReason fuerza la
+// compensación aunque el pago siga pendiente.
+func resolveBookingSaga(sagaID uint, now time.Time, abortReason string) error {
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var saga models.BookingSaga
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&saga, sagaID).Error; err != nil {
+			return err
+		}
+		if saga.Status == models.SagaCompleted || saga.Status == models.SagaCompensated {
+			return nil
+		}
+		expired := !now.Before(saga.Deadline)
+
+		// La reserva no llegó a crearse: no hay nada que compensar
+		if saga.ReservationID == nil {
+			if !expired && abortReason == "" {
+				return nil
+			}
+			return finishSaga(tx, &saga, models.SagaCompensated, "saga timed out before reserving inventory")
+		}
+
+		reservation, err := lockReservation(tx, *saga.ReservationID)
+		if err != nil {
+			return err
+		}
+		intent, err := lockSagaPaymentIntent(tx, reservation.ID)
+		if err != nil {
+			return err
+		}
+		if intent != nil {
+			saga.PaymentIntentID = &intent.ID
+		}
+
+		paymentStatus := ""
+		if intent != nil {
+			paymentStatus = intent.Status
+		}
+
+		switch sagaOutcome(reservation.Status, paymentStatus, expired, abortReason != "") {
+		case sagaComplete:
+			if err := recordSagaStep(tx, &saga, models.SagaStepConfirmReservation, models.SagaActionExecute, nil); err != nil {
+				return err
+			}
+			saga.Step = models.SagaStepConfirmReservation
+			return finishSaga(tx, &saga, models.SagaCompleted, "")
+		case sagaWait:
+			if intent != nil {
+				saga.Status = models.SagaAwaitingPayment
+			}
+			return tx.Model(&saga).Select("Status", "PaymentIntentID").Updates(&saga).Error
+		}
+
+		reason := abortReason
+		switch {
+		case reason != "":
+		case paymentStatus == models.PaymentFailed:
+			reason = "payment failed: " + intent.FailureReason
+		case expired && reservation.Status == models.StatusPending:
+			reason = "saga timed out waiting for payment"
+		default:
+			reason = "reservation is " + reservation.Status
+		}
+		return compensateBookingSaga(tx, &saga, reservation, intent, reason)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve booking saga %d: %v", sagaID, err)
+	}
+	return nil
+}
+
+// compensateBookingSaga deshace los pasos hechos: anula o devuelve el pago y deja la reserva
+// fallida o cancelada, lo que libera el inventario. Si el proveedor no responde o rechaza la
+// anulación el inventario se libera igual y la saga queda como compensation_failed para revisarla.
+func compensateBookingSaga(tx *gorm.DB, saga *models.BookingSaga, reservation *models.Reservation, intent *models.PaymentIntent, reason string) error {
+	status := models.SagaCompensated
+
+	if intent != nil {
+		var err error
+		switch intent.Status {
+		case models.PaymentAuthorized:
+			err = voidPayment(tx, intent, "", sagaActor)
+		case models.PaymentCaptured:
+			err = refundPayment(tx, intent, 0, "", sagaActor)
+		case models.PaymentCreated, models.PaymentPending:
+			// Un webhook posterior ya no puede autorizar este pago
+			err = applyPaymentStatus(tx, intent, models.PaymentFailed, reason, 0)
+		default:
+			err = errSagaNothingToCompensate
+		}
+		if isGatewayFailure(err) {
+			status = models.SagaCompensationFailed
+		} else if err != nil && err != errSagaNothingToCompensate {
+			return err
+		}
+		if err != errSagaNothingToCompensate {
+			if err := recordSagaStep(tx, saga, models.SagaStepAuthorizePayment, models.SagaActionCompensate, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	// El pago fallido ya pudo haber movido la reserva; se vuelve a leer dentro de la transacción
+	current, err := lockReservation(tx, reservation.ID)
+	if err != nil {
+		return err
+	}
+	to := ""
+	switch {
+	case CanTransition(current.Status, models.StatusFailed):
+		to = models.StatusFailed
+	case current.Status == models.StatusConfirmed:
+		to = models.StatusCancelled
+	}
+	if to != "" {
+		if _, err := transitionReservation(tx, current.ID, to, sagaActor, reason); err != nil {
+			return err
+		}
+		if err := recordSagaStep(tx, saga, models.SagaStepReserveInventory, models.SagaActionCompensate, nil); err != nil {
+			return err
+		}
+	}
+
+	return finishSaga(tx, saga, status, reason)
+}
+
+// errSagaNothingToCompensate indica que el pago ya terminó sin cobro y no hay que deshacerlo
+var errSagaNothingToCompensate = errors.New("nothing to compensate")
+
+// lockSagaPaymentIntent obtiene el último intento de pago de la reserva bloqueando la fila, o nil si no hay
+func lockSagaPaymentIntent(tx *gorm.DB, reservationID uint) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("reservation_id = ?", reservationID).Order("id desc").First(&intent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// finishSaga deja la saga en el estado indicado; completed y compensated son definitivos
+func finishSaga(tx *gorm.DB, saga *models.BookingSaga, status, lastError string) error {
+	saga.Status = status
+	saga.LastError = lastError
+	if status == models.SagaCompleted || status == models.SagaCompensated {
+		now := time.Now()
+		saga.CompletedAt = &now
+	}
+	return tx.Model(saga).Select("Status", "Step", "PaymentIntentID", "LastError", "CompletedAt").Updates(saga).Error
+}
+
+// recordSagaStep guarda la ejecución de un paso o de su compensación
+func recordSagaStep(tx *gorm.DB, saga *models.BookingSaga, step, action string, cause error) error {
+	record := models.BookingSagaStep{
+		SagaID:    saga.ID,
+		Step:      step,
+		Action:    action,
+		Succeeded: cause == nil,
+	}
+	if cause != nil {
+		record.Error = cause.Error()
+	}
+	return tx.Create(&record).Error
+}
+
+// ResolveStuckSagas avanza las sagas que esperan el webhook del pago, las que vencieron sin
+// terminar y las que tienen una compensación fallida. Devuelve cuántas terminaron.
+func ResolveStuckSagas(now time.Time) (int, error) {
+	var sagas []models.BookingSaga
+	err := initializers.DB.
+		Where("status IN ? OR (status = ? AND deadline <= ?)",
+			[]string{models.SagaAwaitingPayment, models.SagaCompensationFailed}, models.SagaRunning, now).
+		Order("id").
+		Find(&sagas).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch stuck sagas: %v", err)
+	}
+
+	finished := 0
+	for _, saga := range sagas {
+		if err := resolveBookingSaga(saga.ID, now, ""); err != nil {
+			return finished, err
+		}
+		var current models.BookingSaga
+		if err := initializers.DB.Select("status").First(&current, saga.ID).Error; err != nil {
+			return finished, fmt.Errorf("failed to fetch booking saga %d: %v", saga.ID, err)
+		}
+		if current.Status == models.SagaCompleted || current.Status == models.SagaCompensated {
+			finished++
+		}
+	}
+	if len(sagas) > 0 {
+		notifyOutbox()
+	}
+
+	return finished, nil
+}
+
+// GetBookingSagas obtiene las sagas con el estado indicado, o las que no terminaron si no se indica
+func GetBookingSagas(status string) ([]models.BookingSaga, error) {
+	query := initializers.DB.Order("created_at desc, id desc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status IN ?", inFlightSagaStatuses)
+	}
+
+	var sagas []models.BookingSaga
+	if err := query.Find(&sagas).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch booking sagas: %v", err)
+	}
+	return sagas, nil
+}
+
+// GetBookingSaga obtiene una saga con sus pasos en orden
+func GetBookingSaga(sagaID uint) (*models.BookingSaga, []models.BookingSagaStep, error) {
+	var saga models.BookingSaga
+	err := initializers.DB.First(&saga, sagaID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrSagaNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch booking saga %d: %v", sagaID, err)
+	}
+
+	var steps []models.BookingSagaStep
+	if err := initializers.DB.Where("saga_id = ?", sagaID).Order("id").Find(&steps).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch steps for booking saga %d: %v", sagaID, err)
+	}
+	return &saga, steps, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"reservation-api/models"
+	"reservation-api/payments"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSagaOutcome(t *testing.T) {
+	cases := []struct {
+		reservation, payment string
+		expired, aborted     bool
+		expected             string
+	}{
+		{models.StatusConfirmed, models.PaymentAuthorized, false, false, sagaComplete},
+		{models.StatusConfirmed, models.PaymentCaptured, true, false, sagaComplete},
+		{models.StatusPending, models.PaymentPending, false, false, sagaWait},
+		{models.StatusPending, "", false, false, sagaWait},
+		{models.StatusPending, models.PaymentPending, true, false, sagaCompensate}, // venció esperando el webhook
+		{models.StatusPending, models.PaymentCreated, false, true, sagaCompensate}, // proveedor no disponible
+		{models.StatusFailed, models.PaymentFailed, false, true, sagaCompensate},
+		{models.StatusCancelled, models.PaymentAuthorized, false, false, sagaCompensate}, // cancelada mientras se pagaba
+	}
+	for _, tc := range cases {
+		if got := sagaOutcome(tc.reservation, tc.payment, tc.expired, tc.aborted); got != tc.expected {
+			t.Errorf("%s/%s expired=%v aborted=%v: expected %s, got %s", tc.reservation, tc.payment, tc.expired, tc.aborted, tc.expected, got)
+		}
+	}
+}
+
+// startedSaga crea una reserva que ocupa su inventario, su intento de pago y la saga que los creó
+func startedSaga(t *testing.T, db *gorm.DB, roomType models.RoomType, reservationStatus string, intent models.PaymentIntent) (*models.BookingSaga, *models.Reservation, *models.PaymentIntent) {
+	t.Helper()
+	reservation := bookedReservation(t, db, roomType, testNight(10), testNight(12))
+	db.Model(reservation).Update("status", reservationStatus)
+
+	intent.ReservationID = reservation.ID
+	if err := db.Create(&intent).Error; err != nil {
+		t.Fatal(err)
+	}
+	saga := models.BookingSaga{
+		UserID: reservation.UserID, HotelID: reservation.HotelID, Status: models.SagaAwaitingPayment,
+		ReservationID: &reservation.ID, PaymentIntentID: &intent.ID, Deadline: time.Now(),
+	}
+	if err := db.Create(&saga).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &saga, reservation, &intent
+}
+
+func TestCompensateBookingSaga(t *testing.T) {
+	db := openTestDB(t)
+	roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+	db.Create(&roomType)
+
+	// Autorización que el proveedor conoce: se anula
+	authorization, err := getPaymentGateway().Authorize(payments.AuthorizeRequest{Reference: "saga-test", Amount: 200, PaymentMethod: payments.FakeTokenApproved})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name              string
+		reservationStatus string
+		intent            models.PaymentIntent
+		sagaStatus        string
+		reservation       string
+		payment           string
+		steps             int // El pago fallido ya deja la reserva fallida, sin paso de inventario
+	}{
+		{"authorized payment is voided", models.StatusConfirmed,
+			models.PaymentIntent{Amount: 200, Status: models.PaymentAuthorized, ProviderRef: authorization.ProviderRef},
+			models.SagaCompensated, models.StatusCancelled, models.PaymentVoided, 2},
+		{"pending payment is failed", models.StatusPending,
+			models.PaymentIntent{Amount: 200, Status: models.PaymentPending, ProviderRef: "fake_async"},
+			models.SagaCompensated, models.StatusFailed, models.PaymentFailed, 1},
+		{"void the provider rejects is left for review", models.StatusConfirmed,
+			models.PaymentIntent{Amount: 200, Status: models.PaymentAuthorized, ProviderRef: "fake_unknown"},
+			models.SagaCompensationFailed, models.StatusCancelled, models.PaymentAuthorized, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			saga, reservation, intent := startedSaga(t, db, roomType, tc.reservationStatus, tc.intent)
+			t.Cleanup(func() { db.Where("1 = 1").Delete(&models.RoomInventory{}) })
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return compensateBookingSaga(tx, saga, reservation, intent, "payment timed out")
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var storedSaga models.BookingSaga
+			var storedReservation models.Reservation
+			var storedIntent models.PaymentIntent
+			db.First(&storedSaga, saga.ID)
+			db.First(&storedReservation, reservation.ID)
+			db.First(&storedIntent, intent.ID)
+			if storedSaga.Status != tc.sagaStatus {
+				t.Errorf("expected saga %s, got %s", tc.sagaStatus, storedSaga.Status)
+			}
+			if storedReservation.Status != tc.reservation {
+				t.Errorf("expected reservation %s, got %s", tc.reservation, storedReservation.Status)
+			}
+			if storedIntent.Status != tc.payment {
+				t.Errorf("expected payment %s, got %s", tc.payment, storedIntent.Status)
+			}
+
+			// El inventario se libera aunque el pago no se haya podido anular
+			for _, reserved := range reservedNights(t, db, roomType, testNight(10), testNight(12)) {
+				if reserved != 0 {
+					t.Errorf("nights should be released, got %d reserved", reserved)
+				}
+			}
+
+			var steps []models.BookingSagaStep
+			db.Where("saga_id = ?", saga.ID).Order("id").Find(&steps)
+			if len(steps) != tc.steps || steps[0].Step != models.SagaStepAuthorizePayment {
+				t.Fatalf("expected %d compensations starting with the payment, got %+v", tc.steps, steps)
+			}
+			if tc.steps == 2 && steps[1].Step != models.SagaStepReserveInventory {
+				t.Errorf("expected the inventory compensation, got %s", steps[1].Step)
+			}
+			if steps[0].Succeeded != (tc.sagaStatus == models.SagaCompensated) {
+				t.Errorf("payment compensation recorded as succeeded=%v", steps[0].Succeeded)
+			}
+		})
+	}
+}
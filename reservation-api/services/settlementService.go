@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operaciones que se pueden diferir sobre un pago
+const (
+	settlementCapture = "capture"
+	settlementVoid    = "void"
+	settlementRefund  = "refund"
+)
+
+const (
+	settlementMaxBackoff  = 30 * time.Minute
+	settlementBatchSize   = 100
+	settlementMaxAttempts = 20 // Con el backoff máximo, unas 8 horas reintentando
+)
+
+// RetryPaymentSettlements reintenta las operaciones de pago pendientes cuyo momento llegó.
+// Una operación que no se puede guardar no detiene las demás del lote. Devuelve cuántas se
+// completaron y los errores de las que no se pudieron guardar.
+func RetryPaymentSettlements(now time.Time) (int, error) {
+	var ids []uint
+	err := initializers.DB.Model(&models.PaymentSettlement{}).
+		Where("status = ? AND next_attempt_at <= ?", models.SettlementPending, now.UTC()).
+		Order("id").
+		Limit(settlementBatchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch pending payment settlements: %v", err)
+	}
+
+	completed := 0
+	var errs []error
+	for _, id := range ids {
+		done, err := runPaymentSettlement(id)
+		if err != nil {
+			log.Printf("Failed to settle payment operation %d: %s", id, err)
+			errs = append(errs, err)
+			continue
+		}
+		if done {
+			completed++
+		}
+	}
+	return completed, errors.Join(errs...)
+}
+
+// runPaymentSettlement hace la operación pendiente con la operación y el pago bloqueados, así dos
+// procesos no la hacen a la vez. La llamada al proveedor se hace con esos bloqueos tomados a
+// propósito: mientras dura (como mucho el timeout del cliente del proveedor) las demás escrituras
+// sobre ese pago esperan, pero soltarlos permitiría que otro proceso cobrara o anulara el mismo pago
+// entre la llamada y el registro de su resultado. La referencia de la operación es la misma en cada
+// intento para que el proveedor no la repita si un intento anterior llegó a hacerse. Devuelve si
+// quedó completada.
+func runPaymentSettlement(settlementID uint) (bool, error) {
+	completed := false
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var settlement models.PaymentSettlement
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&settlement, settlementID).Error; err != nil {
+			return err
+		}
+		if settlement.Status != models.SettlementPending {
+			completed = settlement.Status == models.SettlementCompleted
+			return nil
+		}
+
+		// La operación va en un savepoint: si falla por algo que no es el proveedor se deshace lo que
+		// alcanzó a escribir y el error queda guardado en la fila. Lo que registró un rechazo o una
+		// caída del proveedor se conserva.
+		var cause error
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			cause = settlePayment(tx, &settlement)
+			if isGatewayFailure(cause) {
+				return nil
+			}
+			return cause
+		})
+		if err != nil && err != cause {
+			return err
+		}
+
+		completed = cause == nil
+		return finishPaymentSettlement(tx, &settlement, cause)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to settle payment operation %d: %v", settlementID, err)
+	}
+	return completed, nil
+}
+
+// settlePayment hace sobre el pago bloqueado la operación pendiente
+func settlePayment(tx *gorm.DB, settlement *models.PaymentSettlement) error {
+	intent, err := lockPaymentIntent(tx, settlement.PaymentIntentID)
+	if err != nil {
+		return err
+	}
+
+	reference := fmt.Sprintf("ps_%d", settlement.ID)
+	switch settlement.Operation {
+	case settlementCapture:
+		return capturePayment(tx, intent, settlement.Amount, reference, settlement.Actor)
+	case settlementVoid:
+		return voidPayment(tx, intent, reference, settlement.Actor)
+	case settlementRefund:
+		return refundPayment(tx, intent, settlement.Amount, reference, settlement.Actor)
+	default:
+		return fmt.Errorf("unknown payment operation %q", settlement.Operation)
+	}
+}
+
+// finishPaymentSettlement guarda el resultado del intento. Si el pago ya no admite la operación (por
+// ejemplo, se operó a mano) o el proveedor la rechazó queda fallida. Cualquier otro error (el
+// proveedor no respondió, un fallo inesperado) la reprograma con backoff hasta settlementMaxAttempts
+// intentos; después queda fallida para que la revise alguien.
+func finishPaymentSettlement(tx *gorm.DB, settlement *models.PaymentSettlement, cause error) error {
+	attempts := settlement.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	var reservationErr *ReservationError
+	switch {
+	case cause == nil:
+		now := time.Now().UTC()
+		updates["status"] = models.SettlementCompleted
+		updates["completed_at"] = now
+		updates["last_error"] = ""
+	case !errors.Is(cause, ErrPaymentGatewayUnavailable) && errors.As(cause, &reservationErr):
+		updates["status"] = models.SettlementFailed
+		updates["last_error"] = cause.Error()
+	case attempts >= settlementMaxAttempts:
+		updates["status"] = models.SettlementFailed
+		updates["last_error"] = fmt.Sprintf("gave up after %d attempts: %s", attempts, cause)
+	default:
+		updates["next_attempt_at"] = time.Now().UTC().Add(settlementBackoff(settlement.Attempts))
+		updates["last_error"] = cause.Error()
+	}
+
+	return tx.Model(settlement).Updates(updates).Error
+}
+
+// settlementBackoff es la espera antes del próximo intento de una operación que ya falló attempts veces
+func settlementBackoff(attempts int) time.Duration {
+	backoff := time.Duration(1<<uint(min(attempts, 16))) * time.Minute
+	if backoff > settlementMaxBackoff {
+		backoff = settlementMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"reservation-api/models"
+	"reservation-api/payments"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryPaymentSettlements(t *testing.T) {
+	db := openTestDB(t)
+
+	authorization, err := getPaymentGateway().Authorize(payments.AuthorizeRequest{Reference: "settlement-test", Amount: 200, PaymentMethod: payments.FakeTokenApproved})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorized := models.PaymentIntent{Amount: 200, Status: models.PaymentAuthorized, ProviderRef: authorization.ProviderRef}
+	unknown := models.PaymentIntent{Amount: 200, Status: models.PaymentAuthorized, ProviderRef: "fake_unknown"}
+	db.Create(&authorized)
+	db.Create(&unknown)
+
+	due := time.Now().UTC().Add(-time.Minute)
+	settlements := []models.PaymentSettlement{
+		{PaymentIntentID: authorized.ID, Operation: "settle", Status: models.SettlementPending, NextAttemptAt: due},
+		{PaymentIntentID: unknown.ID, Operation: settlementVoid, Status: models.SettlementPending, NextAttemptAt: due},
+		{PaymentIntentID: authorized.ID, Operation: "settle", Status: models.SettlementPending, Attempts: settlementMaxAttempts - 1, NextAttemptAt: due},
+		{PaymentIntentID: authorized.ID, Operation: settlementVoid, Status: models.SettlementPending, NextAttemptAt: due},
+	}
+	for i := range settlements {
+		db.Create(&settlements[i])
+	}
+
+	// La operación desconocida no detiene el lote: se guarda su error y se sigue con las demás
+	completed, err := RetryPaymentSettlements(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed != 1 {
+		t.Errorf("expected 1 completed settlement, got %d", completed)
+	}
+
+	expected := []struct {
+		status   string
+		attempts int
+		err      string
+	}{
+		{models.SettlementPending, 1, "unknown payment operation"},
+		{models.SettlementFailed, 1, ErrPaymentRejected.Message}, // rechazo definitivo del proveedor
+		{models.SettlementFailed, settlementMaxAttempts, "gave up after"},
+		{models.SettlementCompleted, 1, ""},
+	}
+	for i, settlement := range settlements {
+		var stored models.PaymentSettlement
+		db.First(&stored, settlement.ID)
+		if stored.Status != expected[i].status || stored.Attempts != expected[i].attempts {
+			t.Errorf("settlement %d: expected %s after %d attempts, got %s after %d", i, expected[i].status, expected[i].attempts, stored.Status, stored.Attempts)
+		}
+		if expected[i].err != "" && !strings.Contains(stored.LastError, expected[i].err) {
+			t.Errorf("settlement %d: expected error %q, got %q", i, expected[i].err, stored.LastError)
+		}
+	}
+
+	var pending models.PaymentSettlement
+	db.First(&pending, settlements[0].ID)
+	if !pending.NextAttemptAt.After(time.Now()) {
+		t.Errorf("expected the failing settlement to be rescheduled, got %s", pending.NextAttemptAt)
+	}
+
+	// El rechazo queda registrado como operación aunque la operación diferida falle
+	var operations int64
+	db.Model(&models.PaymentOperation{}).Where("payment_intent_id = ?", unknown.ID).Count(&operations)
+	if operations != 1 {
+		t.Errorf("expected the rejected void to be recorded, got %d operations", operations)
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Transiciones permitidas desde cada estado. checked_out, cancelled, no_show y failed son finales.
+var statusTransitions = map[string][]string{
+	models.StatusPending:   {models.StatusConfirmed, models.StatusCancelled, models.StatusFailed},
+	models.StatusConfirmed: {models.StatusCheckedIn, models.StatusCancelled, models.StatusNoShow},
+	models.StatusCheckedIn: {models.StatusCheckedOut},
+}
+
+// CanTransition indica si una reserva puede pasar del estado from al estado to
+func CanTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UserActor identifica a un usuario como autor de un cambio de estado
+func UserActor(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// recordStatusChange guarda la transición en el historial dentro de la transacción
+func recordStatusChange(tx *gorm.DB, reservation *models.Reservation, from, actor, reason string) error {
+	return tx.Create(&models.ReservationStatusChange{
+		ReservationID: reservation.ID,
+		FromStatus:    from,
+		ToStatus:      reservation.Status,
+		Actor:         actor,
+		Reason:        reason,
+		CreatedAt:     reservation.StatusChangedAt,
+	}).Error
+}
+
+// lockReservation obtiene la reserva bloqueando la fila hasta el final de la transacción
+func lockReservation(tx *gorm.DB, reservationID uint) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, reservationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// TransitionReservation cambia el estado de la reserva y registra quién lo hizo.
+// Cancelar, marcar como no show o como fallida devuelve al inventario las noches que
+// todavía no pasaron y las ofrece a la lista de espera. Cancelar o fallar también devuelve
+// el uso del código promocional.
+func TransitionReservation(reservationID uint, to, actor, reason string) (*models.Reservation, error) {
+	var reservation *models.Reservation
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = transitionReservation(tx, reservationID, to, actor, reason)
+		return err
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to change reservation status: %v", err)
+	}
+	notifyOutbox()
+
+	return reservation, nil
+}
+
+// transitionReservation cambia el estado de la reserva dentro de la transacción
+func transitionReservation(tx *gorm.DB, reservationID uint, to, actor, reason string) (*models.Reservation, error) {
+	reservation, err := lockReservation(tx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := reservation.Status
+	if !CanTransition(from, to) {
+		return nil, ErrIllegalTransition
+	}
+
+	now := time.Now()
+	if to == models.StatusCancelled || to == models.StatusNoShow || to == models.StatusFailed {
+		releaseFrom := reservation.FechaDesde
+		if today := nights(now, now.AddDate(0, 0, 1))[0]; releaseFrom.Before(today) {
+			releaseFrom = today
+		}
+		if err := releaseInventory(tx, reservation.RoomTypeID, releaseFrom, reservation.FechaHasta); err != nil {
+			return nil, fmt.Errorf("failed to release inventory: %v", err)
+		}
+		if err := offerFreedRoom(tx, reservation.RoomTypeID, releaseFrom, reservation.FechaHasta); err != nil {
+			return nil, fmt.Errorf("failed to offer room to waitlist: %v", err)
+		}
+	}
+	if to == models.StatusCancelled || to == models.StatusFailed {
+		if err := releasePromoRedemption(tx, reservation.ID, now); err != nil {
+			return nil, fmt.Errorf("failed to release promo code: %v", err)
+		}
+	}
+
+	reservation.Status = to
+	reservation.StatusChangedAt = now
+	if err := tx.Model(reservation).Select("Status", "StatusChangedAt").Updates(reservation).Error; err != nil {
+		return nil, err
+	}
+
+	if err := recordStatusChange(tx, reservation, from, actor, reason); err != nil {
+		return nil, err
+	}
+
+	eventType := models.ReservationStatusChangedEvent
+	if to == models.StatusCancelled {
+		eventType = models.ReservationCancelledEvent
+	}
+	if err := enqueueReservationEvent(tx, eventType, reservation, from, actor); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// GetReservationHistory obtiene las transiciones de estado de la reserva en orden
+func GetReservationHistory(reservationID uint) ([]models.ReservationStatusChange, error) {
+	var count int64
+	if err := initializers.DB.Model(&models.Reservation{}).Where("id = ?", reservationID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrReservationNotFound
+	}
+
+	var history []models.ReservationStatusChange
+	err := initializers.DB.Where("reservation_id = ?", reservationID).Order("created_at, id").Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch history for reservation %d: %v", reservationID, err)
+	}
+	return history, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"reservation-api/models"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := [][2]string{
+		{models.StatusPending, models.StatusConfirmed},
+		{models.StatusPending, models.StatusCancelled},
+		{models.StatusPending, models.StatusFailed},
+		{models.StatusConfirmed, models.StatusCheckedIn},
+		{models.StatusConfirmed, models.StatusCancelled},
+		{models.StatusConfirmed, models.StatusNoShow},
+		{models.StatusCheckedIn, models.StatusCheckedOut},
+	}
+	for _, transition := range allowed {
+		if !CanTransition(transition[0], transition[1]) {
+			t.Errorf("%s -> %s should be allowed", transition[0], transition[1])
+		}
+	}
+
+	rejected := [][2]string{
+		{models.StatusPending, models.StatusCheckedIn},
+		{models.StatusPending, models.StatusNoShow},
+		{models.StatusCheckedIn, models.StatusCancelled},
+		{models.StatusCheckedOut, models.StatusCheckedIn},
+		{models.StatusCancelled, models.StatusConfirmed},
+		{models.StatusNoShow, models.StatusCheckedIn},
+		{models.StatusConfirmed, models.StatusFailed},
+		{models.StatusFailed, models.StatusConfirmed},
+		{models.StatusConfirmed, models.StatusConfirmed},
+	}
+	for _, transition := range rejected {
+		if CanTransition(transition[0], transition[1]) {
+			t.Errorf("%s -> %s should be rejected", transition[0], transition[1])
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"reservation-api/initializers"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB reemplaza la base de datos por una SQLite en memoria con todas las tablas durante el test.
+// SQLite ignora los SELECT ... FOR UPDATE pero sí respeta transacciones y savepoints.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Una sola conexión: las transacciones anidadas usan savepoints sobre la misma
+	sqlDB.SetMaxOpenConns(1)
+
+	previous := initializers.DB
+	initializers.DB = db
+	initializers.SyncDatabase()
+	t.Cleanup(func() {
+		initializers.DB = previous
+		sqlDB.Close()
+	})
+	return db
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"reservation-api/clients"
+	"reservation-api/dto"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reglas de fechas por defecto, configurables por variables de entorno
+const (
+	defaultMaxStayNights    = 30  // MAX_STAY_NIGHTS
+	defaultMinLeadTimeHours = 0   // MIN_LEAD_TIME_HOURS
+	defaultMaxAdvanceDays   = 365 // MAX_ADVANCE_DAYS
+	defaultCheckInHour      = 14  // CHECK_IN_HOUR, en UTC como las fechas de las reservas
+)
+
+// DateRules son las reglas de fechas que tiene que cumplir una reserva
+type DateRules struct {
+	MaxStayNights int
+	MinLeadTime   time.Duration // Anticipación mínima entre la reserva y el check-in
+	MaxAdvance    time.Duration // Anticipación máxima con la que se puede reservar
+	CheckInHour   int           // Hora del check-in, desde la que se mide la anticipación
+}
+
+// envInt lee una variable de entorno entera no negativa o devuelve el valor por defecto
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// dateRulesFromEnv arma las reglas de fechas configuradas
+func dateRulesFromEnv() DateRules {
+	return DateRules{
+		MaxStayNights: envInt("MAX_STAY_NIGHTS", defaultMaxStayNights),
+		MinLeadTime:   time.Duration(envInt("MIN_LEAD_TIME_HOURS", defaultMinLeadTimeHours)) * time.Hour,
+		MaxAdvance:    time.Duration(envInt("MAX_ADVANCE_DAYS", defaultMaxAdvanceDays)) * 24 * time.Hour,
+		CheckInHour:   min(envInt("CHECK_IN_HOUR", defaultCheckInHour), 23),
+	}
+}
+
+// ValidateDates verifica el rango de fechas de una reserva respecto de now. La anticipación se mide
+// hasta la hora de check-in del primer día, así que con MinLeadTime en 0 se puede reservar para hoy.
+func ValidateDates(checkIn, checkOut, now time.Time, rules DateRules) error {
+	stay := nights(checkIn, checkOut)
+	if !checkOut.After(checkIn) || len(stay) == 0 {
+		return ErrInvalidDateRange
+	}
+
+	today := nights(now, now.AddDate(0, 0, 1))[0]
+	if stay[0].Before(today) {
+		return ErrDateInPast
+	}
+	if rules.MaxStayNights > 0 && len(stay) > rules.MaxStayNights {
+		return ErrStayTooLong
+	}
+	lead := stay[0].Add(time.Duration(rules.CheckInHour) * time.Hour).Sub(now)
+	if rules.MinLeadTime > 0 && lead < rules.MinLeadTime {
+		return ErrLeadTimeTooShort
+	}
+	if rules.MaxAdvance > 0 && lead > rules.MaxAdvance {
+		return ErrBookingTooFar
+	}
+
+	return nil
+}
+
+var (
+	hotelClientOnce sync.Once
+	hotelClient     *clients.HotelClient
+)
+
+// getHotelClient crea el cliente de hotel-api la primera vez que se usa,
+// cuando las variables de entorno ya están cargadas. hotel-api valida los tokens
+// con el mismo SECRET, así que el cliente firma sus propios tokens de servicio.
+func getHotelClient() *clients.HotelClient {
+	hotelClientOnce.Do(func() {
+		baseURL := os.Getenv("HOTEL_API_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080"
+		}
+		hotelClient = clients.NewHotelClient(baseURL, os.Getenv("SECRET"))
+	})
+	return hotelClient
+}
+
+// ValidateReservation verifica una reserva antes de crearla: primero las fechas,
+// que no requieren llamadas, después el usuario en user-api y el hotel en hotel-api.
+func ValidateReservation(reservationDto dto.ReservationDTO) error {
+	if err := ValidateDates(reservationDto.FechaDesde, reservationDto.FechaHasta, time.Now(), dateRulesFromEnv()); err != nil {
+		return err
+	}
+
+	userExists, err := CheckUserExists(reservationDto.UserID)
+	if err != nil {
+		return ErrUserLookupUnavailable
+	}
+	if !userExists {
+		return ErrUserNotFound
+	}
+
+	hotelExists, err := getHotelClient().HotelExists(reservationDto.HotelID)
+	if errors.Is(err, clients.ErrHotelAPIUnavailable) {
+		return ErrHotelLookupUnavailable
+	}
+	if err != nil {
+		return err
+	}
+	if !hotelExists {
+		return ErrHotelNotFound
+	}
+
+	return nil
+}
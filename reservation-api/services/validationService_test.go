@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateDates(t *testing.T) {
+	now := time.Date(2030, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return time.Date(2030, 3, 10+offset, 14, 0, 0, 0, time.UTC) }
+	rules := DateRules{MaxStayNights: 14, MinLeadTime: 2 * time.Hour, MaxAdvance: 90 * 24 * time.Hour, CheckInHour: 14}
+
+	cases := []struct {
+		name     string
+		checkIn  time.Time
+		checkOut time.Time
+		expected error
+	}{
+		{"valid", day(1), day(3), nil},
+		{"check-out before check-in", day(3), day(1), ErrInvalidDateRange},
+		{"same day", day(1), day(1), ErrInvalidDateRange},
+		{"past", day(-2), day(1), ErrDateInPast},
+		{"too long", day(1), day(20), ErrStayTooLong},
+		{"today is too soon", day(0), day(2), ErrLeadTimeTooShort},
+		{"too far ahead", day(120), day(122), ErrBookingTooFar},
+	}
+
+	for _, c := range cases {
+		if err := ValidateDates(c.checkIn, c.checkOut, now, rules); err != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, err)
+		}
+	}
+}
+
+func TestValidateDatesSameDayCheckIn(t *testing.T) {
+	checkIn := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+	checkOut := checkIn.AddDate(0, 0, 2)
+	morning := time.Date(2030, 3, 10, 9, 0, 0, 0, time.UTC)
+	evening := time.Date(2030, 3, 10, 22, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		now      time.Time
+		lead     time.Duration
+		expected error
+	}{
+		{"no lead time in the morning", morning, 0, nil},
+		{"no lead time after the check-in hour", evening, 0, nil},
+		{"enough lead time before the check-in hour", morning, 2 * time.Hour, nil},
+		{"not enough lead time before the check-in hour", morning, 6 * time.Hour, ErrLeadTimeTooShort},
+		{"lead time after the check-in hour", evening, 2 * time.Hour, ErrLeadTimeTooShort},
+	}
+	for _, c := range cases {
+		rules := DateRules{MinLeadTime: c.lead, CheckInHour: 14}
+		if err := ValidateDates(checkIn, checkOut, c.now, rules); err != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Minutos que dura el bloqueo ofrecido a la lista de espera cuando no se configura
+// WAITLIST_HOLD_TTL_MINUTES. Es más largo que un bloqueo normal porque el usuario
+// primero tiene que enterarse de la oferta.
+const defaultWaitlistHoldTTLMinutes = 120
+
+// waitlistHoldTTL devuelve cuánto dura el bloqueo ofrecido a la lista de espera
+func waitlistHoldTTL() time.Duration {
+	minutes := envInt("WAITLIST_HOLD_TTL_MINUTES", defaultWaitlistHoldTTLMinutes)
+	if minutes == 0 {
+		minutes = defaultWaitlistHoldTTLMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// JoinWaitlist anota al usuario en la lista de espera del tipo de habitación para el rango.
+// Si hay habitaciones libres no se anota: puede reservar directamente. Tampoco se anota dos
+// veces para la misma estadía mientras la entrada anterior siga esperando o tenga una oferta.
+func JoinWaitlist(userID uint, waitlistDto dto.WaitlistDTO) (*models.WaitlistEntry, error) {
+	if err := ValidateDates(waitlistDto.FechaDesde, waitlistDto.FechaHasta, time.Now(), dateRulesFromEnv()); err != nil {
+		return nil, err
+	}
+
+	guests := waitlistDto.Guests
+	if guests == 0 {
+		guests = 1
+	}
+
+	var entry models.WaitlistEntry
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		roomType, err := roomTypeForStay(tx, waitlistDto.HotelID, waitlistDto.RoomTypeID, guests)
+		if err != nil {
+			return err
+		}
+
+		// Bloquear el tipo de habitación serializa las altas en su lista de espera,
+		// así dos pedidos simultáneos no anotan dos veces la misma estadía
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.RoomType{}, roomType.ID).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		err = tx.Model(&models.WaitlistEntry{}).
+			Where("user_id = ? AND room_type_id = ? AND fecha_desde = ? AND fecha_hasta = ? AND status IN ?",
+				userID, roomType.ID, waitlistDto.FechaDesde, waitlistDto.FechaHasta, []string{models.WaitlistWaiting, models.WaitlistOffered}).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrWaitlistEntryExists
+		}
+
+		dates := nights(waitlistDto.FechaDesde, waitlistDto.FechaHasta)
+		var inventory []models.RoomInventory
+		err = tx.
+			Where("room_type_id = ? AND date >= ? AND date < ?", roomType.ID, dates[0], dates[len(dates)-1].AddDate(0, 0, 1)).
+			Find(&inventory).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch inventory for room type %d: %v", roomType.ID, err)
+		}
+		if remainingRooms(roomType, inventory) > 0 {
+			return ErrRoomsAvailable
+		}
+
+		entry = models.WaitlistEntry{
+			UserID:     userID,
+			HotelID:    roomType.HotelID,
+			RoomTypeID: roomType.ID,
+			Guests:     guests,
+			FechaDesde: waitlistDto.FechaDesde,
+			FechaHasta: waitlistDto.FechaHasta,
+			Status:     models.WaitlistWaiting,
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to join waitlist: %v", err)
+	}
+	return &entry, nil
+}
+
+// GetWaitlistByUser obtiene las entradas de la lista de espera del usuario
+func GetWaitlistByUser(userID uint) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	if err := initializers.DB.Where("user_id = ?", userID).Order("created_at desc, id desc").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist for user %d: %v", userID, err)
+	}
+	return entries, nil
+}
+
+// LeaveWaitlist saca al usuario de la lista de espera. Si ya se le ofreció un bloqueo,
+// el bloqueo sigue vigente hasta que venza o lo libere.
+func LeaveWaitlist(userID, entryID uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWaitlistEntryNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if entry.Status != models.WaitlistWaiting && entry.Status != models.WaitlistOffered {
+			return ErrWaitlistEntryClosed
+		}
+
+		entry.Status = models.WaitlistCancelled
+		return tx.Model(&entry).Update("status", entry.Status).Error
+	})
+	if err != nil {
+		var reservationErr *ReservationError
+		if errors.As(err, &reservationErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to leave waitlist: %v", err)
+	}
+
+	return &entry, nil
+}
+
+// updateWaitlistOffer cierra la entrada a la que se ofreció el bloqueo, si existe
+func updateWaitlistOffer(tx *gorm.DB, holdToken, status string) error {
+	return tx.Model(&models.WaitlistEntry{}).
+		Where("hold_token = ? AND status = ?", holdToken, models.WaitlistOffered).
+		Update("status", status).Error
+}
+
+// offerFreedRoom ofrece la habitación que se liberó entre from y to al primer usuario de la
+// lista de espera cuyo rango se superpone y que ahora tiene todas sus noches libres. Se le
+// bloquea la habitación y se emite un evento para avisarle.
+func offerFreedRoom(tx *gorm.DB, roomTypeID uint, from, to time.Time) error {
+	now := time.Now()
+	today := nights(now, now.AddDate(0, 0, 1))[0]
+
+	var entries []models.WaitlistEntry
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("room_type_id = ? AND status = ? AND fecha_desde < ? AND fecha_hasta > ? AND fecha_desde >= ?",
+			roomTypeID, models.WaitlistWaiting, to, from, today).
+		Order("created_at, id").
+		Find(&entries).Error
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	var roomType models.RoomType
+	if err := tx.First(&roomType, roomTypeID).Error; err != nil {
+		return err
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Guests > roomType.Capacity {
+			continue
+		}
+
+		// El bloqueo se intenta en un savepoint: si al usuario le falta alguna noche se sigue con el próximo
+		var hold *models.RoomHold
+		err := tx.Transaction(func(savepoint *gorm.DB) error {
+			var err error
+			hold, err = placeHold(savepoint, roomType, entry.UserID, entry.Guests, entry.FechaDesde, entry.FechaHasta, waitlistHoldTTL())
+			return err
+		})
+		if errors.Is(err, ErrNoAvailability) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		entry.Status = models.WaitlistOffered
+		entry.HoldToken = hold.Token
+		entry.OfferedAt = &now
+		if err := tx.Model(entry).Select("Status", "HoldToken", "OfferedAt").Updates(entry).Error; err != nil {
+			return err
+		}
+
+		return enqueueEvent(tx, models.WaitlistOfferedEvent, "waitlist:"+strconv.FormatUint(uint64(entry.ID), 10), models.WaitlistEvent{
+			SchemaVersion:   models.WaitlistEventSchemaVersion,
+			EventType:       models.WaitlistOfferedEvent,
+			Timestamp:       now.UTC(),
+			WaitlistEntryID: entry.ID,
+			UserID:          entry.UserID,
+			HotelID:         entry.HotelID,
+			RoomTypeID:      entry.RoomTypeID,
+			CheckIn:         entry.FechaDesde.Format("2006-01-02"),
+			CheckOut:        entry.FechaHasta.Format("2006-01-02"),
+			HoldToken:       hold.Token,
+			HoldExpiresAt:   hold.ExpiresAt.UTC(),
+		})
+	}
+
+	return nil
+}
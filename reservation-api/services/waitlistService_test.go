@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"reservation-api/dto"
+	"reservation-api/initializers"
+	"reservation-api/models"
+	"testing"
+	"time"
+)
+
+// testNight devuelve la medianoche UTC dentro de days días
+func testNight(days int) time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+}
+
+// fillNights deja sin habitaciones libres las noches de from a to
+func fillNights(t *testing.T, roomType models.RoomType, from, to time.Time) {
+	t.Helper()
+	for _, night := range nights(from, to) {
+		row := models.RoomInventory{RoomTypeID: roomType.ID, Date: night, Total: roomType.Count, Reserved: roomType.Count}
+		if err := initializers.DB.Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	db := openTestDB(t)
+	roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+	db.Create(&roomType)
+
+	request := dto.WaitlistDTO{HotelID: "h1", RoomTypeID: roomType.ID, Guests: 2, FechaDesde: testNight(10), FechaHasta: testNight(12)}
+	if _, err := JoinWaitlist(7, request); !errors.Is(err, ErrRoomsAvailable) {
+		t.Fatalf("expected rooms available, got %v", err)
+	}
+
+	fillNights(t, roomType, testNight(10), testNight(12))
+	entry, err := JoinWaitlist(7, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != models.WaitlistWaiting {
+		t.Errorf("expected a waiting entry, got %s", entry.Status)
+	}
+
+	if _, err := JoinWaitlist(7, request); !errors.Is(err, ErrWaitlistEntryExists) {
+		t.Errorf("expected duplicate entry to be rejected, got %v", err)
+	}
+	if _, err := JoinWaitlist(8, request); err != nil {
+		t.Errorf("another user should be able to join: %v", err)
+	}
+
+	// Una entrada cerrada no impide volver a anotarse
+	db.Model(entry).Update("status", models.WaitlistCancelled)
+	if _, err := JoinWaitlist(7, request); err != nil {
+		t.Errorf("expected to join again after leaving, got %v", err)
+	}
+}
+
+func TestOfferFreedRoom(t *testing.T) {
+	db := openTestDB(t)
+	roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+	db.Create(&roomType)
+
+	// Se libera la habitación de las noches 10 y 11; la noche 12 sigue ocupada
+	fillNights(t, roomType, testNight(12), testNight(13))
+
+	base := time.Now().Add(-time.Hour)
+	entries := []models.WaitlistEntry{
+		{UserID: 1, RoomTypeID: roomType.ID, Guests: 2, FechaDesde: testNight(20), FechaHasta: testNight(22), CreatedAt: base},                      // no se superpone
+		{UserID: 2, RoomTypeID: roomType.ID, Guests: 2, FechaDesde: testNight(11), FechaHasta: testNight(13), CreatedAt: base.Add(time.Minute)},     // le falta la noche 12
+		{UserID: 3, RoomTypeID: roomType.ID, Guests: 3, FechaDesde: testNight(10), FechaHasta: testNight(11), CreatedAt: base.Add(2 * time.Minute)}, // supera la capacidad
+		{UserID: 4, RoomTypeID: roomType.ID, Guests: 2, FechaDesde: testNight(10), FechaHasta: testNight(12), CreatedAt: base.Add(3 * time.Minute)},
+		{UserID: 5, RoomTypeID: roomType.ID, Guests: 1, FechaDesde: testNight(10), FechaHasta: testNight(12), CreatedAt: base.Add(4 * time.Minute)},
+	}
+	for i := range entries {
+		entries[i].HotelID = "h1"
+		entries[i].Status = models.WaitlistWaiting
+		db.Create(&entries[i])
+	}
+
+	if err := offerFreedRoom(db, roomType.ID, testNight(10), testNight(12)); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[uint]string{}
+	var stored []models.WaitlistEntry
+	db.Find(&stored)
+	for _, entry := range stored {
+		statuses[entry.UserID] = entry.Status
+	}
+	expected := map[uint]string{1: models.WaitlistWaiting, 2: models.WaitlistWaiting, 3: models.WaitlistWaiting, 4: models.WaitlistOffered, 5: models.WaitlistWaiting}
+	for userID, status := range expected {
+		if statuses[userID] != status {
+			t.Errorf("user %d: expected %s, got %s", userID, status, statuses[userID])
+		}
+	}
+
+	// El intento fallido del usuario 2 se deshizo en su savepoint: no quedó bloqueo ni noches tomadas
+	var holds []models.RoomHold
+	db.Find(&holds)
+	if len(holds) != 1 || holds[0].UserID != 4 {
+		t.Fatalf("expected only the hold offered to user 4, got %+v", holds)
+	}
+	var inventory []models.RoomInventory
+	db.Order("date").Find(&inventory)
+	reserved := map[string]int{}
+	for _, night := range inventory {
+		reserved[night.Date.Format("2006-01-02")] = night.Reserved
+	}
+	for days, count := range map[int]int{10: 1, 11: 1, 12: 1} {
+		if got := reserved[testNight(days).Format("2006-01-02")]; got != count {
+			t.Errorf("night +%d: expected %d reserved, got %d", days, count, got)
+		}
+	}
+
+	var events []models.OutboxEntry
+	db.Find(&events)
+	if len(events) != 1 || events[0].EventType != models.WaitlistOfferedEvent {
+		t.Errorf("expected one waitlist offer event, got %+v", events)
+	}
+}
+
+func TestOfferFreedRoomRollsBackFailedHold(t *testing.T) {
+	db := openTestDB(t)
+	roomType := models.RoomType{HotelID: "h1", Name: "Doble", Capacity: 2, Count: 1, BaseRate: 100}
+	db.Create(&roomType)
+	fillNights(t, roomType, testNight(12), testNight(13))
+
+	entry := models.WaitlistEntry{UserID: 2, HotelID: "h1", RoomTypeID: roomType.ID, Guests: 2, FechaDesde: testNight(11), FechaHasta: testNight(13), Status: models.WaitlistWaiting}
+	db.Create(&entry)
+
+	if err := offerFreedRoom(db, roomType.ID, testNight(10), testNight(12)); err != nil {
+		t.Fatal(err)
+	}
+
+	// El bloqueo creó la fila de la noche 11 antes de encontrar completa la 12; el savepoint la deshace
+	var count int64
+	db.Model(&models.RoomInventory{}).Where("room_type_id = ? AND date = ?", roomType.ID, testNight(11)).Count(&count)
+	if count != 0 {
+		t.Errorf("expected the partial hold to be rolled back, found %d inventory rows for the free night", count)
+	}
+	db.Model(&models.RoomHold{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no holds, got %d", count)
+	}
+	db.First(&entry, entry.ID)
+	if entry.Status != models.WaitlistWaiting {
+		t.Errorf("expected the entry to keep waiting, got %s", entry.Status)
+	}
+}
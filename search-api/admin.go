@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"search-api/consumer"
+	"search-api/reindex"
+	"strconv"
+)
+
+// deadLettersHandler permite listar (GET), reprocesar (POST) o descartar (DELETE)
+// los mensajes de hoteles que terminaron en la dead-letter queue.
+// El parámetro opcional 'id' limita la operación a un mensaje.
+func deadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	messageID := r.URL.Query().Get("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := 50
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "El parámetro 'limit' debe ser un entero positivo", http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+
+		letters, err := consumer.ListDeadLetters(limit)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error al leer la dead-letter queue: %s", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"messages": letters})
+
+	case http.MethodPost:
+		replayed, err := consumer.ReplayDeadLetters(messageID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error al reprocesar los mensajes: %s", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"replayed": replayed})
+
+	case http.MethodDelete:
+		purged, err := consumer.PurgeDeadLetters(messageID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error al descartar los mensajes: %s", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"purged": purged})
+
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// reindexHandler inicia una reindexación completa (POST) o devuelve su progreso (GET)
+func reindexHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		progress := reindex.Current()
+		if progress == nil {
+			http.Error(w, "No se ejecutó ninguna reindexación", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, progress)
+
+	case http.MethodPost:
+		progress, err := reindex.Start(searchIndex)
+		if err == reindex.ErrAlreadyRunning {
+			writeJSON(w, http.StatusConflict, reindex.Current())
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error al iniciar la reindexación: %s", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, progress)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeJSON responde con el valor serializado en JSON
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
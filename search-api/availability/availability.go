@@ -0,0 +1,120 @@
+package availability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Cantidad de consultas simultáneas a reservation-api por búsqueda
+const workers = 8
+
+// Tiempo máximo para resolver la disponibilidad de todos los candidatos de una búsqueda
+const RequestTimeout = 3 * time.Second
+
+// Request son las fechas y huéspedes de una búsqueda con disponibilidad
+type Request struct {
+	CheckIn  time.Time
+	CheckOut time.Time
+	Guests   int
+}
+
+// hotelAvailability es la respuesta de reservation-api para un hotel
+type hotelAvailability struct {
+	HotelID        string `json:"hotel_id"`
+	Available      bool   `json:"available"`
+	RemainingRooms int    `json:"remaining_rooms"`
+}
+
+var client = &http.Client{}
+
+// reservationAPIURL devuelve la URL base de reservation-api
+func reservationAPIURL() string {
+	if base := os.Getenv("RESERVATION_API_URL"); base != "" {
+		return base
+	}
+	return "http://localhost:3001"
+}
+
+// Check consulta en paralelo la disponibilidad de los hoteles y devuelve las
+// habitaciones libres de los que se pueden reservar. Los hoteles cuya consulta
+// falla o no termina a tiempo se consideran no disponibles.
+func Check(ctx context.Context, hotelIDs []string, req Request) map[string]int {
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	bookable := make(map[string]int)
+
+	for i := 0; i < workers && i < len(hotelIDs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hotelID := range jobs {
+				result, err := fetch(ctx, hotelID, req)
+				if err != nil {
+					log.Printf("Error al consultar la disponibilidad del hotel %s: %v", hotelID, err)
+					continue
+				}
+				if result.Available && result.RemainingRooms > 0 {
+					mu.Lock()
+					bookable[hotelID] = result.RemainingRooms
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	// Dejar de repartir trabajo si se vence el tiempo de la búsqueda
+	for _, hotelID := range hotelIDs {
+		select {
+		case jobs <- hotelID:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return bookable
+}
+
+// fetch consulta la disponibilidad de un hotel en reservation-api
+func fetch(ctx context.Context, hotelID string, req Request) (*hotelAvailability, error) {
+	params := url.Values{}
+	params.Set("check_in", req.CheckIn.Format("2006-01-02"))
+	params.Set("check_out", req.CheckOut.Format("2006-01-02"))
+	params.Set("guests", fmt.Sprint(req.Guests))
+	endpoint := fmt.Sprintf("%s/reservations/availability/%s?%s", reservationAPIURL(), url.PathEscape(hotelID), params.Encode())
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("respuesta inesperada de reservation-api: %d", resp.StatusCode)
+	}
+
+	var result hotelAvailability
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,63 @@
+package availability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckReturnsOnlyBookableHotels(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		hotelID := strings.TrimPrefix(r.URL.Path, "/reservations/availability/")
+		switch hotelID {
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "full":
+			json.NewEncoder(w).Encode(hotelAvailability{HotelID: hotelID})
+		default:
+			json.NewEncoder(w).Encode(hotelAvailability{HotelID: hotelID, Available: true, RemainingRooms: 3})
+		}
+	}))
+	defer server.Close()
+	t.Setenv("RESERVATION_API_URL", server.URL)
+
+	ids := []string{"full", "broken"}
+	for i := 0; i < 20; i++ {
+		ids = append(ids, string(rune('a'+i)))
+	}
+
+	req := Request{CheckIn: time.Now(), CheckOut: time.Now().AddDate(0, 0, 2), Guests: 2}
+	bookable := Check(context.Background(), ids, req)
+
+	if len(bookable) != 20 {
+		t.Fatalf("expected 20 bookable hotels, got %d", len(bookable))
+	}
+	if _, ok := bookable["full"]; ok {
+		t.Error("sold out hotel should not be bookable")
+	}
+	if _, ok := bookable["broken"]; ok {
+		t.Error("hotel with failed check should not be bookable")
+	}
+	if bookable["a"] != 3 {
+		t.Errorf("expected 3 remaining rooms, got %d", bookable["a"])
+	}
+	if maxInFlight > workers {
+		t.Errorf("expected at most %d concurrent requests, got %d", workers, maxInFlight)
+	}
+}
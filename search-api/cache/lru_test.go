@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	// Usar "a" hace que "b" sea la menos usada
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %v %v", v, ok)
+	}
+	c.Add("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("expected c=3, got %v %v", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	c := NewLRU[string, int](2, 10*time.Millisecond)
+	c.Add("a", 1)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expired entry should not be returned")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expired entry should be removed, got %d entries", c.Len())
+	}
+}
+
+func TestLRUUpdateRefreshesValue(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Add("a", 1)
+	c.Add("a", 2)
+
+	if v, _ := c.Get("a"); v != 2 {
+		t.Errorf("expected updated value 2, got %d", v)
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected 1 entry, got %d", c.Len())
+	}
+}
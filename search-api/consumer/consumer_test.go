@@ -0,0 +1,99 @@
+package consumer
+
+import (
+	"search-api/index"
+	"search-api/memory"
+	"testing"
+)
+
+func TestHandleHotelEventAppliesRevisionsInOrder(t *testing.T) {
+	backend := memory.NewIndex()
+
+	created := HotelEvent{EventType: hotelCreated, HotelID: "h1", Revision: 1, Hotel: &index.Hotel{Name: "Hotel Plaza", City: "Córdoba"}}
+	updated := HotelEvent{EventType: hotelUpdated, HotelID: "h1", Revision: 2, Hotel: &index.Hotel{Name: "Plaza Grand", City: "Córdoba"}}
+
+	if err := handleHotelEvent(backend, created); err != nil {
+		t.Fatal(err)
+	}
+	if err := handleHotelEvent(backend, updated); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un evento viejo que llega tarde no pisa la versión nueva
+	if err := handleHotelEvent(backend, created); err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := backend.Search(index.SearchQuery{Text: "grand", Page: 1, Size: 10})
+	if result.Total != 1 || result.Hits[0].Revision != 2 {
+		t.Fatalf("expected revision 2 to be indexed, got %+v", result.Hits)
+	}
+
+	deleted := HotelEvent{EventType: hotelDeleted, HotelID: "h1", Revision: 3}
+	if err := handleHotelEvent(backend, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := backend.Search(index.SearchQuery{Text: "grand", Page: 1, Size: 10}); result.Total != 0 {
+		t.Error("deleted hotel should not be indexed")
+	}
+
+	// Una actualización anterior al borrado que llega tarde no vuelve a crear el hotel
+	if err := handleHotelEvent(backend, updated); err != nil {
+		t.Fatal(err)
+	}
+	if revision, found, _ := backend.Revision("h1"); !found || revision != 3 {
+		t.Errorf("expected the deletion revision to be kept, got %d %v", revision, found)
+	}
+	if result, _ := backend.Search(index.SearchQuery{Text: "grand", Page: 1, Size: 10}); result.Total != 0 {
+		t.Error("a stale update should not re-create a deleted hotel")
+	}
+}
+
+func TestHandleHotelEventRejectsInvalidEvents(t *testing.T) {
+	backend := memory.NewIndex()
+
+	if err := handleHotelEvent(backend, HotelEvent{EventType: hotelCreated, Revision: 1}); err == nil {
+		t.Error("expected error for event without hotel_id")
+	}
+	if err := handleHotelEvent(backend, HotelEvent{EventType: hotelUpdated, HotelID: "h1", Revision: 1}); err == nil {
+		t.Error("expected error for update without hotel data")
+	}
+	if err := handleHotelEvent(backend, HotelEvent{EventType: "hotel.renamed", HotelID: "h1", Revision: 1}); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+}
+
+func TestHotelCreatedEventFromFirstVersionMessages(t *testing.T) {
+	backend := memory.NewIndex()
+
+	// Mensaje tal como lo publicaba la primera versión de hotel-api en la cola hotel_created
+	body := []byte(`{"id":"h1","name":"Hotel Plaza","address":"San Martín 100","city":"Córdoba","country":"Argentina","amenities":["wifi"]}`)
+	event, err := hotelCreatedEvent(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handleHotelEvent(backend, event); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := backend.Search(index.SearchQuery{Text: "plaza", Page: 1, Size: 10}); result.Total != 1 {
+		t.Fatalf("expected the migrated hotel to be indexed, got %+v", result.Hits)
+	}
+
+	// Un hotel que ya cambió después no vuelve a la versión de la cola vieja
+	updated := HotelEvent{EventType: hotelUpdated, HotelID: "h1", Revision: 2, Hotel: &index.Hotel{Name: "Plaza Grand", City: "Córdoba"}}
+	if err := handleHotelEvent(backend, updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := handleHotelEvent(backend, event); err != nil {
+		t.Fatal(err)
+	}
+	if revision, _, _ := backend.Revision("h1"); revision != 2 {
+		t.Errorf("expected revision 2 to be kept, got %d", revision)
+	}
+
+	for _, invalid := range []string{`not json`, `{"name":"Sin id"}`} {
+		if _, err := hotelCreatedEvent([]byte(invalid)); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package consumer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// DeadLetter es un mensaje de hotel que no se pudo indexar
+type DeadLetter struct {
+	MessageID  string          `json:"message_id"`
+	RoutingKey string          `json:"routing_key"`
+	Error      string          `json:"error,omitempty"`
+	Attempts   int32           `json:"attempts"`
+	FailedAt   *time.Time      `json:"failed_at,omitempty"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// declareDeadLetterQueue declara el exchange y la cola de mensajes fallidos
+func declareDeadLetterQueue(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(deadLetterExchange, "fanout", true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.QueueDeclare(deadLetterQueue, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(deadLetterQueue, "", deadLetterExchange, false, nil)
+}
+
+// deadLetter manda el mensaje a la dead-letter queue con el motivo del fallo y lo confirma
+func deadLetter(ch *amqp.Channel, msg amqp.Delivery, cause error, attempts int) {
+	// Los mensajes reenviados desde la DLQ llegan por la cola directa; el tipo conserva el evento original
+	routingKey := msg.RoutingKey
+	if msg.Type != "" {
+		routingKey = msg.Type
+	}
+
+	err := ch.Publish(deadLetterExchange, routingKey, false, false, amqp.Publishing{
+		ContentType:  msg.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+		Type:         msg.Type,
+		Body:         msg.Body,
+		Headers: amqp.Table{
+			"x-error":       cause.Error(),
+			"x-attempts":    int32(attempts),
+			"x-failed-at":   time.Now().UTC(),
+			"x-routing-key": routingKey,
+		},
+	})
+	if err != nil {
+		// Si no se pudo publicar, el broker lo manda a la DLX configurada en la cola
+		log.Printf("Error al publicar en la dead-letter queue: %v", err)
+		if err := msg.Nack(false, false); err != nil {
+			log.Printf("Error al rechazar el mensaje: %v", err)
+		}
+		return
+	}
+
+	if err := msg.Ack(false); err != nil {
+		log.Printf("Error al confirmar el mensaje: %v", err)
+	}
+}
+
+// openAdminChannel abre un canal propio para las operaciones de administración
+func openAdminChannel() (*amqp.Channel, error) {
+	if conn == nil || conn.IsClosed() {
+		return nil, fmt.Errorf("la conexión con RabbitMQ no está disponible")
+	}
+	return conn.Channel()
+}
+
+// toDeadLetter arma la vista de un mensaje de la dead-letter queue
+func toDeadLetter(msg amqp.Delivery) DeadLetter {
+	letter := DeadLetter{
+		MessageID:  msg.MessageId,
+		RoutingKey: msg.RoutingKey,
+		Body:       json.RawMessage(msg.Body),
+	}
+
+	if key, ok := msg.Headers["x-routing-key"].(string); ok {
+		letter.RoutingKey = key
+	}
+	if cause, ok := msg.Headers["x-error"].(string); ok {
+		letter.Error = cause
+	}
+	if attempts, ok := msg.Headers["x-attempts"].(int32); ok {
+		letter.Attempts = attempts
+	}
+	if failedAt, ok := msg.Headers["x-failed-at"].(time.Time); ok {
+		letter.FailedAt = &failedAt
+	}
+
+	// Mensajes que llegaron por la DLX de la cola en vez de por deadLetter
+	if letter.Error == "" {
+		if deaths, ok := msg.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 {
+			if death, ok := deaths[0].(amqp.Table); ok {
+				letter.Error = fmt.Sprintf("%v", death["reason"])
+			}
+		}
+	}
+
+	if !json.Valid(msg.Body) {
+		body, _ := json.Marshal(string(msg.Body))
+		letter.Body = body
+	}
+
+	return letter
+}
+
+// visitDeadLetters toma hasta limit mensajes de la dead-letter queue y llama a fn con cada uno.
+// Si fn devuelve true el mensaje se confirma (sale de la cola); si no, vuelve a la cola.
+func visitDeadLetters(limit int, fn func(ch *amqp.Channel, msg amqp.Delivery) (bool, error)) error {
+	ch, err := openAdminChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	var keep []amqp.Delivery
+	defer func() {
+		// Devolver a la cola los mensajes que no se procesaron
+		for _, msg := range keep {
+			msg.Nack(false, true)
+		}
+	}()
+
+	for i := 0; limit <= 0 || i < limit; i++ {
+		msg, ok, err := ch.Get(deadLetterQueue, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		done, err := fn(ch, msg)
+		if err != nil {
+			keep = append(keep, msg)
+			return err
+		}
+		if !done {
+			keep = append(keep, msg)
+			continue
+		}
+		if err := msg.Ack(false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListDeadLetters devuelve hasta limit mensajes de la dead-letter queue sin sacarlos de la cola
+func ListDeadLetters(limit int) ([]DeadLetter, error) {
+	letters := []DeadLetter{}
+	err := visitDeadLetters(limit, func(_ *amqp.Channel, msg amqp.Delivery) (bool, error) {
+		letters = append(letters, toDeadLetter(msg))
+		return false, nil
+	})
+	return letters, err
+}
+
+// ReplayDeadLetters vuelve a encolar los mensajes fallidos en la cola de search-api.
+// Si messageID no está vacío solo se reprocesa ese mensaje. Devuelve la cantidad reenviada.
+func ReplayDeadLetters(messageID string) (int, error) {
+	replayed := 0
+	err := visitDeadLetters(0, func(ch *amqp.Channel, msg amqp.Delivery) (bool, error) {
+		if messageID != "" && msg.MessageId != messageID {
+			return false, nil
+		}
+
+		routingKey := msg.RoutingKey
+		if key, ok := msg.Headers["x-routing-key"].(string); ok {
+			routingKey = key
+		}
+
+		// Publicar directo en la cola de search-api para no afectar a otros consumidores
+		err := ch.Publish("", hotelEventsQueue, false, false, amqp.Publishing{
+			ContentType:  msg.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    msg.MessageId,
+			Timestamp:    msg.Timestamp,
+			Type:         routingKey,
+			Body:         msg.Body,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		replayed++
+		return true, nil
+	})
+	return replayed, err
+}
+
+// PurgeDeadLetters descarta los mensajes fallidos.
+// Si messageID no está vacío solo se descarta ese mensaje. Devuelve la cantidad descartada.
+func PurgeDeadLetters(messageID string) (int, error) {
+	if messageID == "" {
+		ch, err := openAdminChannel()
+		if err != nil {
+			return 0, err
+		}
+		defer ch.Close()
+		return ch.QueuePurge(deadLetterQueue, false)
+	}
+
+	purged := 0
+	err := visitDeadLetters(0, func(_ *amqp.Channel, msg amqp.Delivery) (bool, error) {
+		if msg.MessageId != messageID {
+			return false, nil
+		}
+		purged++
+		return true, nil
+	})
+	return purged, err
+}
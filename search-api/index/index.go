@@ -0,0 +1,205 @@
+package index
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Hotel es el documento de un hotel en el índice de búsqueda
+type Hotel struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address,omitempty"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Amenities []string `json:"amenities,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Revision  int64    `json:"revision,omitempty"`
+
+	// Promedio y cantidad de reseñas aprobadas; se mantienen en hotel-api
+	RatingAverage float64 `json:"rating_average,omitempty"`
+	ReviewCount   int     `json:"review_count,omitempty"`
+}
+
+// HasLocation indica si el hotel tiene coordenadas
+func (h Hotel) HasLocation() bool {
+	return h.Latitude != nil && h.Longitude != nil
+}
+
+// Hit es un hotel encontrado por una búsqueda
+type Hit struct {
+	Hotel
+	DistanceKm *float64 `json:"distance_km,omitempty"` // Solo en búsquedas con lat y lon
+}
+
+// Modos de filtrado por amenities
+const (
+	AmenitiesAll = "all" // el hotel tiene que tener todas las amenities pedidas
+	AmenitiesAny = "any" // alcanza con que tenga alguna
+)
+
+// Ordenamientos permitidos. Siempre se desempata por id para que los cursores sean estables.
+const (
+	SortRelevance = "relevance"
+	SortNameAsc   = "name_asc"
+	SortNameDesc  = "name_desc"
+	SortDistance  = "distance" // requiere un punto de referencia
+	SortRating    = "rating"   // mejor calificados primero; a igual promedio, los de más reseñas
+)
+
+// Campos sobre los que se calculan los facets
+var FacetFields = []string{"city", "country", "amenities"}
+
+// IsValidSort indica si el ordenamiento pedido está permitido
+func IsValidSort(sort string) bool {
+	switch sort {
+	case SortRelevance, SortNameAsc, SortNameDesc, SortDistance, SortRating:
+		return true
+	}
+	return false
+}
+
+// SearchQuery son los parámetros de una búsqueda de hoteles
+type SearchQuery struct {
+	Text          string
+	City          string
+	Country       string
+	Amenities     []string
+	AmenitiesMode string
+	Sort          string
+	Page          int
+	Size          int
+	Cursor        string // "*" para empezar a paginar con cursores
+	Geo           *GeoFilter
+	MinRating     float64 // 0 para no filtrar por calificación
+}
+
+// GeoFilter es el punto de referencia de una búsqueda por cercanía
+type GeoFilter struct {
+	Lat      float64
+	Lon      float64
+	RadiusKm float64 // 0 para no filtrar por distancia
+}
+
+// Radio medio de la Tierra en kilómetros, el mismo que usa Solr
+const earthRadiusKm = 6371.0087714
+
+// DistanceKm calcula la distancia en kilómetros entre dos puntos con la fórmula de haversine
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// FacetCount es la cantidad de hoteles para un valor de un facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchResult es la respuesta de una búsqueda de hoteles
+type SearchResult struct {
+	Hits       []Hit                   `json:"hits"`
+	Total      int                     `json:"total"`
+	Page       int                     `json:"page"`
+	Size       int                     `json:"size"`
+	Facets     map[string][]FacetCount `json:"facets"`
+	NextPage   *int                    `json:"next_page"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+// Tipos de entidades que devuelve el autocompletado
+const (
+	SuggestHotel   = "hotel"
+	SuggestCity    = "city"
+	SuggestCountry = "country"
+)
+
+// Suggestion es una sugerencia de autocompletado
+type Suggestion struct {
+	Text   string `json:"text"`
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"` // ID del hotel en las sugerencias de tipo hotel
+	Weight int64  `json:"weight"`
+}
+
+// Backend es un índice de búsqueda de hoteles
+type Backend interface {
+	// Index agrega o reemplaza el documento del hotel
+	Index(hotel Hotel) error
+	// Delete elimina el documento del hotel y recuerda la revisión del borrado para
+	// que un evento anterior que llegue tarde no vuelva a crear el hotel
+	Delete(id string, revision int64) error
+	// Revision devuelve la última revisión del hotel, indexada o borrada; el segundo valor es false si no se conoce
+	Revision(id string) (int64, bool, error)
+	// Search busca hoteles con filtros, facets y paginación
+	Search(query SearchQuery) (*SearchResult, error)
+	// Suggest devuelve sugerencias de hoteles, ciudades y países para el prefijo
+	Suggest(prefix string, limit int) ([]Suggestion, error)
+	// NewBuild prepara un índice vacío para reconstruirlo sin afectar las búsquedas
+	NewBuild() (Build, error)
+}
+
+// Build es un índice en construcción que reemplaza al vigente al publicarse
+type Build interface {
+	// Name identifica el índice en construcción
+	Name() string
+	// Add indexa un lote de hoteles
+	Add(hotels []Hotel) error
+	// Delete elimina un hotel del índice en construcción recordando la revisión del borrado
+	Delete(id string, revision int64) error
+	// Publish hace que las búsquedas pasen a usar el índice nuevo
+	Publish() error
+	// Discard descarta el índice en construcción
+	Discard() error
+}
+
+// NextPage devuelve el número de la página siguiente o nil si no hay más resultados
+func NextPage(page, size, total int) *int {
+	if page*size >= total {
+		return nil
+	}
+	next := page + 1
+	return &next
+}
+
+// RankSuggestions ordena las sugerencias (primero las que empiezan con el prefijo, después
+// por peso y texto), elimina repetidas y se queda con las primeras limit.
+func RankSuggestions(prefix string, suggestions []Suggestion, limit int) []Suggestion {
+	prefix = strings.ToLower(prefix)
+	startsWith := func(s Suggestion) bool {
+		return strings.HasPrefix(strings.ToLower(s.Text), prefix)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if startsWith(a) != startsWith(b) {
+			return startsWith(a)
+		}
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		return strings.ToLower(a.Text) < strings.ToLower(b.Text)
+	})
+
+	ranked := []Suggestion{}
+	seen := make(map[string]bool)
+	for _, s := range suggestions {
+		key := s.Type + "|" + strings.ToLower(s.Text) + "|" + s.ID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ranked = append(ranked, s)
+		if len(ranked) == limit {
+			break
+		}
+	}
+
+	return ranked
+}
@@ -0,0 +1,24 @@
+package index
+
+import "testing"
+
+func TestRankSuggestions(t *testing.T) {
+	suggestions := []Suggestion{
+		{Text: "Gran Hotel Buenos Aires", Type: SuggestHotel, ID: "1", Weight: 9},
+		{Text: "Buenos Aires", Type: SuggestCity, Weight: 5},
+		{Text: "Buenos Aires", Type: SuggestCity, Weight: 5},
+		{Text: "Bueno Stay", Type: SuggestHotel, ID: "2", Weight: 1},
+	}
+
+	ranked := RankSuggestions("buen", suggestions, 2)
+
+	if len(ranked) != 2 {
+		t.Fatalf("expected 2 suggestions, got %d", len(ranked))
+	}
+	if ranked[0].Text != "Buenos Aires" || ranked[0].Type != SuggestCity {
+		t.Errorf("expected prefix match with highest weight first, got %+v", ranked[0])
+	}
+	if ranked[1].Text != "Bueno Stay" {
+		t.Errorf("expected duplicates removed and prefix matches before infix ones, got %+v", ranked[1])
+	}
+}
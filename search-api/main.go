@@ -1,35 +1,121 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"search-api/consumer"
+	"search-api/index"
+	"search-api/memory"
+	"search-api/middleware"
+	"search-api/reindex"
 	"search-api/solr"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
 )
 
-func searchHandler(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		http.Error(w, "El parámetro 'q' es requerido", http.StatusBadRequest)
-		return
+// Cada cuánto se reconstruye el autocompletado de Solr si no se configura SUGGEST_BUILD_INTERVAL_MINUTES
+const defaultSuggestBuildInterval = 10 * time.Minute
+
+// Índice de hoteles que usan los handlers y el consumidor
+var searchIndex index.Backend
+
+// newSearchIndex crea el índice configurado en SEARCH_BACKEND: "solr" (por defecto) o "memory"
+func newSearchIndex() (index.Backend, error) {
+	switch backend := os.Getenv("SEARCH_BACKEND"); backend {
+	case "", "solr":
+		solrURL := os.Getenv("SOLR_URL")
+		if solrURL == "" {
+			solrURL = "http://localhost:8983/solr"
+		}
+		alias := os.Getenv("SOLR_ALIAS")
+		if alias == "" {
+			alias = "hotel_core"
+		}
+		return solr.NewClient(solrURL, alias), nil
+	case "memory":
+		return memory.NewIndex(), nil
+	default:
+		return nil, fmt.Errorf("SEARCH_BACKEND desconocido: %s", backend)
 	}
+}
 
-	results, err := solr.SearchHotels(query)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al buscar hoteles: %s", err), http.StatusInternalServerError)
-		return
+// buildSuggestersEvery reconstruye el autocompletado de Solr periódicamente para que incluya los
+// hoteles que el consumidor indexó desde la última construcción
+func buildSuggestersEvery(client *solr.Client) {
+	interval := defaultSuggestBuildInterval
+	if minutes, err := strconv.Atoi(os.Getenv("SUGGEST_BUILD_INTERVAL_MINUTES")); err == nil && minutes > 0 {
+		interval = time.Duration(minutes) * time.Minute
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
+	go func() {
+		for range time.Tick(interval) {
+			if err := client.BuildSuggesters(); err != nil {
+				log.Printf("No se pudo construir el autocompletado: %v", err)
+			}
+		}
+	}()
+}
+
+// runReindexCommand ejecuta la reindexación desde la línea de comandos: search-api reindex.
+// Solo se repiten sobre el índice nuevo los eventos que consume este mismo proceso, por lo que
+// con el servidor en marcha conviene usar POST /admin/reindex.
+func runReindexCommand() {
+	fmt.Println("Reindexando hoteles desde hotel-api...")
+	progress, err := reindex.Run(searchIndex)
+	if progress != nil {
+		fmt.Printf("Índice: %s\nHoteles: %d, indexados: %d, con error: %d\n", progress.Collection, progress.Total, progress.Indexed, progress.Failed)
+		for _, message := range progress.Errors {
+			fmt.Println("  -", message)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Error en la reindexación: %v", err)
+	}
 }
 
 func main() {
+	// Cargar la configuración del .env (SECRET, URLs de las otras APIs y de Solr).
+	// Sin el archivo se usan las variables de entorno del proceso.
+	if err := godotenv.Load(); err != nil {
+		log.Printf("No se encontró el archivo .env, se usan las variables de entorno: %v", err)
+	}
+
+	var err error
+	searchIndex, err = newSearchIndex()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Subir el esquema de hoteles antes de que una reindexación cree colecciones con él
+	if client, ok := searchIndex.(*solr.Client); ok {
+		if err := client.UploadConfigSet(); err != nil {
+			log.Printf("No se pudo subir el configset de Solr: %v", err)
+		}
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		runReindexCommand()
+		return
+	}
+
+	// El índice en memoria arranca vacío, así que se carga desde hotel-api
+	if _, ok := searchIndex.(*memory.Index); ok {
+		if _, err := reindex.Start(searchIndex); err != nil {
+			log.Printf("No se pudo cargar el índice en memoria: %v", err)
+		}
+	}
+
+	if client, ok := searchIndex.(*solr.Client); ok {
+		buildSuggestersEvery(client)
+	}
+
 	fmt.Println("Iniciando el consumidor de RabbitMQ...")
 	go func() {
-		err := consumer.Consume()
+		err := consumer.Consume(searchIndex)
 		if err != nil {
 			log.Fatalf("Error al consumir los mensajes: %v", err)
 		}
@@ -38,6 +124,15 @@ func main() {
 	// Nuevo endpoint para búsquedas
 	http.HandleFunc("/search", searchHandler)
 
+	// Autocompletado mientras el usuario escribe
+	http.HandleFunc("/suggest", suggestHandler)
+
+	// Administración de los mensajes de hoteles que no se pudieron indexar
+	http.HandleFunc("/admin/dead-letters", middleware.RequireAdmin(deadLettersHandler))
+
+	// Reindexación completa de hoteles desde hotel-api
+	http.HandleFunc("/admin/reindex", middleware.RequireAdmin(reindexHandler))
+
 	fmt.Println("Servidor corriendo en :8082")
 	log.Fatal(http.ListenAndServe(":8082", nil))
 }
@@ -0,0 +1,444 @@
+package memory
+
+import (
+	"fmt"
+	"search-api/index"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Peso de cada campo en la relevancia, equivalente al qf usado con Solr
+var fieldWeights = map[string]float64{
+	"name":      3,
+	"city":      2,
+	"country":   1,
+	"address":   1,
+	"amenities": 1,
+}
+
+// Index es un índice invertido en memoria. Sirve para tests y para
+// instalaciones chicas que no quieren depender de Solr.
+type Index struct {
+	mu    sync.RWMutex
+	store *store
+}
+
+// store son los documentos y el índice invertido de una versión del índice
+type store struct {
+	docs       map[string]index.Hotel
+	postings   map[string]map[string]float64 // término -> id del hotel -> peso
+	tombstones map[string]int64              // id del hotel eliminado -> revisión del borrado
+}
+
+func newStore() *store {
+	return &store{
+		docs:       make(map[string]index.Hotel),
+		postings:   make(map[string]map[string]float64),
+		tombstones: make(map[string]int64),
+	}
+}
+
+// NewIndex crea un índice vacío
+func NewIndex() *Index {
+	return &Index{store: newStore()}
+}
+
+// tokenize separa el texto en términos en minúscula
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// fieldValues devuelve el texto de cada campo buscable del hotel
+func fieldValues(hotel index.Hotel) map[string][]string {
+	return map[string][]string{
+		"name":      {hotel.Name},
+		"city":      {hotel.City},
+		"country":   {hotel.Country},
+		"address":   {hotel.Address},
+		"amenities": hotel.Amenities,
+	}
+}
+
+// add indexa el hotel reemplazando la versión anterior
+func (s *store) add(hotel index.Hotel) {
+	s.remove(hotel.ID)
+	delete(s.tombstones, hotel.ID)
+	s.docs[hotel.ID] = hotel
+
+	for field, values := range fieldValues(hotel) {
+		for _, value := range values {
+			for _, term := range tokenize(value) {
+				if s.postings[term] == nil {
+					s.postings[term] = make(map[string]float64)
+				}
+				s.postings[term][hotel.ID] += fieldWeights[field]
+			}
+		}
+	}
+}
+
+// remove quita el hotel del índice
+func (s *store) remove(id string) {
+	hotel, ok := s.docs[id]
+	if !ok {
+		return
+	}
+	delete(s.docs, id)
+
+	for _, values := range fieldValues(hotel) {
+		for _, value := range values {
+			for _, term := range tokenize(value) {
+				delete(s.postings[term], id)
+				if len(s.postings[term]) == 0 {
+					delete(s.postings, term)
+				}
+			}
+		}
+	}
+}
+
+// Index agrega o reemplaza el documento del hotel
+func (m *Index) Index(hotel index.Hotel) error {
+	if hotel.ID == "" {
+		return fmt.Errorf("el hotel no tiene id")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store.add(hotel)
+	return nil
+}
+
+// Delete elimina el documento del hotel y recuerda la revisión del borrado
+func (m *Index) Delete(id string, revision int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store.remove(id)
+	m.store.tombstones[id] = revision
+	return nil
+}
+
+// Revision devuelve la revisión indexada del hotel o la del borrado si se eliminó
+func (m *Index) Revision(id string) (int64, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if hotel, ok := m.store.docs[id]; ok {
+		return hotel.Revision, true, nil
+	}
+	revision, ok := m.store.tombstones[id]
+	return revision, ok, nil
+}
+
+// matchText devuelve los hoteles que contienen todos los términos con su puntaje.
+// El último término también se busca como prefijo, igual que con Solr.
+func (s *store) matchText(text string) map[string]float64 {
+	terms := tokenize(text)
+	scores := make(map[string]float64)
+	if len(terms) == 0 {
+		for id := range s.docs {
+			scores[id] = 0
+		}
+		return scores
+	}
+
+	for i, term := range terms {
+		matches := make(map[string]float64)
+		for id, weight := range s.postings[term] {
+			matches[id] += weight
+		}
+		if i == len(terms)-1 {
+			for indexed, postings := range s.postings {
+				if indexed != term && strings.HasPrefix(indexed, term) {
+					for id, weight := range postings {
+						matches[id] += weight
+					}
+				}
+			}
+		}
+
+		if i == 0 {
+			scores = matches
+			continue
+		}
+		for id := range scores {
+			if weight, ok := matches[id]; ok {
+				scores[id] += weight
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	return scores
+}
+
+// distance devuelve la distancia del hotel al punto de la búsqueda, o nil si no aplica
+func distance(hotel index.Hotel, geo *index.GeoFilter) *float64 {
+	if geo == nil || !hotel.HasLocation() {
+		return nil
+	}
+	km := index.DistanceKm(geo.Lat, geo.Lon, *hotel.Latitude, *hotel.Longitude)
+	return &km
+}
+
+// matchesFilters indica si el hotel cumple los filtros, salvo el del campo excluido.
+// El filtro por radio se aplica siempre, igual que en Solr.
+func matchesFilters(hotel index.Hotel, query index.SearchQuery, excluded string) bool {
+	if query.Geo != nil && query.Geo.RadiusKm > 0 {
+		km := distance(hotel, query.Geo)
+		if km == nil || *km > query.Geo.RadiusKm {
+			return false
+		}
+	}
+	if query.MinRating > 0 && hotel.RatingAverage < query.MinRating {
+		return false
+	}
+	if excluded != "city" && query.City != "" && !strings.EqualFold(hotel.City, query.City) {
+		return false
+	}
+	if excluded != "country" && query.Country != "" && !strings.EqualFold(hotel.Country, query.Country) {
+		return false
+	}
+	if excluded != "amenities" && len(query.Amenities) > 0 {
+		found := 0
+		for _, wanted := range query.Amenities {
+			for _, amenity := range hotel.Amenities {
+				if strings.EqualFold(amenity, wanted) {
+					found++
+					break
+				}
+			}
+		}
+		if query.AmenitiesMode == index.AmenitiesAny && found == 0 {
+			return false
+		}
+		if query.AmenitiesMode != index.AmenitiesAny && found < len(query.Amenities) {
+			return false
+		}
+	}
+	return true
+}
+
+// facetValues devuelve los valores del hotel para un campo de facet
+func facetValues(hotel index.Hotel, field string) []string {
+	switch field {
+	case "city":
+		return []string{hotel.City}
+	case "country":
+		return []string{hotel.Country}
+	case "amenities":
+		return hotel.Amenities
+	}
+	return nil
+}
+
+// facets cuenta los valores de cada campo. Cada facet ignora su propio filtro
+// para que se puedan elegir varios valores, como hace Solr con tag/ex.
+func (s *store) facets(scores map[string]float64, query index.SearchQuery) map[string][]index.FacetCount {
+	facets := make(map[string][]index.FacetCount, len(index.FacetFields))
+	for _, field := range index.FacetFields {
+		counts := make(map[string]int)
+		for id := range scores {
+			hotel := s.docs[id]
+			if !matchesFilters(hotel, query, field) {
+				continue
+			}
+			for _, value := range facetValues(hotel, field) {
+				if value != "" {
+					counts[value]++
+				}
+			}
+		}
+
+		list := []index.FacetCount{}
+		for value, count := range counts {
+			list = append(list, index.FacetCount{Value: value, Count: count})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].Value < list[j].Value
+		})
+		facets[field] = list
+	}
+	return facets
+}
+
+// Search busca hoteles con filtros, facets y paginación
+func (m *Index) Search(query index.SearchQuery) (*index.SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scores := m.store.matchText(query.Text)
+
+	hits := []index.Hit{}
+	for id := range scores {
+		if hotel := m.store.docs[id]; matchesFilters(hotel, query, "") {
+			hits = append(hits, index.Hit{Hotel: hotel, DistanceKm: distance(hotel, query.Geo)})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		switch query.Sort {
+		case index.SortNameAsc:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case index.SortNameDesc:
+			if a.Name != b.Name {
+				return a.Name > b.Name
+			}
+		case index.SortRating:
+			if a.RatingAverage != b.RatingAverage {
+				return a.RatingAverage > b.RatingAverage
+			}
+			if a.ReviewCount != b.ReviewCount {
+				return a.ReviewCount > b.ReviewCount
+			}
+		case index.SortDistance:
+			// Los hoteles sin coordenadas van al final
+			if (a.DistanceKm == nil) != (b.DistanceKm == nil) {
+				return a.DistanceKm != nil
+			}
+			if a.DistanceKm != nil && *a.DistanceKm != *b.DistanceKm {
+				return *a.DistanceKm < *b.DistanceKm
+			}
+		default:
+			if scores[a.ID] != scores[b.ID] {
+				return scores[a.ID] > scores[b.ID]
+			}
+		}
+		return a.ID < b.ID
+	})
+
+	result := &index.SearchResult{
+		Total:  len(hits),
+		Page:   query.Page,
+		Size:   query.Size,
+		Facets: m.store.facets(scores, query),
+	}
+
+	// Con cursores el cursor es simplemente el desplazamiento de la página siguiente
+	start := (query.Page - 1) * query.Size
+	if query.Cursor != "" {
+		start = 0
+		if query.Cursor != "*" {
+			offset, err := strconv.Atoi(query.Cursor)
+			if err != nil || offset < 0 {
+				return nil, fmt.Errorf("cursor inválido: %s", query.Cursor)
+			}
+			start = offset
+		}
+	}
+
+	end := start + query.Size
+	if start > len(hits) {
+		start = len(hits)
+	}
+	if end > len(hits) {
+		end = len(hits)
+	}
+	result.Hits = hits[start:end]
+
+	result.NextPage = index.NextPage(query.Page, query.Size, result.Total)
+	if query.Cursor != "" && end < len(hits) {
+		result.NextCursor = strconv.Itoa(end)
+	}
+
+	return result, nil
+}
+
+// Suggest devuelve sugerencias de nombres de hoteles, ciudades y países para el prefijo.
+// El peso de ciudades y países es la cantidad de hoteles que tienen.
+func (m *Index) Suggest(prefix string, limit int) ([]index.Suggestion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lower := strings.ToLower(strings.TrimSpace(prefix))
+	matches := func(value string) bool {
+		if strings.HasPrefix(strings.ToLower(value), lower) {
+			return true
+		}
+		for _, term := range tokenize(value) {
+			if strings.HasPrefix(term, lower) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var suggestions []index.Suggestion
+	places := make(map[index.Suggestion]int64)
+	for _, hotel := range m.store.docs {
+		if matches(hotel.Name) {
+			suggestions = append(suggestions, index.Suggestion{Text: hotel.Name, Type: index.SuggestHotel, ID: hotel.ID, Weight: 1})
+		}
+		if hotel.City != "" && matches(hotel.City) {
+			places[index.Suggestion{Text: hotel.City, Type: index.SuggestCity}]++
+		}
+		if hotel.Country != "" && matches(hotel.Country) {
+			places[index.Suggestion{Text: hotel.Country, Type: index.SuggestCountry}]++
+		}
+	}
+	for place, count := range places {
+		place.Weight = count
+		suggestions = append(suggestions, place)
+	}
+
+	return index.RankSuggestions(prefix, suggestions, limit), nil
+}
+
+// build es una copia nueva del índice que reemplaza a la vigente al publicarse
+type build struct {
+	target *Index
+	store  *store
+}
+
+// NewBuild prepara un índice vacío para reconstruirlo sin afectar las búsquedas
+func (m *Index) NewBuild() (index.Build, error) {
+	return &build{target: m, store: newStore()}, nil
+}
+
+// Name identifica el índice en construcción
+func (b *build) Name() string {
+	return "memory"
+}
+
+// Add indexa un lote de hoteles en la copia nueva
+func (b *build) Add(hotels []index.Hotel) error {
+	for _, hotel := range hotels {
+		if hotel.ID == "" {
+			return fmt.Errorf("el hotel no tiene id")
+		}
+		b.store.add(hotel)
+	}
+	return nil
+}
+
+// Delete elimina un hotel de la copia nueva recordando la revisión del borrado
+func (b *build) Delete(id string, revision int64) error {
+	b.store.remove(id)
+	b.store.tombstones[id] = revision
+	return nil
+}
+
+// Publish reemplaza el índice vigente por la copia nueva
+func (b *build) Publish() error {
+	b.target.mu.Lock()
+	defer b.target.mu.Unlock()
+	b.target.store = b.store
+	return nil
+}
+
+// Discard descarta la copia nueva
+func (b *build) Discard() error {
+	return nil
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"search-api/index"
+	"testing"
+)
+
+func seed(t *testing.T) *Index {
+	t.Helper()
+	m := NewIndex()
+	hotels := []index.Hotel{
+		{ID: "1", Name: "Hotel Plaza", City: "Córdoba", Country: "Argentina", Amenities: []string{"Wifi", "Pool"}},
+		{ID: "2", Name: "Plaza Suites", City: "Buenos Aires", Country: "Argentina", Amenities: []string{"Wifi"}},
+		{ID: "3", Name: "Gran Hotel", City: "Montevideo", Country: "Uruguay", Amenities: []string{"Pool", "Gym"}},
+	}
+	for _, hotel := range hotels {
+		if err := m.Index(hotel); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func ids(hits []index.Hit) []string {
+	result := make([]string, len(hits))
+	for i, hotel := range hits {
+		result[i] = hotel.ID
+	}
+	return result
+}
+
+func TestSearchTextRanksNameMatchesFirst(t *testing.T) {
+	m := seed(t)
+
+	result, err := m.Search(index.SearchQuery{Text: "pla", Sort: index.SortRelevance, Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("expected 2 hits for prefix 'pla', got %v", ids(result.Hits))
+	}
+
+	result, _ = m.Search(index.SearchQuery{Text: "hotel argentina", Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 1 || got[0] != "1" {
+		t.Errorf("all terms must match, got %v", got)
+	}
+}
+
+func TestSearchFiltersAndFacets(t *testing.T) {
+	m := seed(t)
+
+	result, _ := m.Search(index.SearchQuery{Country: "argentina", Amenities: []string{"wifi", "pool"}, AmenitiesMode: index.AmenitiesAll, Sort: index.SortNameAsc, Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 1 || got[0] != "1" {
+		t.Errorf("expected only hotel 1 with all amenities, got %v", got)
+	}
+
+	result, _ = m.Search(index.SearchQuery{Amenities: []string{"Gym", "Wifi"}, AmenitiesMode: index.AmenitiesAny, Sort: index.SortNameAsc, Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 3 {
+		t.Errorf("expected 3 hotels with any amenity, got %v", got)
+	}
+
+	// El facet de país ignora su propio filtro para poder elegir otro país
+	result, _ = m.Search(index.SearchQuery{Country: "Uruguay", Page: 1, Size: 10})
+	if len(result.Facets["country"]) != 2 || result.Facets["country"][0] != (index.FacetCount{Value: "Argentina", Count: 2}) {
+		t.Errorf("unexpected country facets: %v", result.Facets["country"])
+	}
+	if len(result.Facets["city"]) != 1 || result.Facets["city"][0].Value != "Montevideo" {
+		t.Errorf("unexpected city facets: %v", result.Facets["city"])
+	}
+}
+
+func TestSearchRating(t *testing.T) {
+	m := seed(t)
+	m.Index(index.Hotel{ID: "1", Name: "Hotel Plaza", City: "Córdoba", Country: "Argentina", RatingAverage: 4.2, ReviewCount: 10})
+	m.Index(index.Hotel{ID: "2", Name: "Plaza Suites", City: "Buenos Aires", Country: "Argentina", RatingAverage: 4.8, ReviewCount: 3})
+	m.Index(index.Hotel{ID: "3", Name: "Gran Hotel", City: "Montevideo", Country: "Uruguay", RatingAverage: 4.2, ReviewCount: 25})
+
+	result, _ := m.Search(index.SearchQuery{Sort: index.SortRating, Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 3 || got[0] != "2" || got[1] != "3" || got[2] != "1" {
+		t.Errorf("expected hotels by rating and then by review count, got %v", got)
+	}
+
+	result, _ = m.Search(index.SearchQuery{MinRating: 4.5, Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 1 || got[0] != "2" {
+		t.Errorf("expected only hotel 2 with rating 4.5 or more, got %v", got)
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	m := seed(t)
+
+	result, _ := m.Search(index.SearchQuery{Sort: index.SortNameAsc, Page: 1, Size: 2, Cursor: "*"})
+	if got := ids(result.Hits); len(got) != 2 || got[0] != "3" || got[1] != "1" {
+		t.Fatalf("unexpected first page: %v", got)
+	}
+	if result.NextCursor == "" || result.NextPage == nil || *result.NextPage != 2 {
+		t.Fatalf("expected next page and cursor, got %v %v", result.NextPage, result.NextCursor)
+	}
+
+	result, _ = m.Search(index.SearchQuery{Sort: index.SortNameAsc, Page: 2, Size: 2, Cursor: result.NextCursor})
+	if got := ids(result.Hits); len(got) != 1 || got[0] != "2" {
+		t.Errorf("unexpected second page: %v", got)
+	}
+	if result.NextCursor != "" {
+		t.Errorf("last page should not have a cursor, got %s", result.NextCursor)
+	}
+}
+
+func TestSearchGeo(t *testing.T) {
+	m := seed(t)
+	coordinates := map[string][2]float64{
+		"1": {-31.42, -64.18}, // Córdoba
+		"2": {-34.60, -58.38}, // Buenos Aires
+		"3": {-34.90, -56.16}, // Montevideo
+	}
+	for id, point := range coordinates {
+		hotel := m.store.docs[id]
+		lat, lon := point[0], point[1]
+		hotel.Latitude, hotel.Longitude = &lat, &lon
+		m.Index(hotel)
+	}
+	m.Index(index.Hotel{ID: "4", Name: "Sin Mapa", City: "Rosario", Country: "Argentina"})
+
+	geo := &index.GeoFilter{Lat: -34.60, Lon: -58.38}
+	result, _ := m.Search(index.SearchQuery{Sort: index.SortDistance, Page: 1, Size: 10, Geo: geo})
+	if got := ids(result.Hits); len(got) != 4 || got[0] != "2" || got[1] != "3" || got[2] != "1" || got[3] != "4" {
+		t.Fatalf("unexpected distance order: %v", got)
+	}
+	if result.Hits[0].DistanceKm == nil || *result.Hits[0].DistanceKm > 0.001 {
+		t.Errorf("expected distance 0 for the first hit, got %v", result.Hits[0].DistanceKm)
+	}
+	if km := *result.Hits[1].DistanceKm; km < 190 || km > 215 {
+		t.Errorf("Buenos Aires - Montevideo should be about 200 km, got %f", km)
+	}
+	if result.Hits[3].DistanceKm != nil {
+		t.Errorf("hotel without coordinates should not have a distance")
+	}
+
+	// El radio excluye a los hoteles lejanos y a los que no tienen coordenadas, también en los facets
+	geo.RadiusKm = 250
+	result, _ = m.Search(index.SearchQuery{Sort: index.SortDistance, Page: 1, Size: 10, Geo: geo})
+	if got := ids(result.Hits); len(got) != 2 || got[0] != "2" || got[1] != "3" {
+		t.Errorf("unexpected hits within radius: %v", got)
+	}
+	if len(result.Facets["city"]) != 2 {
+		t.Errorf("unexpected city facets within radius: %v", result.Facets["city"])
+	}
+}
+
+func TestIndexReplacesAndDeletes(t *testing.T) {
+	m := seed(t)
+
+	m.Index(index.Hotel{ID: "2", Name: "Palermo Inn", City: "Buenos Aires", Country: "Argentina", Revision: 2})
+	result, _ := m.Search(index.SearchQuery{Text: "plaza", Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 1 || got[0] != "1" {
+		t.Errorf("old terms of hotel 2 should be gone, got %v", got)
+	}
+	if revision, ok, _ := m.Revision("2"); !ok || revision != 2 {
+		t.Errorf("expected revision 2, got %d %v", revision, ok)
+	}
+
+	m.Delete("1", 5)
+	result, _ = m.Search(index.SearchQuery{Text: "plaza", Page: 1, Size: 10})
+	if result.Total != 0 {
+		t.Errorf("deleted hotel should not be found, got %v", ids(result.Hits))
+	}
+	if revision, ok, _ := m.Revision("1"); !ok || revision != 5 {
+		t.Errorf("expected the deletion revision 5, got %d %v", revision, ok)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	m := seed(t)
+
+	suggestions, _ := m.Suggest("ar", 5)
+	if len(suggestions) != 1 || suggestions[0] != (index.Suggestion{Text: "Argentina", Type: index.SuggestCountry, Weight: 2}) {
+		t.Errorf("unexpected suggestions: %v", suggestions)
+	}
+
+	suggestions, _ = m.Suggest("pla", 5)
+	if len(suggestions) != 2 || suggestions[0].ID != "2" || suggestions[0].Type != index.SuggestHotel {
+		t.Errorf("expected hotel names starting with the prefix first, got %v", suggestions)
+	}
+}
+
+func TestBuildPublishReplacesIndex(t *testing.T) {
+	m := seed(t)
+
+	build, _ := m.NewBuild()
+	build.Add([]index.Hotel{{ID: "9", Name: "Nuevo", City: "Salta", Country: "Argentina"}})
+
+	result, _ := m.Search(index.SearchQuery{Page: 1, Size: 10})
+	if result.Total != 3 {
+		t.Fatalf("searches should use the old index until publish, got %d", result.Total)
+	}
+
+	build.Publish()
+	result, _ = m.Search(index.SearchQuery{Page: 1, Size: 10})
+	if got := ids(result.Hits); len(got) != 1 || got[0] != "9" {
+		t.Errorf("expected only the rebuilt hotels, got %v", got)
+	}
+}
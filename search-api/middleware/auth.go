@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// writeError responde con un error en formato JSON
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// RequireAdmin verifica que la cookie Authorization tenga un token válido de un administrador
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("Authorization")
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "No autorizado: Token no encontrado")
+			return
+		}
+
+		secret := os.Getenv("SECRET")
+		if secret == "" {
+			writeError(w, http.StatusInternalServerError, "Error del servidor: JWT secret no configurado")
+			return
+		}
+
+		// Los tokens los emite user-api con los claims sub, role y exp
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(cookie.Value, claims, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(secret), nil
+		})
+		if err != nil || !token.Valid {
+			writeError(w, http.StatusUnauthorized, "No autorizado: Token inválido")
+			return
+		}
+
+		if role, _ := claims["role"].(string); role != "admin" {
+			writeError(w, http.StatusForbidden, "Acceso denegado: Se requieren permisos de administrador")
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,71 @@
+package reindex
+
+import (
+	"search-api/index"
+	"sync"
+)
+
+// Change es un cambio aplicado al índice vigente mientras corre una reindexación.
+// Hotel es nil si el hotel fue eliminado.
+type Change struct {
+	ID       string
+	Revision int64
+	Hotel    *index.Hotel
+}
+
+var (
+	// guard serializa los cambios del consumidor con la publicación del índice nuevo
+	guard sync.Mutex
+	// pending guarda el último cambio por hotel; solo no es nil mientras se construye un índice
+	pending map[string]Change
+)
+
+// Guard ejecuta fn, que modifica el índice vigente, sin que se publique un índice nuevo en el medio.
+// Los cambios informados con track se aplican también sobre el índice en construcción antes de publicarlo,
+// para que no se pierdan eventos consumidos durante la reindexación.
+func Guard(fn func(track func(Change)) error) error {
+	guard.Lock()
+	defer guard.Unlock()
+
+	return fn(func(change Change) {
+		if pending == nil {
+			return
+		}
+		if previous, ok := pending[change.ID]; ok && previous.Revision >= change.Revision {
+			return
+		}
+		pending[change.ID] = change
+	})
+}
+
+// startJournal empieza a registrar los cambios del índice vigente
+func startJournal() {
+	guard.Lock()
+	defer guard.Unlock()
+	pending = map[string]Change{}
+}
+
+// stopJournal deja de registrar cambios; debe llamarse con guard tomado
+func stopJournal() {
+	pending = nil
+}
+
+// replayJournal aplica sobre el índice nuevo los cambios registrados con una revisión mayor a la que
+// se indexó desde hotel-api. Debe llamarse con guard tomado.
+func replayJournal(build index.Build, added map[string]int64) error {
+	for id, change := range pending {
+		if revision, ok := added[id]; ok && revision >= change.Revision {
+			continue
+		}
+		if change.Hotel == nil {
+			if err := build.Delete(id, change.Revision); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := build.Add([]index.Hotel{*change.Hotel}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package reindex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"search-api/index"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Cantidad de hoteles que se piden a hotel-api y se indexan por lote
+const pageSize = 200
+
+// Validez del token de servicio usado para leer hotel-api
+const serviceTokenTTL = time.Minute
+
+// Cantidad máxima de errores que se guardan en el progreso
+const maxReportedErrors = 20
+
+// Estados de una reindexación
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// ErrAlreadyRunning se devuelve si se pide una reindexación mientras hay otra en curso
+var ErrAlreadyRunning = errors.New("ya hay una reindexación en curso")
+
+// Progress es el estado de una reindexación
+type Progress struct {
+	Status     string     `json:"status"`
+	Collection string     `json:"collection"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Total      int        `json:"total"`
+	Pages      int        `json:"pages"`
+	Indexed    int        `json:"indexed"`
+	Failed     int        `json:"failed"`
+	Errors     []string   `json:"errors,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	current *Progress
+)
+
+// Current devuelve una copia del progreso de la última reindexación, o nil si nunca se ejecutó
+func Current() *Progress {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if current == nil {
+		return nil
+	}
+	snapshot := *current
+	snapshot.Errors = append([]string(nil), current.Errors...)
+	return &snapshot
+}
+
+// Start inicia una reindexación del índice en segundo plano
+func Start(backend index.Backend) (*Progress, error) {
+	progress, err := begin()
+	if err != nil {
+		return nil, err
+	}
+
+	go run(backend, progress)
+	return Current(), nil
+}
+
+// Run ejecuta una reindexación completa del índice y espera a que termine
+func Run(backend index.Backend) (*Progress, error) {
+	progress, err := begin()
+	if err != nil {
+		return nil, err
+	}
+
+	err = run(backend, progress)
+	return Current(), err
+}
+
+// begin registra una reindexación nueva si no hay otra en curso
+func begin() (*Progress, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if current != nil && current.Status == StatusRunning {
+		return nil, ErrAlreadyRunning
+	}
+
+	current = &Progress{
+		Status:    StatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	return current, nil
+}
+
+// update aplica un cambio sobre el progreso bajo el lock
+func update(progress *Progress, fn func(p *Progress)) {
+	mu.Lock()
+	defer mu.Unlock()
+	fn(progress)
+}
+
+// recordError suma un error al progreso guardando solo los primeros
+func recordError(progress *Progress, count int, err error) {
+	update(progress, func(p *Progress) {
+		p.Failed += count
+		if len(p.Errors) < maxReportedErrors {
+			p.Errors = append(p.Errors, err.Error())
+		}
+	})
+}
+
+// finish marca el final de la reindexación
+func finish(progress *Progress, err error) error {
+	update(progress, func(p *Progress) {
+		now := time.Now().UTC()
+		p.FinishedAt = &now
+		if err != nil {
+			p.Status = StatusFailed
+			p.Error = err.Error()
+		} else {
+			p.Status = StatusCompleted
+		}
+	})
+
+	if err != nil {
+		log.Printf("Reindexación en %s fallida: %v", progress.Collection, err)
+	} else {
+		log.Printf("Reindexación en %s completada", progress.Collection)
+	}
+	return err
+}
+
+// run crea un índice nuevo, indexa todos los hoteles de hotel-api en él
+// y al terminar lo publica en lugar del vigente (en Solr, moviendo el alias).
+// Los eventos que el consumidor aplica mientras tanto se repiten sobre el índice nuevo antes de publicarlo.
+func run(backend index.Backend, progress *Progress) error {
+	startJournal()
+	defer func() {
+		guard.Lock()
+		stopJournal()
+		guard.Unlock()
+	}()
+
+	build, err := backend.NewBuild()
+	if err != nil {
+		return finish(progress, fmt.Errorf("error al crear el índice nuevo: %v", err))
+	}
+
+	update(progress, func(p *Progress) { p.Collection = build.Name() })
+	log.Printf("Iniciando reindexación en %s", build.Name())
+
+	// Revisión de cada hotel indexado desde hotel-api
+	added := map[string]int64{}
+
+	// Se pide cada página a partir del último ID recibido: si se borra un hotel en el medio,
+	// saltear páginas correría a los siguientes de lugar y alguno no se leería nunca
+	after := ""
+	for page := 1; ; page++ {
+		hotels, total, err := fetchHotelsPage(after)
+		if err != nil {
+			build.Discard()
+			return finish(progress, fmt.Errorf("error al obtener los hoteles de hotel-api: %v", err))
+		}
+
+		update(progress, func(p *Progress) {
+			p.Total = total
+			p.Pages = page
+		})
+
+		if len(hotels) == 0 {
+			break
+		}
+
+		indexBatch(progress, build, hotels)
+		for _, hotel := range hotels {
+			added[hotel.ID] = hotel.Revision
+		}
+		after = hotels[len(hotels)-1].ID
+
+		if len(hotels) < pageSize {
+			break
+		}
+	}
+
+	if err := publish(build, added); err != nil {
+		build.Discard()
+		return finish(progress, err)
+	}
+
+	return finish(progress, nil)
+}
+
+// publish repite los eventos consumidos durante la reindexación y publica el índice nuevo,
+// sin dejar que el consumidor modifique el índice vigente en el medio
+func publish(build index.Build, added map[string]int64) error {
+	guard.Lock()
+	defer guard.Unlock()
+
+	if err := replayJournal(build, added); err != nil {
+		return fmt.Errorf("error al aplicar los eventos recibidos durante la reindexación: %v", err)
+	}
+
+	// Recién ahora las búsquedas pasan a usar el índice nuevo
+	if err := build.Publish(); err != nil {
+		return fmt.Errorf("error al publicar el índice nuevo: %v", err)
+	}
+
+	stopJournal()
+	return nil
+}
+
+// indexBatch indexa un lote y, si se rechaza, reintenta hotel por hotel para contar los errores
+func indexBatch(progress *Progress, build index.Build, hotels []index.Hotel) {
+	if err := build.Add(hotels); err == nil {
+		update(progress, func(p *Progress) { p.Indexed += len(hotels) })
+		return
+	}
+
+	for _, hotel := range hotels {
+		if err := build.Add([]index.Hotel{hotel}); err != nil {
+			recordError(progress, 1, fmt.Errorf("hotel %s: %v", hotel.ID, err))
+			continue
+		}
+		update(progress, func(p *Progress) { p.Indexed++ })
+	}
+}
+
+// serviceToken firma con SECRET un token de corta duración con el que search-api se autentica en hotel-api
+func serviceToken() (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "search-api",
+		"role": "service",
+		"exp":  time.Now().Add(serviceTokenTTL).Unix(),
+	})
+	return token.SignedString([]byte(os.Getenv("SECRET")))
+}
+
+// fetchHotelsPage pide a hotel-api los hoteles siguientes al ID after, ordenados por ID
+// (la primera página si after está vacío).
+// hotel-api requiere autenticación, por lo que se envía un token de servicio firmado con SECRET.
+func fetchHotelsPage(after string) ([]index.Hotel, int, error) {
+	baseURL := os.Getenv("HOTEL_API_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	params := url.Values{}
+	params.Set("size", strconv.Itoa(pageSize))
+	if after != "" {
+		params.Set("after", after)
+	}
+	req, err := http.NewRequest("GET", baseURL+"/hotels/getHotels?"+params.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	token, err := serviceToken()
+	if err != nil {
+		return nil, 0, err
+	}
+	req.AddCookie(&http.Cookie{Name: "Authorization", Value: token})
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("respuesta inesperada de hotel-api: %d", resp.StatusCode)
+	}
+
+	var hotels []index.Hotel
+	if err := json.NewDecoder(resp.Body).Decode(&hotels); err != nil {
+		return nil, 0, err
+	}
+
+	total, _ := strconv.Atoi(resp.Header.Get("X-Total-Count"))
+	return hotels, total, nil
+}
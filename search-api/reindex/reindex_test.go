@@ -0,0 +1,130 @@
+package reindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"search-api/index"
+	"search-api/memory"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestRunReplaysEventsConsumedDuringReindex(t *testing.T) {
+	backend := memory.NewIndex()
+
+	hotels := []index.Hotel{
+		{ID: "1", Name: "Uno", Revision: 1},
+		{ID: "2", Name: "Dos", Revision: 1},
+		{ID: "3", Name: "Tres", Revision: 4},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("Authorization"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// Mientras se lee hotel-api el consumidor aplica cambios sobre el índice vigente
+		Guard(func(track func(Change)) error {
+			updated := index.Hotel{ID: "1", Name: "Uno renovado", Revision: 2}
+			backend.Index(updated)
+			track(Change{ID: "1", Revision: 2, Hotel: &updated})
+			backend.Delete("2", 2)
+			track(Change{ID: "2", Revision: 2})
+			// Evento viejo: el índice nuevo ya tiene una revisión mayor
+			stale := index.Hotel{ID: "3", Name: "Tres viejo", Revision: 3}
+			track(Change{ID: "3", Revision: 3, Hotel: &stale})
+			return nil
+		})
+
+		w.Header().Set("X-Total-Count", "3")
+		json.NewEncoder(w).Encode(hotels)
+	}))
+	defer server.Close()
+	t.Setenv("HOTEL_API_URL", server.URL)
+
+	progress, err := Run(backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if progress.Indexed != 3 {
+		t.Errorf("expected 3 indexed hotels, got %d", progress.Indexed)
+	}
+
+	result, _ := backend.Search(index.SearchQuery{Page: 1, Size: 10})
+	names := map[string]string{}
+	for _, hit := range result.Hits {
+		names[hit.ID] = hit.Name
+	}
+	if len(names) != 2 || names["1"] != "Uno renovado" || names["3"] != "Tres" {
+		t.Errorf("expected the replayed update and delete in the new index, got %v", names)
+	}
+	if revision, found, _ := backend.Revision("2"); !found || revision != 2 {
+		t.Errorf("expected the delete tombstone to survive the swap, got %d %v", revision, found)
+	}
+
+	// Terminada la reindexación ya no se registran cambios
+	Guard(func(track func(Change)) error {
+		track(Change{ID: "9", Revision: 1})
+		return nil
+	})
+	if pending != nil {
+		t.Errorf("expected the journal to be closed after publishing")
+	}
+}
+
+func TestRunDoesNotSkipHotelsWhenOneIsDeleted(t *testing.T) {
+	backend := memory.NewIndex()
+
+	var ids []string
+	for i := 0; i < 2*pageSize+50; i++ {
+		ids = append(ids, fmt.Sprintf("h%04d", i))
+	}
+
+	// hotel-api con paginación por ID; el primer pedido borra un hotel que ya se leyó
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		after := r.URL.Query().Get("after")
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		if requests == 1 && after != "" {
+			t.Errorf("the first page should not have an after parameter, got %q", after)
+		}
+
+		start := sort.SearchStrings(ids, after)
+		if start < len(ids) && ids[start] == after {
+			start++
+		}
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		page := []index.Hotel{}
+		for _, id := range ids[start:end] {
+			page = append(page, index.Hotel{ID: id, Name: id, Revision: 1})
+		}
+		if requests == 1 {
+			ids = append(ids[:10:10], ids[11:]...)
+		}
+
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(ids)))
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+	t.Setenv("HOTEL_API_URL", server.URL)
+
+	progress, err := Run(backend)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if progress.Indexed != 2*pageSize+50 {
+		t.Errorf("expected every hotel read from hotel-api to be indexed, got %d", progress.Indexed)
+	}
+	// Con páginas por desplazamiento el primer hotel de la segunda página se habría salteado
+	if _, found, _ := backend.Revision(fmt.Sprintf("h%04d", pageSize)); !found {
+		t.Errorf("the first hotel of the second page should be indexed")
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"search-api/availability"
+	"search-api/cache"
+	"search-api/index"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tamaño de página por defecto y máximo de /search
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// Búsqueda con disponibilidad: los candidatos se traen del índice en lotes y se revisan hasta
+// agotarlos, hasta maxScannedCandidates o hasta availabilitySearchTimeout, lo que pase primero.
+const (
+	availabilityBatchSize     = 100
+	maxScannedCandidates      = 1000
+	availabilitySearchTimeout = 5 * time.Second
+)
+
+// Radio máximo en kilómetros de una búsqueda por cercanía
+const maxRadiusKm = 20000
+
+// availableHotel es un hotel con habitaciones libres para las fechas pedidas
+type availableHotel struct {
+	index.Hit
+	RemainingRooms int `json:"remaining_rooms"`
+}
+
+// availabilitySearchResult es la respuesta de /search cuando se piden fechas
+type availabilitySearchResult struct {
+	Hits             []availableHotel              `json:"hits"`
+	Total            int                           `json:"total"`
+	TotalApproximate bool                          `json:"total_approximate"` // No se revisaron todos los candidatos: total es un mínimo
+	Page             int                           `json:"page"`
+	Size             int                           `json:"size"`
+	Facets           map[string][]index.FacetCount `json:"facets"` // Del índice, sin filtrar por disponibilidad
+	NextPage         *int                          `json:"next_page"`
+	CheckIn          string                        `json:"check_in"`
+	CheckOut         string                        `json:"check_out"`
+	Guests           int                           `json:"guests"`
+}
+
+// parseAvailabilityRequest lee check_in, check_out y guests. Devuelve nil si no se pidieron fechas.
+func parseAvailabilityRequest(r *http.Request) (*availability.Request, error) {
+	values := r.URL.Query()
+	if values.Get("check_in") == "" && values.Get("check_out") == "" {
+		return nil, nil
+	}
+
+	checkIn, err := time.Parse("2006-01-02", values.Get("check_in"))
+	if err != nil {
+		return nil, fmt.Errorf("El parámetro 'check_in' debe tener el formato YYYY-MM-DD")
+	}
+	checkOut, err := time.Parse("2006-01-02", values.Get("check_out"))
+	if err != nil {
+		return nil, fmt.Errorf("El parámetro 'check_out' debe tener el formato YYYY-MM-DD")
+	}
+	if !checkOut.After(checkIn) {
+		return nil, fmt.Errorf("El parámetro 'check_out' debe ser posterior a 'check_in'")
+	}
+
+	guests := 1
+	if value := values.Get("guests"); value != "" {
+		guests, err = strconv.Atoi(value)
+		if err != nil || guests < 1 {
+			return nil, fmt.Errorf("El parámetro 'guests' debe ser un entero positivo")
+		}
+	}
+
+	return &availability.Request{CheckIn: checkIn, CheckOut: checkOut, Guests: guests}, nil
+}
+
+// parseGeoFilter lee lat, lon y radius_km. Devuelve nil si no se pidió un punto de referencia.
+func parseGeoFilter(r *http.Request) (*index.GeoFilter, error) {
+	values := r.URL.Query()
+	if values.Get("lat") == "" && values.Get("lon") == "" {
+		if values.Get("radius_km") != "" {
+			return nil, fmt.Errorf("El parámetro 'radius_km' requiere los parámetros 'lat' y 'lon'")
+		}
+		return nil, nil
+	}
+
+	lat, err := strconv.ParseFloat(values.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("El parámetro 'lat' debe estar entre -90 y 90")
+	}
+	lon, err := strconv.ParseFloat(values.Get("lon"), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("El parámetro 'lon' debe estar entre -180 y 180")
+	}
+
+	geo := &index.GeoFilter{Lat: lat, Lon: lon}
+	if value := values.Get("radius_km"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 || radius > maxRadiusKm {
+			return nil, fmt.Errorf("El parámetro 'radius_km' debe ser mayor a 0 y menor o igual a %d", maxRadiusKm)
+		}
+		geo.RadiusKm = radius
+	}
+
+	return geo, nil
+}
+
+// parseSearchQuery lee y valida los parámetros de /search
+func parseSearchQuery(r *http.Request) (index.SearchQuery, error) {
+	values := r.URL.Query()
+	query := index.SearchQuery{
+		Text:          values.Get("q"),
+		City:          values.Get("city"),
+		Country:       values.Get("country"),
+		AmenitiesMode: values.Get("amenities_mode"),
+		Sort:          values.Get("sort"),
+		Page:          1,
+		Size:          defaultPageSize,
+		Cursor:        values.Get("cursor"),
+	}
+
+	// Las amenities se aceptan repitiendo el parámetro o separadas por coma
+	for _, value := range values["amenities"] {
+		for _, amenity := range strings.Split(value, ",") {
+			if amenity = strings.TrimSpace(amenity); amenity != "" {
+				query.Amenities = append(query.Amenities, amenity)
+			}
+		}
+	}
+
+	switch query.AmenitiesMode {
+	case "":
+		query.AmenitiesMode = index.AmenitiesAll
+	case index.AmenitiesAll, index.AmenitiesAny:
+	default:
+		return query, fmt.Errorf("El parámetro 'amenities_mode' debe ser 'all' o 'any'")
+	}
+
+	if value := values.Get("min_rating"); value != "" {
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return query, fmt.Errorf("El parámetro 'min_rating' debe estar entre 0 y 5")
+		}
+		query.MinRating = rating
+	}
+
+	geo, err := parseGeoFilter(r)
+	if err != nil {
+		return query, err
+	}
+	query.Geo = geo
+
+	// Con un punto de referencia se ordena por distancia salvo que se pida otra cosa
+	if query.Sort == "" {
+		query.Sort = index.SortRelevance
+		if query.Geo != nil {
+			query.Sort = index.SortDistance
+		}
+	}
+	if !index.IsValidSort(query.Sort) {
+		return query, fmt.Errorf("El parámetro 'sort' no es válido")
+	}
+	if query.Sort == index.SortDistance && query.Geo == nil {
+		return query, fmt.Errorf("El orden 'distance' requiere los parámetros 'lat' y 'lon'")
+	}
+
+	if value := values.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return query, fmt.Errorf("El parámetro 'page' debe ser un entero positivo")
+		}
+		query.Page = page
+	}
+
+	if value := values.Get("size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxPageSize {
+			return query, fmt.Errorf("El parámetro 'size' debe estar entre 1 y %d", maxPageSize)
+		}
+		query.Size = size
+	}
+
+	if query.Cursor != "" && values.Get("page") != "" {
+		return query, fmt.Errorf("Los parámetros 'page' y 'cursor' no se pueden combinar")
+	}
+
+	return query, nil
+}
+
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := parseSearchQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	availabilityRequest, err := parseAvailabilityRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if availabilityRequest != nil {
+		searchAvailableHotels(w, r, query, *availabilityRequest)
+		return
+	}
+
+	results, err := searchIndex.Search(query)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error al buscar hoteles: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// searchAvailableHotels busca los hoteles candidatos en el índice, consulta su disponibilidad
+// en reservation-api y devuelve solo los que se pueden reservar, paginados. Los candidatos se
+// revisan por lotes con un único plazo para toda la búsqueda, que también se corta si el cliente
+// se desconecta; el total es exacto si se revisaron todos y se marca como aproximado si no.
+func searchAvailableHotels(w http.ResponseWriter, r *http.Request, query index.SearchQuery, request availability.Request) {
+	if query.Cursor != "" {
+		http.Error(w, "El parámetro 'cursor' no se puede usar junto con fechas", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), availabilitySearchTimeout)
+	defer cancel()
+
+	candidatesQuery := query
+	candidatesQuery.Size = availabilityBatchSize
+	available := []availableHotel{}
+	var facets map[string][]index.FacetCount
+	scanned, exhausted := 0, false
+	for batch := 1; ; batch++ {
+		candidatesQuery.Page = batch
+		candidates, err := searchIndex.Search(candidatesQuery)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error al buscar hoteles: %s", err), http.StatusInternalServerError)
+			return
+		}
+		if batch == 1 {
+			facets = candidates.Facets
+		}
+
+		hotelIDs := make([]string, len(candidates.Hits))
+		for i, hotel := range candidates.Hits {
+			hotelIDs[i] = hotel.ID
+		}
+		bookable := availability.Check(ctx, hotelIDs, request)
+
+		// Mantener el orden del índice y quedarse solo con los hoteles reservables
+		for _, hit := range candidates.Hits {
+			if rooms, ok := bookable[hit.ID]; ok {
+				available = append(available, availableHotel{Hit: hit, RemainingRooms: rooms})
+			}
+		}
+		scanned += len(candidates.Hits)
+
+		// Si se venció el plazo los hoteles que no se llegaron a consultar cuentan como no
+		// disponibles, así que el total queda aproximado aunque fuera el último lote
+		if ctx.Err() != nil {
+			break
+		}
+		if candidates.NextPage == nil || len(candidates.Hits) == 0 {
+			exhausted = true
+			break
+		}
+		if scanned >= maxScannedCandidates {
+			break
+		}
+	}
+
+	result := availabilitySearchResult{
+		Hits:             []availableHotel{},
+		Total:            len(available),
+		TotalApproximate: !exhausted,
+		Page:             query.Page,
+		Size:             query.Size,
+		Facets:           facets,
+		CheckIn:          request.CheckIn.Format("2006-01-02"),
+		CheckOut:         request.CheckOut.Format("2006-01-02"),
+		Guests:           request.Guests,
+	}
+
+	start := (query.Page - 1) * query.Size
+	if start < len(available) {
+		end := start + query.Size
+		if end > len(available) {
+			end = len(available)
+		}
+		result.Hits = available[start:end]
+		if end < len(available) {
+			next := query.Page + 1
+			result.NextPage = &next
+		}
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Cantidad de sugerencias por defecto y máxima de /suggest
+const (
+	defaultSuggestLimit = 8
+	maxSuggestLimit     = 20
+)
+
+// Cache de sugerencias: el frontend llama a /suggest en cada tecla
+var suggestCache = cache.NewLRU[string, []index.Suggestion](1000, time.Minute)
+
+// suggestHandler devuelve sugerencias de hoteles, ciudades y países para el prefijo 'q'
+func suggestHandler(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("q"))
+	if prefix == "" {
+		http.Error(w, "El parámetro 'q' es requerido", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSuggestLimit {
+			http.Error(w, fmt.Sprintf("El parámetro 'limit' debe estar entre 1 y %d", maxSuggestLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	key := fmt.Sprintf("%s|%d", strings.ToLower(prefix), limit)
+	suggestions, ok := suggestCache.Get(key)
+	if !ok {
+		var err error
+		suggestions, err = searchIndex.Suggest(prefix, limit)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error al obtener sugerencias: %s", err), http.StatusInternalServerError)
+			return
+		}
+		suggestCache.Add(key, suggestions)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"suggestions": suggestions})
+}
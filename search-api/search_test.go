@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"search-api/index"
+	"search-api/memory"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func setupMemoryIndex(t *testing.T) {
+	t.Helper()
+	backend := memory.NewIndex()
+	backend.Index(index.Hotel{ID: "1", Name: "Hotel Plaza", City: "Córdoba", Country: "Argentina", Amenities: []string{"Wifi"}})
+	backend.Index(index.Hotel{ID: "2", Name: "Gran Hotel", City: "Montevideo", Country: "Uruguay", Amenities: []string{"Pool"}})
+	searchIndex = backend
+	suggestCache.Purge()
+}
+
+func TestSearchHandler(t *testing.T) {
+	setupMemoryIndex(t)
+
+	req := httptest.NewRequest("GET", "/search?q=hotel&country=Argentina&size=5", nil)
+	w := httptest.NewRecorder()
+	searchHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result index.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Hits[0].ID != "1" || result.Size != 5 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(result.Facets["country"]) != 2 {
+		t.Errorf("expected country facets for both countries, got %v", result.Facets["country"])
+	}
+}
+
+func TestSearchHandlerValidatesParameters(t *testing.T) {
+	setupMemoryIndex(t)
+
+	for _, query := range []string{"size=0", "page=abc", "sort=price", "amenities_mode=some", "page=2&cursor=*", "sort=distance", "lat=91&lon=0", "lat=10", "radius_km=5", "lat=0&lon=0&radius_km=-1", "check_in=2030-01-05&check_out=2030-01-01", "min_rating=6", "min_rating=abc"} {
+		w := httptest.NewRecorder()
+		searchHandler(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestSearchHandlerGeo(t *testing.T) {
+	setupMemoryIndex(t)
+	lat, lon := -31.42, -64.18
+	searchIndex.Index(index.Hotel{ID: "1", Name: "Hotel Plaza", City: "Córdoba", Country: "Argentina", Latitude: &lat, Longitude: &lon})
+
+	w := httptest.NewRecorder()
+	searchHandler(w, httptest.NewRequest("GET", "/search?lat=-31.4&lon=-64.2&radius_km=10", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result index.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Hits[0].ID != "1" || result.Hits[0].DistanceKm == nil {
+		t.Errorf("expected the nearby hotel with its distance, got %+v", result)
+	}
+}
+
+func TestSuggestHandler(t *testing.T) {
+	setupMemoryIndex(t)
+
+	w := httptest.NewRecorder()
+	suggestHandler(w, httptest.NewRequest("GET", "/suggest?q=mon", nil))
+
+	var body struct {
+		Suggestions []index.Suggestion `json:"suggestions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Suggestions) != 1 || body.Suggestions[0].Type != index.SuggestCity {
+		t.Errorf("unexpected suggestions: %+v", body.Suggestions)
+	}
+}
+
+func TestSearchAvailableHotelsPagesThroughCandidates(t *testing.T) {
+	// Solo se pueden reservar los hoteles desde firstAvailable en adelante
+	firstAvailable := 0
+	var checks int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&checks, 1)
+		id := strings.TrimPrefix(r.URL.Path, "/reservations/availability/")
+		number, _ := strconv.Atoi(id)
+		json.NewEncoder(w).Encode(map[string]interface{}{"hotel_id": id, "available": number >= firstAvailable, "remaining_rooms": 2})
+	}))
+	defer server.Close()
+	t.Setenv("RESERVATION_API_URL", server.URL)
+
+	search := func(hotels int) availabilitySearchResult {
+		t.Helper()
+		backend := memory.NewIndex()
+		for i := 0; i < hotels; i++ {
+			backend.Index(index.Hotel{ID: fmt.Sprintf("%04d", i), Name: "Hotel", City: "Córdoba", Country: "Argentina"})
+		}
+		searchIndex = backend
+
+		w := httptest.NewRecorder()
+		searchHandler(w, httptest.NewRequest("GET", "/search?check_in=2030-01-01&check_out=2030-01-03&guests=2&sort=name_asc&size=10", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result availabilitySearchResult
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// Los hoteles reservables están después de los primeros lotes de candidatos
+	firstAvailable = 230
+	result := search(250)
+	if len(result.Hits) != 10 || result.Hits[0].ID != "0230" {
+		t.Fatalf("expected the first bookable hotels, got %+v", result.Hits)
+	}
+	if result.Total != 20 || result.TotalApproximate || result.NextPage == nil {
+		t.Errorf("expected an exact total of 20 with a next page, got total=%d approximate=%v next=%v", result.Total, result.TotalApproximate, result.NextPage)
+	}
+
+	// Con más candidatos de los que se cuentan el total queda marcado como aproximado
+	firstAvailable = 0
+	result = search(maxScannedCandidates + availabilityBatchSize)
+	if len(result.Hits) != 10 || result.Total != maxScannedCandidates || !result.TotalApproximate {
+		t.Errorf("expected an approximate total of %d, got %d hits, total=%d approximate=%v", maxScannedCandidates, len(result.Hits), result.Total, result.TotalApproximate)
+	}
+
+	// Sin ningún hotel disponible no se recorre todo el índice
+	firstAvailable = 1 << 30
+	atomic.StoreInt32(&checks, 0)
+	result = search(3 * maxScannedCandidates)
+	if len(result.Hits) != 0 || result.Total != 0 || !result.TotalApproximate || result.NextPage != nil {
+		t.Errorf("expected no hits and an approximate total, got %+v", result)
+	}
+	if checks > maxScannedCandidates {
+		t.Errorf("expected at most %d availability checks, got %d", maxScannedCandidates, checks)
+	}
+}
+
+func TestSearchAvailableHotelsStopsWhenClientDisconnects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"available": false})
+	}))
+	defer server.Close()
+	t.Setenv("RESERVATION_API_URL", server.URL)
+
+	backend := memory.NewIndex()
+	for i := 0; i < 3*availabilityBatchSize; i++ {
+		backend.Index(index.Hotel{ID: fmt.Sprintf("%04d", i), Name: "Hotel"})
+	}
+	searchIndex = backend
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/search?check_in=2030-01-01&check_out=2030-01-03", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	searchHandler(w, req)
+
+	var result availabilitySearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.TotalApproximate {
+		t.Errorf("a cancelled search should report an approximate total, got %+v", result)
+	}
+}
@@ -0,0 +1,151 @@
+package solr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"search-api/index"
+	"strings"
+	"time"
+)
+
+// Configset con el esquema de hoteles usado al crear colecciones nuevas
+const hotelConfigSet = "hotel_core"
+
+// collectionsAPI ejecuta una acción de la Collections API de Solr.
+// Si result no es nil se decodifica la respuesta en él.
+func (c *Client) collectionsAPI(params url.Values, result interface{}) error {
+	params.Set("wt", "json")
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(c.baseURL + "/admin/collections?" + params.Encode())
+	if err != nil {
+		return fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("solr returned an error: %v: %s", resp.Status, body)
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("error al decodificar la respuesta de Solr: %v", err)
+		}
+	}
+	return nil
+}
+
+// aliasCollections devuelve las colecciones a las que apunta el alias, o ninguna si no existe
+func (c *Client) aliasCollections() ([]string, error) {
+	var result struct {
+		Aliases map[string]string `json:"aliases"`
+	}
+	if err := c.collectionsAPI(url.Values{"action": {"LISTALIASES"}}, &result); err != nil {
+		return nil, err
+	}
+
+	collections, ok := result.Aliases[c.alias]
+	if !ok || collections == "" {
+		return nil, nil
+	}
+	return strings.Split(collections, ","), nil
+}
+
+// collectionBuild es una colección nueva que se llena durante una reindexación
+type collectionBuild struct {
+	client     *Client
+	collection string
+}
+
+// NewBuild crea una colección vacía con el esquema de hoteles
+func (c *Client) NewBuild() (index.Build, error) {
+	name := fmt.Sprintf("%s_%s", c.alias, time.Now().UTC().Format("20060102150405"))
+	err := c.collectionsAPI(url.Values{
+		"action":                {"CREATE"},
+		"name":                  {name},
+		"numShards":             {"1"},
+		"collection.configName": {hotelConfigSet},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &collectionBuild{client: c, collection: name}, nil
+}
+
+// Name devuelve el nombre de la colección nueva
+func (b *collectionBuild) Name() string {
+	return b.collection
+}
+
+// Add indexa un lote de hoteles en la colección nueva sin hacer commit
+func (b *collectionBuild) Add(hotels []index.Hotel) error {
+	docs := make([]document, len(hotels))
+	for i, hotel := range hotels {
+		docs[i] = toDocument(hotel)
+	}
+	return b.client.post(fmt.Sprintf("%s/%s/update", b.client.baseURL, url.PathEscape(b.collection)), docs)
+}
+
+// Delete deja en la colección nueva la lápida del hotel eliminado, sin hacer commit
+func (b *collectionBuild) Delete(id string, revision int64) error {
+	return b.client.post(fmt.Sprintf("%s/%s/update", b.client.baseURL, url.PathEscape(b.collection)), map[string]interface{}{
+		"add": map[string]interface{}{
+			"doc":       tombstone{ID: id, Revision: revision, Deleted: true},
+			"overwrite": true,
+		},
+	})
+}
+
+// Publish hace commit, construye el autocompletado, mueve el alias a la colección nueva y elimina
+// las colecciones a las que apuntaba antes. Solr reemplaza el alias de forma atómica si ya existía.
+func (b *collectionBuild) Publish() error {
+	collectionURL := fmt.Sprintf("%s/%s", b.client.baseURL, url.PathEscape(b.collection))
+	if err := b.client.post(collectionURL+"/update?commit=true", map[string]interface{}{}); err != nil {
+		return fmt.Errorf("error al hacer commit: %v", err)
+	}
+
+	// Sin los diccionarios el autocompletado sigue funcionando con la consulta de prefijo
+	if err := b.client.buildSuggesters(collectionURL); err != nil {
+		log.Printf("No se pudo construir el autocompletado de %s: %v", b.collection, err)
+	}
+
+	previous, err := b.client.aliasCollections()
+	if err != nil {
+		return fmt.Errorf("error al consultar el alias: %v", err)
+	}
+
+	err = b.client.collectionsAPI(url.Values{
+		"action":      {"CREATEALIAS"},
+		"name":        {b.client.alias},
+		"collections": {b.collection},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	// El índice nuevo ya está publicado: si no se puede borrar una colección vieja solo se avisa
+	for _, collection := range previous {
+		if collection == b.collection {
+			continue
+		}
+		if err := b.client.collectionsAPI(url.Values{"action": {"DELETE"}, "name": {collection}}, nil); err != nil {
+			log.Printf("No se pudo eliminar la colección anterior %s: %v", collection, err)
+			continue
+		}
+		log.Printf("Colección anterior %s eliminada", collection)
+	}
+	return nil
+}
+
+// Discard elimina la colección nueva (se usa para limpiar una reindexación fallida)
+func (b *collectionBuild) Discard() error {
+	return b.client.collectionsAPI(url.Values{
+		"action": {"DELETE"},
+		"name":   {b.collection},
+	}, nil)
+}
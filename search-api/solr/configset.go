@@ -0,0 +1,79 @@
+package solr
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// Archivos del configset hotel_core: el esquema de hoteles y la configuración de la colección
+//
+//go:embed configset/conf
+var configSetFiles embed.FS
+
+// configSetZip comprime los archivos del configset con solrconfig.xml en la raíz, como lo espera Solr
+func configSetZip() ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	err := fs.WalkDir(configSetFiles, "configset/conf", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := configSetFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		file, err := archive.Create(path.Base(name))
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UploadConfigSet sube el configset de hoteles a Solr, reemplazando el anterior si existía.
+// Las colecciones que ya lo usan no cambian: el esquema nuevo se aplica en la próxima reindexación,
+// que crea una colección nueva con él.
+func (c *Client) UploadConfigSet() error {
+	body, err := configSetZip()
+	if err != nil {
+		return fmt.Errorf("error al armar el configset: %v", err)
+	}
+
+	params := url.Values{}
+	params.Set("action", "UPLOAD")
+	params.Set("name", hotelConfigSet)
+	params.Set("overwrite", "true")
+	params.Set("cleanup", "true")
+	params.Set("wt", "json")
+
+	resp, err := c.http.Post(c.baseURL+"/admin/configs?"+params.Encode(), "application/octet-stream", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("solr returned an error: %v: %s", resp.Status, message)
+	}
+
+	log.Printf("Configset %s actualizado en Solr", hotelConfigSet)
+	return nil
+}
@@ -0,0 +1,105 @@
+package solr
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"search-api/index"
+	"strings"
+	"testing"
+)
+
+// schemaField es un campo declarado en managed-schema.xml
+type schemaField struct {
+	Name        string `xml:"name,attr"`
+	Type        string `xml:"type,attr"`
+	MultiValued string `xml:"multiValued,attr"`
+}
+
+// readSchemaFields devuelve los campos del esquema tal como se suben a Solr
+func readSchemaFields(t *testing.T) map[string]schemaField {
+	t.Helper()
+	data, err := configSetZip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file.Name], _ = io.ReadAll(reader)
+		reader.Close()
+	}
+	if _, ok := files["solrconfig.xml"]; !ok {
+		t.Fatalf("solrconfig.xml should be at the root of the configset, got %v", reflect.ValueOf(files).MapKeys())
+	}
+
+	var schema struct {
+		Fields []schemaField `xml:"field"`
+	}
+	if err := xml.Unmarshal(files["managed-schema.xml"], &schema); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]schemaField{}
+	for _, field := range schema.Fields {
+		fields[field.Name] = field
+	}
+	return fields
+}
+
+// jsonFields devuelve los nombres JSON de los campos del tipo, incluidos los de los structs embebidos
+func jsonFields(docType reflect.Type) []string {
+	var names []string
+	for i := 0; i < docType.NumField(); i++ {
+		field := docType.Field(i)
+		if field.Anonymous {
+			names = append(names, jsonFields(field.Type)...)
+			continue
+		}
+		names = append(names, strings.Split(field.Tag.Get("json"), ",")[0])
+	}
+	return names
+}
+
+func TestSchemaDeclaresIndexedFields(t *testing.T) {
+	fields := readSchemaFields(t)
+
+	// Todos los campos que se mandan a Solr, incluida la lápida, tienen que estar declarados
+	names := append(jsonFields(reflect.TypeOf(document{})), jsonFields(reflect.TypeOf(tombstone{}))...)
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			t.Errorf("field %s is not declared in the schema", name)
+			continue
+		}
+		if expected := name == "amenities"; (field.MultiValued == "true") != expected {
+			t.Errorf("field %s should have multiValued=%v, got %q", name, expected, field.MultiValued)
+		}
+	}
+
+	// Los ordenamientos necesitan campos de un solo valor
+	for _, name := range []string{"name", "rating_average", "review_count", "id"} {
+		if fields[name].MultiValued == "true" {
+			t.Errorf("sort field %s should be single-valued", name)
+		}
+	}
+	if fields["location"].Type != "location" || fields["deleted"].Type != "boolean" {
+		t.Errorf("unexpected types for location and deleted: %+v %+v", fields["location"], fields["deleted"])
+	}
+
+	// El documento se serializa con los mismos nombres que declara el esquema
+	encoded, _ := json.Marshal(toDocument(index.Hotel{ID: "1", RatingAverage: 4.5}))
+	if !strings.Contains(string(encoded), `"rating_average":4.5`) {
+		t.Errorf("unexpected document encoding: %s", encoded)
+	}
+}
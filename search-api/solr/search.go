@@ -0,0 +1,213 @@
+package solr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"search-api/index"
+	"strconv"
+	"strings"
+)
+
+// Ordenamientos permitidos y su equivalente en Solr
+var sortFields = map[string]string{
+	index.SortRelevance: "score desc, id asc",
+	index.SortNameAsc:   "name asc, id asc",
+	index.SortNameDesc:  "name desc, id asc",
+	index.SortDistance:  "geodist() asc, id asc",
+	index.SortRating:    "rating_average desc, review_count desc, id asc",
+}
+
+// solrHit es un documento devuelto por Solr con la distancia calculada
+type solrHit struct {
+	index.Hotel
+	Distance *float64 `json:"distance"`
+}
+
+// solrSearchResponse es la parte de la respuesta de Solr que nos interesa
+type solrSearchResponse struct {
+	Response struct {
+		NumFound int       `json:"numFound"`
+		Docs     []solrHit `json:"docs"`
+	} `json:"response"`
+	FacetCounts struct {
+		FacetFields map[string][]interface{} `json:"facet_fields"`
+	} `json:"facet_counts"`
+	NextCursorMark string `json:"nextCursorMark"`
+	Error          *struct {
+		Msg string `json:"msg"`
+	} `json:"error"`
+}
+
+// EscapeQueryChars escapa los caracteres especiales de la sintaxis de Solr
+// para que un término del usuario no pueda alterar la consulta.
+func EscapeQueryChars(term string) string {
+	var b strings.Builder
+	for _, r := range term {
+		switch r {
+		case '\\', '+', '-', '!', '(', ')', ':', '^', '[', ']', '"', '{', '}', '~', '*', '?', '|', '&', ';', '/', ' ', '\t', '\n':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// quote arma una frase entre comillas escapando su contenido
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// buildTextQuery arma la consulta de texto. El último término se busca
+// también como prefijo para mantener la búsqueda mientras se escribe.
+func buildTextQuery(text string) string {
+	terms := strings.Fields(text)
+	if len(terms) == 0 {
+		return "*:*"
+	}
+
+	escaped := make([]string, len(terms))
+	for i, term := range terms {
+		escaped[i] = EscapeQueryChars(term)
+	}
+	last := escaped[len(escaped)-1]
+	escaped[len(escaped)-1] = fmt.Sprintf("(%s OR %s*)", last, last)
+	return strings.Join(escaped, " ")
+}
+
+// Filtro que excluye las lápidas de los hoteles eliminados
+const excludeDeleted = "-deleted:true"
+
+// BuildSearchParams traduce la búsqueda a parámetros de Solr
+func BuildSearchParams(query index.SearchQuery) url.Values {
+	params := url.Values{}
+	params.Set("wt", "json")
+	params.Set("defType", "edismax")
+	params.Set("q", buildTextQuery(query.Text))
+	params.Set("qf", "name^3 city^2 country address amenities")
+	params.Set("q.op", "AND")
+
+	// Las lápidas de los hoteles eliminados nunca son resultados
+	params.Add("fq", excludeDeleted)
+
+	// Los filtros se etiquetan para excluirlos de su propio facet
+	if query.City != "" {
+		params.Add("fq", "{!tag=city}city:"+quote(query.City))
+	}
+	if query.Country != "" {
+		params.Add("fq", "{!tag=country}country:"+quote(query.Country))
+	}
+	if len(query.Amenities) > 0 {
+		quoted := make([]string, len(query.Amenities))
+		for i, amenity := range query.Amenities {
+			quoted[i] = quote(amenity)
+		}
+		operator := " AND "
+		if query.AmenitiesMode == index.AmenitiesAny {
+			operator = " OR "
+		}
+		params.Add("fq", "{!tag=amenities}amenities:("+strings.Join(quoted, operator)+")")
+	}
+
+	if query.MinRating > 0 {
+		params.Add("fq", "rating_average:["+strconv.FormatFloat(query.MinRating, 'f', -1, 64)+" TO *]")
+	}
+
+	// Búsqueda por cercanía: geodist() usa sfield y pt; el filtro por radio no se etiqueta
+	// porque también tiene que aplicarse a los facets.
+	if query.Geo != nil {
+		params.Set("sfield", "location")
+		params.Set("pt", fmt.Sprintf("%g,%g", query.Geo.Lat, query.Geo.Lon))
+		params.Set("fl", "*,distance:geodist()")
+		if query.Geo.RadiusKm > 0 {
+			params.Set("d", strconv.FormatFloat(query.Geo.RadiusKm, 'f', -1, 64))
+			params.Add("fq", "{!geofilt}")
+		}
+	}
+
+	params.Set("facet", "true")
+	params.Set("facet.mincount", "1")
+	for _, field := range index.FacetFields {
+		params.Add("facet.field", fmt.Sprintf("{!ex=%s}%s", field, field))
+	}
+
+	sort, ok := sortFields[query.Sort]
+	if !ok {
+		sort = sortFields[index.SortRelevance]
+	}
+	params.Set("sort", sort)
+	params.Set("rows", strconv.Itoa(query.Size))
+
+	// Solr no permite combinar cursores con desplazamiento
+	if query.Cursor != "" {
+		params.Set("cursorMark", query.Cursor)
+	} else {
+		params.Set("start", strconv.Itoa((query.Page-1)*query.Size))
+	}
+
+	return params
+}
+
+// parseFacets convierte la lista [valor, cantidad, valor, cantidad...] de Solr
+func parseFacets(raw map[string][]interface{}) map[string][]index.FacetCount {
+	facets := make(map[string][]index.FacetCount, len(index.FacetFields))
+	for _, field := range index.FacetFields {
+		counts := []index.FacetCount{}
+		values := raw[field]
+		for i := 0; i+1 < len(values); i += 2 {
+			value, _ := values[i].(string)
+			count, _ := values[i+1].(float64)
+			counts = append(counts, index.FacetCount{Value: value, Count: int(count)})
+		}
+		facets[field] = counts
+	}
+	return facets
+}
+
+// Search busca hoteles en Solr con filtros, facets y paginación
+func (c *Client) Search(query index.SearchQuery) (*index.SearchResult, error) {
+	resp, err := c.http.PostForm(c.coreURL()+"/select", BuildSearchParams(query))
+	if err != nil {
+		return nil, fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var raw solrSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error al decodificar la respuesta de Solr: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if raw.Error != nil {
+			return nil, fmt.Errorf("solr returned an error: %s", raw.Error.Msg)
+		}
+		return nil, fmt.Errorf("solr returned an error: %v", resp.Status)
+	}
+
+	result := &index.SearchResult{
+		Hits:   make([]index.Hit, 0, len(raw.Response.Docs)),
+		Total:  raw.Response.NumFound,
+		Page:   query.Page,
+		Size:   query.Size,
+		Facets: parseFacets(raw.FacetCounts.FacetFields),
+	}
+	for _, doc := range raw.Response.Docs {
+		hit := index.Hit{Hotel: doc.Hotel}
+		// Solr calcula la distancia aunque el hotel no tenga coordenadas
+		if query.Geo != nil && doc.HasLocation() {
+			hit.DistanceKm = doc.Distance
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+
+	result.NextPage = index.NextPage(query.Page, query.Size, result.Total)
+
+	// Solr devuelve el mismo cursor cuando no hay más resultados
+	if query.Cursor != "" && raw.NextCursorMark != query.Cursor {
+		result.NextCursor = raw.NextCursorMark
+	}
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package solr
+
+import (
+	"net/url"
+	"search-api/index"
+	"strings"
+	"testing"
+)
+
+func TestEscapeQueryChars(t *testing.T) {
+	cases := map[string]string{
+		"hilton":          "hilton",
+		"name:*":          `name\:\*`,
+		"a OR b":          `a\ OR\ b`,
+		`x") OR (id:"1`:   `x\"\)\ OR\ \(id\:\"1`,
+		"foo&&bar||baz":   `foo\&\&bar\|\|baz`,
+		`back\slash`:      `back\\slash`,
+		"{!lucene}name:x": `\{\!lucene\}name\:x`,
+		"[1 TO 5]":        `\[1\ TO\ 5\]`,
+	}
+
+	for input, expected := range cases {
+		if got := EscapeQueryChars(input); got != expected {
+			t.Errorf("EscapeQueryChars(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+// filters devuelve los filtros de la búsqueda sin el que excluye las lápidas, que siempre va primero
+func filters(t *testing.T, params url.Values) []string {
+	t.Helper()
+	fq := params["fq"]
+	if len(fq) == 0 || fq[0] != excludeDeleted {
+		t.Fatalf("deleted hotels should always be excluded, got %v", fq)
+	}
+	return fq[1:]
+}
+
+func TestBuildSearchParamsEscapesUserInput(t *testing.T) {
+	params := BuildSearchParams(index.SearchQuery{
+		Text:          "name:x*",
+		City:          `Buenos "Aires"`,
+		Amenities:     []string{"Wifi", "Pool"},
+		AmenitiesMode: index.AmenitiesAny,
+		Sort:          "relevance",
+		Page:          2,
+		Size:          10,
+	})
+
+	if q := params.Get("q"); q != `(name\:x\* OR name\:x\**)` {
+		t.Errorf("unexpected q: %s", q)
+	}
+	fq := filters(t, params)
+	if len(fq) != 2 {
+		t.Fatalf("expected 2 filters, got %v", fq)
+	}
+	if fq[0] != `{!tag=city}city:"Buenos \"Aires\""` {
+		t.Errorf("unexpected city filter: %s", fq[0])
+	}
+	if fq[1] != `{!tag=amenities}amenities:("Wifi" OR "Pool")` {
+		t.Errorf("unexpected amenities filter: %s", fq[1])
+	}
+	if params.Get("start") != "10" || params.Get("rows") != "10" {
+		t.Errorf("unexpected paging: start=%s rows=%s", params.Get("start"), params.Get("rows"))
+	}
+}
+
+func TestBuildSearchParamsCursor(t *testing.T) {
+	params := BuildSearchParams(index.SearchQuery{Sort: "name_asc", Page: 1, Size: 5, Cursor: "*"})
+
+	if params.Get("cursorMark") != "*" || params.Get("start") != "" {
+		t.Errorf("cursor paging should not use start: %v", params)
+	}
+	if !strings.HasSuffix(params.Get("sort"), "id asc") {
+		t.Errorf("cursor paging needs id as tie-breaker, got %s", params.Get("sort"))
+	}
+	if params.Get("q") != "*:*" {
+		t.Errorf("empty text should match all documents, got %s", params.Get("q"))
+	}
+}
+
+func TestBuildSearchParamsGeo(t *testing.T) {
+	params := BuildSearchParams(index.SearchQuery{
+		Sort: index.SortDistance,
+		Page: 1,
+		Size: 10,
+		Geo:  &index.GeoFilter{Lat: -34.6, Lon: -58.38, RadiusKm: 12.5},
+	})
+
+	if params.Get("sfield") != "location" || params.Get("pt") != "-34.6,-58.38" || params.Get("d") != "12.5" {
+		t.Errorf("unexpected geo params: %v", params)
+	}
+	if fq := filters(t, params); len(fq) != 1 || fq[0] != "{!geofilt}" {
+		t.Errorf("expected geofilt filter, got %v", fq)
+	}
+	if params.Get("sort") != "geodist() asc, id asc" {
+		t.Errorf("unexpected sort: %s", params.Get("sort"))
+	}
+	if params.Get("fl") != "*,distance:geodist()" {
+		t.Errorf("distance should be returned, got fl=%s", params.Get("fl"))
+	}
+
+	params = BuildSearchParams(index.SearchQuery{Page: 1, Size: 10, Geo: &index.GeoFilter{Lat: 1, Lon: 2}})
+	if len(filters(t, params)) != 0 || params.Get("d") != "" {
+		t.Errorf("no radius should not filter: %v", params)
+	}
+}
+
+func TestBuildSearchParamsRating(t *testing.T) {
+	params := BuildSearchParams(index.SearchQuery{Sort: index.SortRating, Page: 1, Size: 10, MinRating: 4.5})
+
+	if fq := filters(t, params); len(fq) != 1 || fq[0] != "rating_average:[4.5 TO *]" {
+		t.Errorf("unexpected rating filter: %v", fq)
+	}
+	if params.Get("sort") != "rating_average desc, review_count desc, id asc" {
+		t.Errorf("unexpected sort: %s", params.Get("sort"))
+	}
+}
+
+func TestToDocumentLocation(t *testing.T) {
+	lat, lon := -31.42, -64.18
+	doc := toDocument(index.Hotel{ID: "1", Latitude: &lat, Longitude: &lon})
+	if doc.Location != "-31.42,-64.18" {
+		t.Errorf("unexpected location: %q", doc.Location)
+	}
+	if doc := toDocument(index.Hotel{ID: "2"}); doc.Location != "" {
+		t.Errorf("hotel without coordinates should not have location, got %q", doc.Location)
+	}
+}
@@ -4,73 +4,146 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"search-api/index"
+	"time"
 )
 
-// Estructura de ejemplo para los documentos de hoteles
-type Hotel struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	City    string `json:"city"`
-	Country string `json:"country"`
+// Client es el índice de hoteles respaldado por Solr
+type Client struct {
+	baseURL string // URL base de Solr, por ejemplo http://localhost:8983/solr
+	alias   string // Alias con el que se consulta e indexa la colección vigente
+	http    *http.Client
 }
 
-// Enviar el hotel a Solr
-func SendToSolr(message []byte) error {
-	var hotel Hotel
-	err := json.Unmarshal(message, &hotel)
-	if err != nil {
-		return fmt.Errorf("error unmarshaling message: %v", err)
+// NewClient crea un cliente para la colección (o alias) de hoteles.
+// La reindexación crea una colección nueva y mueve el alias al terminar.
+func NewClient(baseURL, alias string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		alias:   alias,
+		http:    &http.Client{Timeout: 30 * time.Second},
 	}
+}
 
-	jsonData, err := json.Marshal(map[string]interface{}{
-		"add": map[string]interface{}{
-			"doc": hotel,
-		},
-	})
+// coreURL devuelve la URL de la colección de hoteles
+func (c *Client) coreURL() string {
+	return c.baseURL + "/" + url.PathEscape(c.alias)
+}
+
+// post envía un cuerpo JSON a Solr y valida la respuesta
+func (c *Client) post(endpoint string, body interface{}) error {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("error marshaling data to Solr: %v", err)
 	}
 
-	solrURL := "http://localhost:8983/solr/hotel_core/update?commit=true"
-	req, err := http.NewRequest("POST", solrURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("error creating request: %v", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return fmt.Errorf("error sending request to Solr: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("solr returned an error: %v", resp.Status)
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("solr returned an error: %v: %s", resp.Status, message)
+	}
+
+	return nil
+}
+
+// document es el documento que se manda a Solr: el hotel más el campo
+// location ("lat,lon") que usan el filtro y el ordenamiento por distancia.
+type document struct {
+	index.Hotel
+	Location string `json:"location,omitempty"`
+}
+
+// toDocument arma el documento de Solr del hotel
+func toDocument(hotel index.Hotel) document {
+	doc := document{Hotel: hotel}
+	if hotel.HasLocation() {
+		doc.Location = fmt.Sprintf("%g,%g", *hotel.Latitude, *hotel.Longitude)
+	}
+	return doc
+}
+
+// Index agrega o reemplaza el documento del hotel en Solr
+func (c *Client) Index(hotel index.Hotel) error {
+	err := c.post(c.coreURL()+"/update?commit=true", map[string]interface{}{
+		"add": map[string]interface{}{
+			"doc":       toDocument(hotel),
+			"overwrite": true,
+		},
+	})
+	if err != nil {
+		return err
 	}
 
-	log.Println("Document successfully indexed in Solr.")
+	log.Printf("Hotel %s indexado en Solr (revisión %d)", hotel.ID, hotel.Revision)
 	return nil
 }
 
-// SearchHotels busca hoteles en Solr según una consulta de búsqueda
-func SearchHotels(queryTerm string) (map[string]interface{}, error) {
-	// Construcción de la consulta para búsqueda global
-	query := fmt.Sprintf("%s*", queryTerm)
-	solrURL := fmt.Sprintf("http://localhost:8983/solr/hotel_core/select?q=%s&wt=json", query)
+// tombstone es el documento que reemplaza al de un hotel eliminado: solo guarda la revisión
+// del borrado para descartar eventos anteriores, y las búsquedas lo excluyen
+type tombstone struct {
+	ID       string `json:"id"`
+	Revision int64  `json:"revision"`
+	Deleted  bool   `json:"deleted"`
+}
 
-	resp, err := http.Get(solrURL)
+// Delete reemplaza el documento del hotel por una lápida con la revisión del borrado
+func (c *Client) Delete(id string, revision int64) error {
+	err := c.post(c.coreURL()+"/update?commit=true", map[string]interface{}{
+		"add": map[string]interface{}{
+			"doc":       tombstone{ID: id, Revision: revision, Deleted: true},
+			"overwrite": true,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+		return err
+	}
+
+	log.Printf("Hotel %s eliminado de Solr (revisión %d)", id, revision)
+	return nil
+}
+
+// Revision devuelve la revisión del hotel indexada en Solr, o la del borrado si se eliminó.
+// El segundo valor es false si Solr no tiene el hotel.
+func (c *Client) Revision(id string) (int64, bool, error) {
+	solrURL := fmt.Sprintf("%s/get?id=%s&fl=id,revision&wt=json", c.coreURL(), url.QueryEscape(id))
+
+	resp, err := c.http.Get(solrURL)
+	if err != nil {
+		return 0, false, fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
 	}
 	defer resp.Body.Close()
 
-	var result map[string]interface{}
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, fmt.Errorf("solr returned an error: %v", resp.Status)
+	}
+
+	var result struct {
+		Doc *struct {
+			Revision int64 `json:"revision"`
+		} `json:"doc"`
+	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error al decodificar la respuesta de Solr: %v", err)
+		return 0, false, fmt.Errorf("error al decodificar la respuesta de Solr: %v", err)
+	}
+
+	if result.Doc == nil {
+		return 0, false, nil
 	}
 
-	return result, nil
+	return result.Doc.Revision, true, nil
 }
@@ -0,0 +1,204 @@
+package solr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"search-api/index"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Diccionarios del SuggestComponent configurados en configset/conf/solrconfig.xml
+// (AnalyzingInfixLookupFactory sobre name, city y country; el de nombres usa id como payload).
+var suggestDictionaries = map[string]string{
+	"hotelNameSuggester": index.SuggestHotel,
+	"citySuggester":      index.SuggestCity,
+	"countrySuggester":   index.SuggestCountry,
+}
+
+// solrSuggestResponse es la respuesta del handler /suggest de Solr
+type solrSuggestResponse struct {
+	Suggest map[string]map[string]struct {
+		Suggestions []struct {
+			Term    string `json:"term"`
+			Weight  int64  `json:"weight"`
+			Payload string `json:"payload"`
+		} `json:"suggestions"`
+	} `json:"suggest"`
+}
+
+// errNoSuggester indica que la colección no tiene el handler /suggest o sus diccionarios,
+// por ejemplo porque se creó antes de subir el configset actual
+var errNoSuggester = errors.New("solr suggester is not configured")
+
+// Suggest devuelve hasta limit sugerencias de nombres de hoteles, ciudades y países para el prefijo.
+// Si la colección no tiene configurado el autocompletado, o sus diccionarios no tienen nada para el
+// prefijo (por ejemplo, un hotel nuevo antes de la próxima construcción), se busca el prefijo con
+// una consulta normal.
+func (c *Client) Suggest(prefix string, limit int) ([]index.Suggestion, error) {
+	suggestions, err := c.suggesterSuggest(prefix, limit)
+	if errors.Is(err, errNoSuggester) || (err == nil && len(suggestions) == 0) {
+		return c.prefixSuggest(prefix, limit)
+	}
+	return suggestions, err
+}
+
+// BuildSuggesters reconstruye los diccionarios del autocompletado de la colección vigente
+func (c *Client) BuildSuggesters() error {
+	return c.buildSuggesters(c.coreURL())
+}
+
+// buildSuggesters reconstruye los diccionarios del autocompletado de la colección en collectionURL
+func (c *Client) buildSuggesters(collectionURL string) error {
+	params := url.Values{}
+	params.Set("wt", "json")
+	params.Set("suggest", "true")
+	params.Set("suggest.build", "true")
+	for dictionary := range suggestDictionaries {
+		params.Add("suggest.dictionary", dictionary)
+	}
+
+	resp, err := c.http.Get(collectionURL + "/suggest?" + params.Encode())
+	if err != nil {
+		return fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return errNoSuggester
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("solr returned an error: %v", resp.Status)
+	}
+	return nil
+}
+
+// suggesterSuggest consulta los diccionarios del handler /suggest de Solr
+func (c *Client) suggesterSuggest(prefix string, limit int) ([]index.Suggestion, error) {
+	params := url.Values{}
+	params.Set("wt", "json")
+	params.Set("suggest", "true")
+	params.Set("suggest.q", prefix)
+	params.Set("suggest.count", strconv.Itoa(limit))
+	for dictionary := range suggestDictionaries {
+		params.Add("suggest.dictionary", dictionary)
+	}
+
+	resp, err := c.http.Get(c.coreURL() + "/suggest?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Solr responde 404 si no existe el handler y 400 si no existe alguno de los diccionarios
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return nil, errNoSuggester
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("solr returned an error: %v", resp.Status)
+	}
+
+	var raw solrSuggestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error al decodificar la respuesta de Solr: %v", err)
+	}
+
+	var suggestions []index.Suggestion
+	for dictionary, byTerm := range raw.Suggest {
+		entityType, ok := suggestDictionaries[dictionary]
+		if !ok {
+			continue
+		}
+		for _, result := range byTerm {
+			for _, s := range result.Suggestions {
+				suggestion := index.Suggestion{Text: s.Term, Type: entityType, Weight: s.Weight}
+				if entityType == index.SuggestHotel {
+					suggestion.ID = s.Payload
+				}
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+
+	return index.RankSuggestions(prefix, suggestions, limit), nil
+}
+
+// BuildPrefixSuggestParams arma la consulta que reemplaza al autocompletado: hoteles cuyo nombre,
+// ciudad o país tienen una palabra que empieza con el prefijo, sin las lápidas, con las
+// ciudades y países de esos hoteles como facets.
+func BuildPrefixSuggestParams(prefix string, limit int) url.Values {
+	params := url.Values{}
+	params.Set("wt", "json")
+	params.Set("defType", "edismax")
+	params.Set("q", buildTextQuery(prefix))
+	params.Set("qf", "name city country")
+	params.Set("q.op", "AND")
+	params.Add("fq", excludeDeleted)
+	params.Set("fl", "id,name")
+	params.Set("rows", strconv.Itoa(limit))
+	params.Set("facet", "true")
+	params.Set("facet.mincount", "1")
+	params.Set("facet.limit", "-1")
+	params.Add("facet.field", "city")
+	params.Add("facet.field", "country")
+	return params
+}
+
+// matchesPrefix indica si el valor o alguna de sus palabras empieza con el prefijo
+func matchesPrefix(value, prefix string) bool {
+	value = strings.ToLower(value)
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if strings.HasPrefix(value, prefix) {
+		return true
+	}
+	for _, term := range strings.FieldsFunc(value, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		if strings.HasPrefix(term, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixSuggest arma las sugerencias con una búsqueda normal. Como en el índice en memoria,
+// el peso de ciudades y países es la cantidad de hoteles que tienen.
+func (c *Client) prefixSuggest(prefix string, limit int) ([]index.Suggestion, error) {
+	resp, err := c.http.PostForm(c.coreURL()+"/select", BuildPrefixSuggestParams(prefix, limit))
+	if err != nil {
+		return nil, fmt.Errorf("error al hacer la solicitud a Solr: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("solr returned an error: %v", resp.Status)
+	}
+
+	var raw solrSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error al decodificar la respuesta de Solr: %v", err)
+	}
+
+	var suggestions []index.Suggestion
+	for _, doc := range raw.Response.Docs {
+		if matchesPrefix(doc.Name, prefix) {
+			suggestions = append(suggestions, index.Suggestion{Text: doc.Name, Type: index.SuggestHotel, ID: doc.ID, Weight: 1})
+		}
+	}
+	places := map[string]string{"city": index.SuggestCity, "country": index.SuggestCountry}
+	for field, facets := range parseFacets(raw.FacetCounts.FacetFields) {
+		entityType, ok := places[field]
+		if !ok {
+			continue
+		}
+		for _, facet := range facets {
+			if matchesPrefix(facet.Value, prefix) {
+				suggestions = append(suggestions, index.Suggestion{Text: facet.Value, Type: entityType, Weight: int64(facet.Count)})
+			}
+		}
+	}
+
+	return index.RankSuggestions(prefix, suggestions, limit), nil
+}
@@ -0,0 +1,108 @@
+package solr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"search-api/index"
+	"testing"
+)
+
+func TestSuggestFallsBackToPrefixQuery(t *testing.T) {
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/hotel_core/suggest":
+			// Colección creada sin los diccionarios del autocompletado
+			http.Error(w, `{"error":{"msg":"No suggester named hotelNameSuggester was configured"}}`, http.StatusBadRequest)
+		case "/solr/hotel_core/select":
+			r.ParseForm()
+			filters = r.Form["fq"]
+			w.Write([]byte(`{
+				"response": {"numFound": 2, "docs": [{"id": "1", "name": "Montañas Hotel"}, {"id": "2", "name": "Gran Hotel"}]},
+				"facet_counts": {"facet_fields": {
+					"city": ["Montevideo", 2, "Mendoza", 1],
+					"country": ["Uruguay", 2]
+				}}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	suggestions, err := NewClient(server.URL+"/solr", "hotel_core").Suggest("mont", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) == 0 || filters[0] != excludeDeleted {
+		t.Errorf("the fallback should exclude deleted hotels, got filters %v", filters)
+	}
+
+	expected := []index.Suggestion{
+		{Text: "Montevideo", Type: index.SuggestCity, Weight: 2},
+		{Text: "Montañas Hotel", Type: index.SuggestHotel, ID: "1", Weight: 1},
+	}
+	if len(suggestions) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, suggestions)
+	}
+	for i := range expected {
+		if suggestions[i] != expected[i] {
+			t.Errorf("suggestion %d: expected %+v, got %+v", i, expected[i], suggestions[i])
+		}
+	}
+}
+
+func TestMatchesPrefix(t *testing.T) {
+	cases := []struct {
+		value, prefix string
+		expected      bool
+	}{
+		{"Buenos Aires", "bue", true},
+		{"Buenos Aires", "air", true},
+		{"Buenos Aires", "buenos a", true},
+		{"Gran Hotel", "hotel g", false},
+		{"San Carlos de Bariloche", "ari", false},
+	}
+	for _, tc := range cases {
+		if got := matchesPrefix(tc.value, tc.prefix); got != tc.expected {
+			t.Errorf("matchesPrefix(%q, %q) = %v, expected %v", tc.value, tc.prefix, got, tc.expected)
+		}
+	}
+}
+
+func TestSuggestFallsBackWhenDictionariesAreEmpty(t *testing.T) {
+	var built bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/hotel_core/suggest":
+			if r.URL.Query().Get("suggest.build") == "true" {
+				built = len(r.URL.Query()["suggest.dictionary"]) == len(suggestDictionaries)
+				w.Write([]byte(`{}`))
+				return
+			}
+			// Diccionarios construidos antes de que se indexara el hotel
+			w.Write([]byte(`{"suggest": {"hotelNameSuggester": {"nue": {"numFound": 0, "suggestions": []}}}}`))
+		case "/solr/hotel_core/select":
+			w.Write([]byte(`{"response": {"numFound": 1, "docs": [{"id": "1", "name": "Nuevo Hotel"}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/solr", "hotel_core")
+	suggestions, err := client.Suggest("nue", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].ID != "1" {
+		t.Errorf("expected the new hotel from the prefix query, got %+v", suggestions)
+	}
+
+	if err := client.BuildSuggesters(); err != nil {
+		t.Fatal(err)
+	}
+	if !built {
+		t.Error("expected every dictionary to be built")
+	}
+}
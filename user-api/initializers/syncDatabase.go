@@ -4,5 +4,5 @@ package initializers
 import "user-reservation-api/models"
 
 func SyncDatabase() {
-	DB.AutoMigrate(&models.User{})
+	DB.AutoMigrate(&models.User{}, &models.Notification{})
 }
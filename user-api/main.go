@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"user-reservation-api/initializers"
+	"user-reservation-api/notifications"
 	"user-reservation-api/routes"
+	"user-reservation-api/services"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -19,6 +21,10 @@ func init() {
 func main() {
 	// Verificar el valor de SECRET
 	fmt.Println("SECRET:", os.Getenv("SECRET"))
+	// Enviar a los huéspedes los emails de sus reservas
+	services.StartNotificationConsumer()
+	services.StartNotificationSender(notifications.TransportFromEnv())
+
 	r := gin.Default()
 
 	// Configuración CORS
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Estados de una notificación
+const (
+	NotificationPending   = "pending"
+	NotificationSent      = "sent"
+	NotificationFailed    = "failed"    // Se agotaron los reintentos
+	NotificationCancelled = "cancelled" // Recordatorio de una reserva cancelada o modificada
+)
+
+// Notification es un email para un usuario y el registro de su envío
+type Notification struct {
+	ID            uint       `json:"id" gorm:"primary_key"`
+	DedupKey      string     `json:"-" gorm:"size:191;uniqueIndex"` // Evita duplicados si un evento llega dos veces
+	UserID        uint       `json:"userId" gorm:"index"`
+	ReservationID uint       `json:"reservationId" gorm:"index"`
+	Kind          string     `json:"kind" gorm:"size:30"`
+	Email         string     `json:"email"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"size:20;index:idx_notification_due"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty" gorm:"type:text"`
+	ScheduledAt   time.Time  `json:"scheduledAt" gorm:"index:idx_notification_due"` // Próximo intento
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
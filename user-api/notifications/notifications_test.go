@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderConfirmation(t *testing.T) {
+	message, err := Render(KindConfirmation, Data{
+		Email:         "guest@example.com",
+		ReservationID: 42,
+		HotelID:       "h1",
+		CheckIn:       "2030-01-02",
+		CheckOut:      "2030-01-05",
+		Guests:        2,
+		Currency:      "USD",
+		TotalPrice:    300,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.To != "guest@example.com" || message.Subject != "Reserva #42 confirmada" {
+		t.Errorf("unexpected message: %+v", message)
+	}
+	for _, expected := range []string{"2030-01-02", "2030-01-05", "300.00 USD"} {
+		if !strings.Contains(message.Body, expected) {
+			t.Errorf("body should contain %q:\n%s", expected, message.Body)
+		}
+	}
+}
+
+func TestRenderFailureAndNoShow(t *testing.T) {
+	data := Data{Email: "guest@example.com", ReservationID: 7, HotelID: "h1", CheckIn: "2030-01-02", CheckOut: "2030-01-05"}
+	for _, kind := range []string{KindFailure, KindNoShow} {
+		message, err := Render(kind, data)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		if !strings.Contains(message.Subject, "#7") || !strings.Contains(message.Body, "2030-01-02") {
+			t.Errorf("%s: unexpected message: %+v", kind, message)
+		}
+	}
+}
+
+func TestRenderUnknownKind(t *testing.T) {
+	if _, err := Render("unknown", Data{}); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := t.TempDir()
+	transport := FileTransport{Dir: dir, From: "reservas@localhost"}
+	if err := transport.Send(Message{To: "guest@example.com", Subject: "Hola", Body: "Texto\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one email file, got %v", files)
+	}
+	content, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(content), "Subject: Hola\r\n") || !strings.Contains(string(content), "To: guest@example.com") {
+		t.Errorf("unexpected email:\n%s", content)
+	}
+}
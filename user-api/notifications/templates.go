@@ -0,0 +1,139 @@
+// Package notifications arma los emails que recibe un huésped sobre sus reservas y los
+// envía por un transporte intercambiable (SMTP, archivo o log). No accede a la base de datos.
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Tipos de notificación
+const (
+	KindConfirmation  = "confirmation"
+	KindModification  = "modification"
+	KindCancellation  = "cancellation"
+	KindReminder      = "reminder"
+	KindWaitlistOffer = "waitlist_offer"
+	KindFailure       = "failure" // La reserva no se pudo confirmar, por ejemplo por un pago rechazado
+	KindNoShow        = "no_show"
+)
+
+// Data son los datos de la reserva que se usan en las plantillas
+type Data struct {
+	Email         string
+	ReservationID uint
+	HotelID       string
+	CheckIn       string // YYYY-MM-DD
+	CheckOut      string // YYYY-MM-DD
+	Guests        int
+	Currency      string
+	TotalPrice    float64
+	DaysBefore    int    // Días que faltan para el check-in, solo en los recordatorios
+	HoldToken     string // Bloqueo ofrecido, solo en las ofertas de la lista de espera
+	HoldExpiresAt string
+}
+
+// Message es un email listo para enviar
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func mustTemplate(name, subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New(name + "_subject").Parse(subject)),
+		body:    template.Must(template.New(name + "_body").Parse(body)),
+	}
+}
+
+var templates = map[string]messageTemplate{
+	KindConfirmation: mustTemplate(KindConfirmation,
+		"Reserva #{{.ReservationID}} confirmada",
+		`Hola,
+
+Tu reserva #{{.ReservationID}} en el hotel {{.HotelID}} quedó confirmada.
+
+Check-in: {{.CheckIn}}
+Check-out: {{.CheckOut}}
+Huéspedes: {{.Guests}}
+Total: {{printf "%.2f" .TotalPrice}} {{.Currency}}
+
+¡Gracias por reservar con nosotros!
+`),
+	KindModification: mustTemplate(KindModification,
+		"Reserva #{{.ReservationID}} modificada",
+		`Hola,
+
+Tu reserva #{{.ReservationID}} en el hotel {{.HotelID}} fue modificada. Estos son los nuevos datos:
+
+Check-in: {{.CheckIn}}
+Check-out: {{.CheckOut}}
+Huéspedes: {{.Guests}}
+Total: {{printf "%.2f" .TotalPrice}} {{.Currency}}
+`),
+	KindCancellation: mustTemplate(KindCancellation,
+		"Reserva #{{.ReservationID}} cancelada",
+		`Hola,
+
+Tu reserva #{{.ReservationID}} en el hotel {{.HotelID}} del {{.CheckIn}} al {{.CheckOut}} fue cancelada.
+
+Si no fuiste vos, contactanos.
+`),
+	KindFailure: mustTemplate(KindFailure,
+		"No pudimos confirmar la reserva #{{.ReservationID}}",
+		`Hola,
+
+No pudimos confirmar tu reserva #{{.ReservationID}} en el hotel {{.HotelID}} del {{.CheckIn}} al {{.CheckOut}} porque el pago no se completó.
+
+Las habitaciones se liberaron y no se realizó ningún cobro. Podés volver a reservar cuando quieras.
+`),
+	KindNoShow: mustTemplate(KindNoShow,
+		"No registramos tu llegada a la reserva #{{.ReservationID}}",
+		`Hola,
+
+Tu reserva #{{.ReservationID}} en el hotel {{.HotelID}} del {{.CheckIn}} al {{.CheckOut}} quedó registrada como no presentada.
+
+Si creés que es un error, contactanos.
+`),
+	KindReminder: mustTemplate(KindReminder,
+		"Tu estadía empieza en {{.DaysBefore}} días",
+		`Hola,
+
+Te recordamos que tu reserva #{{.ReservationID}} en el hotel {{.HotelID}} empieza el {{.CheckIn}} y termina el {{.CheckOut}}.
+
+¡Te esperamos!
+`),
+	KindWaitlistOffer: mustTemplate(KindWaitlistOffer,
+		"Se liberó una habitación para tus fechas",
+		`Hola,
+
+Se liberó una habitación en el hotel {{.HotelID}} del {{.CheckIn}} al {{.CheckOut}} y la reservamos para vos hasta el {{.HoldExpiresAt}}.
+
+Para confirmarla, creá la reserva con el código de bloqueo {{.HoldToken}}.
+`),
+}
+
+// Render arma el email del tipo indicado para el destinatario de data
+func Render(kind string, data Data) (Message, error) {
+	tmpl, ok := templates[kind]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown notification kind %q", kind)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s subject: %v", kind, err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s body: %v", kind, err)
+	}
+
+	return Message{To: data.Email, Subject: subject.String(), Body: body.String()}, nil
+}
@@ -0,0 +1,112 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Transport envía un email
+type Transport interface {
+	Send(message Message) error
+}
+
+// SMTPTransport envía los emails por un servidor SMTP
+type SMTPTransport struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send envía el email por SMTP; si hay usuario se autentica con PLAIN
+func (t SMTPTransport) Send(message Message) error {
+	var auth smtp.Auth
+	if t.Username != "" {
+		auth = smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	}
+	return smtp.SendMail(t.Host+":"+t.Port, auth, t.From, []string{message.To}, format(t.From, message))
+}
+
+// FileTransport guarda cada email como un archivo .eml en Dir, para desarrollo local
+type FileTransport struct {
+	Dir  string
+	From string
+}
+
+// Send escribe el email en un archivo nuevo
+func (t FileTransport) Send(message Message) error {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitize(message.To))
+	return os.WriteFile(filepath.Join(t.Dir, name), format(t.From, message), 0o644)
+}
+
+// LogTransport escribe los emails en el log, para desarrollo local
+type LogTransport struct{}
+
+// Send escribe el email en el log
+func (LogTransport) Send(message Message) error {
+	log.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// format arma el email con sus encabezados
+func format(from string, message Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitize deja solo caracteres seguros para un nombre de archivo
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// TransportFromEnv elige el transporte según NOTIFICATION_TRANSPORT: "smtp" (SMTP_HOST,
+// SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD), "file" (NOTIFICATION_DIR) o "log", que es el valor por defecto
+func TransportFromEnv() Transport {
+	from := os.Getenv("NOTIFICATION_FROM")
+	if from == "" {
+		from = "reservas@localhost"
+	}
+
+	switch os.Getenv("NOTIFICATION_TRANSPORT") {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return SMTPTransport{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	case "file":
+		dir := os.Getenv("NOTIFICATION_DIR")
+		if dir == "" {
+			dir = "notifications-out"
+		}
+		return FileTransport{Dir: dir, From: from}
+	default:
+		return LogTransport{}
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"user-reservation-api/initializers"
+	"user-reservation-api/models"
+	"user-reservation-api/notifications"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tipos de eventos publicados por reservation-api que generan notificaciones
+const (
+	reservationCreated       = "reservation.created"
+	reservationModified      = "reservation.modified"
+	reservationCancelled     = "reservation.cancelled"
+	reservationStatusChanged = "reservation.status_changed"
+	waitlistOffered          = "waitlist.offered"
+)
+
+// Estados de la reserva que generan notificaciones
+const (
+	statusConfirmed = "confirmed"
+	statusFailed    = "failed"
+	statusNoShow    = "no_show"
+)
+
+const (
+	defaultReminderDaysBefore = 2
+	defaultMaxAttempts        = 5
+	notificationLease         = time.Minute // Tiempo que una notificación queda reservada mientras se envía
+	notificationMaxBackoff    = time.Hour
+	notificationPollInterval  = 30 * time.Second
+	reminderHour              = 9 // Hora del día en que se envían los recordatorios
+)
+
+// ReservationEvent es un evento del exchange reservation_events de reservation-api.
+// Incluye los campos de los eventos de reservas y de la lista de espera.
+type ReservationEvent struct {
+	SchemaVersion int       `json:"schema_version"`
+	EventType     string    `json:"event_type"`
+	Timestamp     time.Time `json:"timestamp"`
+	ReservationID uint      `json:"reservation_id"`
+	UserID        uint      `json:"user_id"`
+	HotelID       string    `json:"hotel_id"`
+	Guests        int       `json:"guests"`
+	CheckIn       string    `json:"check_in"`
+	CheckOut      string    `json:"check_out"`
+	Status        string    `json:"status"`
+	Currency      string    `json:"currency"`
+	TotalPrice    float64   `json:"total_price"`
+
+	// Solo en waitlist.offered
+	WaitlistEntryID uint      `json:"waitlist_entry_id"`
+	HoldToken       string    `json:"hold_token"`
+	HoldExpiresAt   time.Time `json:"hold_expires_at"`
+}
+
+// envPositiveInt lee una variable de entorno entera positiva o devuelve el valor por defecto
+func envPositiveInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// HandleReservationEvent guarda las notificaciones que genera el evento. messageID es el
+// ID del mensaje de RabbitMQ: si el mismo evento llega dos veces no se duplican los emails.
+func HandleReservationEvent(messageID string, event ReservationEvent) error {
+	var user models.User
+	err := initializers.DB.First(&user, event.UserID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Skipping %s event for unknown user %d", event.EventType, event.UserID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data := notifications.Data{
+		Email:         user.Email,
+		ReservationID: event.ReservationID,
+		HotelID:       event.HotelID,
+		CheckIn:       event.CheckIn,
+		CheckOut:      event.CheckOut,
+		Guests:        event.Guests,
+		Currency:      event.Currency,
+		TotalPrice:    event.TotalPrice,
+		HoldToken:     event.HoldToken,
+		HoldExpiresAt: event.HoldExpiresAt.Format("2006-01-02 15:04 MST"),
+	}
+	now := time.Now()
+
+	return initializers.DB.Transaction(func(tx *gorm.DB) error {
+		switch event.EventType {
+		case reservationCreated:
+			// La reserva se crea pendiente de pago: la confirmación se envía cuando pasa a confirmada
+			if event.Status != statusConfirmed {
+				return nil
+			}
+			return confirmReservation(tx, messageID, user.ID, data, now)
+		case reservationStatusChanged:
+			switch event.Status {
+			case statusConfirmed:
+				return confirmReservation(tx, messageID, user.ID, data, now)
+			case statusFailed:
+				if err := queueNotification(tx, messageID, notifications.KindFailure, user.ID, data, now); err != nil {
+					return err
+				}
+				return cancelReminders(tx, event.ReservationID)
+			case statusNoShow:
+				if err := queueNotification(tx, messageID, notifications.KindNoShow, user.ID, data, now); err != nil {
+					return err
+				}
+				return cancelReminders(tx, event.ReservationID)
+			default:
+				return nil
+			}
+		case reservationModified:
+			if err := queueNotification(tx, messageID, notifications.KindModification, user.ID, data, now); err != nil {
+				return err
+			}
+			if err := cancelReminders(tx, event.ReservationID); err != nil {
+				return err
+			}
+			return scheduleReminder(tx, messageID, user.ID, data, now)
+		case reservationCancelled:
+			if err := queueNotification(tx, messageID, notifications.KindCancellation, user.ID, data, now); err != nil {
+				return err
+			}
+			return cancelReminders(tx, event.ReservationID)
+		case waitlistOffered:
+			return queueNotification(tx, messageID, notifications.KindWaitlistOffer, user.ID, data, now)
+		default:
+			return nil
+		}
+	})
+}
+
+// confirmReservation envía la confirmación y programa el recordatorio de la reserva
+func confirmReservation(tx *gorm.DB, messageID string, userID uint, data notifications.Data, now time.Time) error {
+	if err := queueNotification(tx, messageID, notifications.KindConfirmation, userID, data, now); err != nil {
+		return err
+	}
+	return scheduleReminder(tx, messageID, userID, data, now)
+}
+
+// queueNotification arma el email y lo deja pendiente de envío para scheduledAt
+func queueNotification(tx *gorm.DB, messageID, kind string, userID uint, data notifications.Data, scheduledAt time.Time) error {
+	message, err := notifications.Render(kind, data)
+	if err != nil {
+		return err
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Notification{
+		DedupKey:      messageID + ":" + kind,
+		UserID:        userID,
+		ReservationID: data.ReservationID,
+		Kind:          kind,
+		Email:         message.To,
+		Subject:       message.Subject,
+		Body:          message.Body,
+		Status:        models.NotificationPending,
+		ScheduledAt:   scheduledAt,
+	}).Error
+}
+
+// scheduleReminder programa el recordatorio REMINDER_DAYS_BEFORE días antes del check-in.
+// Si esa fecha ya pasó no se programa.
+func scheduleReminder(tx *gorm.DB, messageID string, userID uint, data notifications.Data, now time.Time) error {
+	checkIn, err := time.ParseInLocation("2006-01-02", data.CheckIn, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid check-in %q: %v", data.CheckIn, err)
+	}
+
+	data.DaysBefore = envPositiveInt("REMINDER_DAYS_BEFORE", defaultReminderDaysBefore)
+	remindAt := checkIn.AddDate(0, 0, -data.DaysBefore).Add(reminderHour * time.Hour)
+	if !remindAt.After(now) {
+		return nil
+	}
+
+	return queueNotification(tx, messageID, notifications.KindReminder, userID, data, remindAt)
+}
+
+// cancelReminders cancela los recordatorios pendientes de la reserva
+func cancelReminders(tx *gorm.DB, reservationID uint) error {
+	return tx.Model(&models.Notification{}).
+		Where("reservation_id = ? AND kind = ? AND status = ?", reservationID, notifications.KindReminder, models.NotificationPending).
+		Update("status", models.NotificationCancelled).Error
+}
+
+// StartNotificationSender envía en segundo plano las notificaciones pendientes cuyo momento llegó
+func StartNotificationSender(transport notifications.Transport) {
+	go func() {
+		for {
+			if _, err := SendDueNotifications(transport, time.Now()); err != nil {
+				log.Printf("Failed to send notifications: %s", err)
+			}
+			time.Sleep(notificationPollInterval)
+		}
+	}()
+}
+
+// SendDueNotifications envía las notificaciones pendientes hasta now y devuelve cuántas se enviaron.
+// Un envío fallido se reintenta con backoff exponencial hasta NOTIFICATION_MAX_ATTEMPTS intentos.
+func SendDueNotifications(transport notifications.Transport, now time.Time) (int, error) {
+	maxAttempts := envPositiveInt("NOTIFICATION_MAX_ATTEMPTS", defaultMaxAttempts)
+	sent := 0
+
+	for {
+		notification, err := claimDueNotification(now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+
+		sendErr := transport.Send(notifications.Message{To: notification.Email, Subject: notification.Subject, Body: notification.Body})
+		if err := recordAttempt(notification, sendErr, maxAttempts); err != nil {
+			return sent, err
+		}
+		if sendErr == nil {
+			sent++
+		}
+	}
+}
+
+// claimDueNotification reserva la notificación pendiente más antigua para que ningún otro proceso la envíe
+func claimDueNotification(now time.Time) (models.Notification, error) {
+	var notification models.Notification
+
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND scheduled_at <= ?", models.NotificationPending, now).
+			Order("scheduled_at, id").
+			First(&notification).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&notification).Update("scheduled_at", time.Now().Add(notificationLease)).Error
+	})
+	return notification, err
+}
+
+// recordAttempt guarda el resultado del envío
+func recordAttempt(notification models.Notification, sendErr error, maxAttempts int) error {
+	attempts := notification.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.NotificationSent
+		updates["sent_at"] = time.Now()
+		updates["last_error"] = ""
+	case attempts >= maxAttempts:
+		log.Printf("Notification %d failed after %d attempts: %s", notification.ID, attempts, sendErr)
+		updates["status"] = models.NotificationFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		backoff := time.Duration(1<<uint(min(attempts, 16))) * time.Minute
+		if backoff > notificationMaxBackoff {
+			backoff = notificationMaxBackoff
+		}
+		updates["scheduled_at"] = time.Now().Add(backoff)
+		updates["last_error"] = sendErr.Error()
+	}
+
+	return initializers.DB.Model(&notification).Updates(updates).Error
+}